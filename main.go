@@ -56,11 +56,14 @@ func main() {
 		user.UpdatedAt = time.Now().Format(time.RFC3339)
 
 		return user, nil
-	}))
+	}), task.WithName("prepare-user"))
 
 	bar := task.New(context.Background(), task.WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
 		// process user
-		user := values[1].(User)
+		user, err := task.GetAs[User](task.ResultsFromCtx(ctx), "prepare-user")
+		if err != nil {
+			return nil, err
+		}
 		log.Printf("process user.. %v \n", user)
 
 		if user.ID != "quzbuz" {
@@ -70,7 +73,9 @@ func main() {
 		return nil, nil
 	}))
 
-	foo.AddSubtasks(quz, bar)
+	if err := foo.AddSubtasks(quz, bar); err != nil {
+		panic(err)
+	}
 	if _, err := task.Run([]*task.Task{foo}); err != nil {
 		panic(err)
 	}
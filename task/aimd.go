@@ -0,0 +1,169 @@
+package task
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// AIMDConfig holds the settings of an AIMDLimiter.
+//
+// Members:
+// - InitialLimit: the concurrency limit the limiter starts with, defaults to MinLimit
+// - MinLimit: the lower bound of the concurrency limit, defaults to 1
+// - MaxLimit: the upper bound of the concurrency limit, defaults to 100
+// - BackoffRatio: the factor the limit is multiplied with after a failure, defaults to 0.5
+// - LatencyThreshold: tasks running longer than this count as failures, zero disables the latency check
+type AIMDConfig struct {
+	InitialLimit     int
+	MinLimit         int
+	MaxLimit         int
+	BackoffRatio     float64
+	LatencyThreshold time.Duration
+}
+
+// AIMDLimiter bounds the number of tasks running at the same time and adapts the bound to the observed health of the tasks
+// using additive-increase/multiplicative-decrease: every successful task raises the limit by 1/limit, so the limit grows by one
+// per "window" of successes, while every failed, slow or timed out task multiplies the limit by the backoff ratio.
+// Otherwise cancelled tasks don't change the limit. An AIMDLimiter is safe for concurrent use and is usually shared between runs
+// to protect a downstream system, see WithAdaptiveLimit.
+type AIMDLimiter struct {
+	mu       sync.Mutex
+	cfg      AIMDConfig
+	limit    float64
+	inflight int
+	changed  chan struct{}
+}
+
+// NewAIMDLimiter creates a new AIMDLimiter with the given configuration, filling in defaults for zero values.
+func NewAIMDLimiter(cfg AIMDConfig) *AIMDLimiter {
+	if cfg.MinLimit < 1 {
+		cfg.MinLimit = 1
+	}
+	if cfg.MaxLimit < 1 {
+		cfg.MaxLimit = 100
+	}
+	if cfg.MaxLimit < cfg.MinLimit {
+		cfg.MaxLimit = cfg.MinLimit
+	}
+	if cfg.InitialLimit < cfg.MinLimit {
+		cfg.InitialLimit = cfg.MinLimit
+	}
+	if cfg.InitialLimit > cfg.MaxLimit {
+		cfg.InitialLimit = cfg.MaxLimit
+	}
+	if cfg.BackoffRatio <= 0 || cfg.BackoffRatio >= 1 {
+		cfg.BackoffRatio = 0.5
+	}
+
+	return &AIMDLimiter{
+		cfg:     cfg,
+		limit:   float64(cfg.InitialLimit),
+		changed: make(chan struct{}),
+	}
+}
+
+// Limit returns the current concurrency limit.
+func (l *AIMDLimiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int(l.limit)
+}
+
+// Inflight returns the number of acquired slots that have not been released yet.
+func (l *AIMDLimiter) Inflight() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.inflight
+}
+
+// Acquire blocks until a slot is available or ctx is done. In the latter case it returns the result of CheckCancelled.
+// Every successful call to Acquire must be followed by a call to Release.
+func (l *AIMDLimiter) Acquire(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		if l.inflight < int(l.limit) {
+			l.inflight++
+			l.mu.Unlock()
+			return nil
+		}
+		changed := l.changed
+		l.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return CheckCancelled(ctx)
+		}
+	}
+}
+
+// Release frees a slot acquired by Acquire and adjusts the limit based on the latency and the error of the task.
+func (l *AIMDLimiter) Release(latency time.Duration, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.inflight--
+
+	switch {
+	case uninformative(err):
+		// cancellations other than timeouts say nothing about the health of the downstream
+	case err != nil, l.cfg.LatencyThreshold > 0 && latency > l.cfg.LatencyThreshold:
+		l.limit = max(float64(l.cfg.MinLimit), l.limit*l.cfg.BackoffRatio)
+	default:
+		l.limit = min(float64(l.cfg.MaxLimit), l.limit+1/l.limit)
+	}
+
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+// limiters returns the distinct limiters of the tags of task, ordered by the smallest tag each limiter is registered under,
+// so tasks acquiring the same limiters always do so in the same order and cannot deadlock each other.
+func (r *Runner) limiters(task *Task) []*AIMDLimiter {
+	var limiters []*AIMDLimiter
+	for _, tag := range task.Tags {
+		if l, ok := r.Options.Limiters[tag]; ok && !slices.Contains(limiters, l) {
+			limiters = append(limiters, l) // a limiter shared by several tags grants a single slot
+		}
+	}
+	if len(limiters) < 2 {
+		return limiters
+	}
+
+	rank := make(map[*AIMDLimiter]string, len(limiters))
+	for tag, l := range r.Options.Limiters {
+		if first, ok := rank[l]; slices.Contains(limiters, l) && (!ok || tag < first) {
+			rank[l] = tag
+		}
+	}
+	sort.Slice(limiters, func(i, j int) bool {
+		return rank[limiters[i]] < rank[limiters[j]]
+	})
+	return limiters
+}
+
+// WithTags returns a TaskConfigFunc that adds tags to the task. Tags group tasks for run-wide policies like WithAdaptiveLimit.
+func WithTags(tags ...string) TaskConfigFunc {
+	return func(t *Task) {
+		t.Tags = append(t.Tags, tags...)
+	}
+}
+
+// WithAdaptiveLimit returns a RunnerConfigFunc that runs every task tagged with tag only when the limiter grants a slot,
+// and reports the duration and error of the task back to the limiter.
+//
+// Example usage:
+//
+//	payments := task.NewAIMDLimiter(task.AIMDConfig{MaxLimit: 20, LatencyThreshold: time.Second})
+//	runner := task.NewRunner(task.WithAdaptiveLimit("payments", payments))
+func WithAdaptiveLimit(tag string, l *AIMDLimiter) RunnerConfigFunc {
+	return func(o *RunOptions) {
+		if o.Limiters == nil {
+			o.Limiters = map[string]*AIMDLimiter{}
+		}
+		o.Limiters[tag] = l
+	}
+}
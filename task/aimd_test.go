@@ -0,0 +1,171 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAIMDLimiter(t *testing.T) {
+	l := NewAIMDLimiter(AIMDConfig{InitialLimit: 4, MinLimit: 1, MaxLimit: 5})
+
+	ctx := context.Background()
+	if err := l.Acquire(ctx); err != nil {
+		t.Fatal("didnt expect error")
+	}
+	l.Release(time.Millisecond, errors.New("downstream failed"))
+	if l.Limit() != 2 {
+		t.Errorf("expected limit of %d after failure, got %d", 2, l.Limit())
+	}
+
+	for i := 0; i < 20; i++ {
+		if err := l.Acquire(ctx); err != nil {
+			t.Fatal("didnt expect error")
+		}
+		l.Release(time.Millisecond, nil)
+	}
+	if l.Limit() != 5 {
+		t.Errorf("expected limit to grow to %d, got %d", 5, l.Limit())
+	}
+
+	if err := l.Acquire(ctx); err != nil {
+		t.Fatal("didnt expect error")
+	}
+	l.Release(time.Millisecond, &CancelledError{Reason: CancelledByCaller, Cause: context.Canceled})
+	if l.Limit() != 5 {
+		t.Errorf("expected cancellation to keep limit at %d, got %d", 5, l.Limit())
+	}
+
+	if err := l.Acquire(ctx); err != nil {
+		t.Fatal("didnt expect error")
+	}
+	l.Release(time.Millisecond, &CancelledError{Reason: TimedOut, Cause: context.DeadlineExceeded})
+	if l.Limit() != 2 {
+		t.Errorf("expected limit of %d after timeout, got %d", 2, l.Limit())
+	}
+}
+
+func TestAIMDLimiterLatency(t *testing.T) {
+	l := NewAIMDLimiter(AIMDConfig{InitialLimit: 8, LatencyThreshold: time.Millisecond})
+
+	if err := l.Acquire(context.Background()); err != nil {
+		t.Fatal("didnt expect error")
+	}
+	l.Release(time.Second, nil)
+
+	if l.Limit() != 4 {
+		t.Errorf("expected slow task to halve the limit, got %d", l.Limit())
+	}
+}
+
+func TestAIMDLimiterAcquireCancelled(t *testing.T) {
+	l := NewAIMDLimiter(AIMDConfig{InitialLimit: 1})
+	if err := l.Acquire(context.Background()); err != nil {
+		t.Fatal("didnt expect error")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+
+	if err := l.Acquire(ctx); !errors.Is(err, ErrTimedOut) {
+		t.Errorf("expected a timeout, got %v", err)
+	}
+}
+
+func TestAdaptiveLimit(t *testing.T) {
+	l := NewAIMDLimiter(AIMDConfig{InitialLimit: 2, MaxLimit: 2})
+	r := NewRunner(WithAdaptiveLimit("downstream", l))
+
+	var running, peak atomic.Int64
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			task := New(context.Background(), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+				n := running.Add(1)
+				for {
+					p := peak.Load()
+					if n <= p || peak.CompareAndSwap(p, n) {
+						break
+					}
+				}
+				time.Sleep(time.Millisecond)
+				running.Add(-1)
+				return nil, nil
+			}), WithTags("downstream"))
+
+			if _, err := r.Run([]*Task{task}); err != nil {
+				t.Error("should not throw an error")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if peak.Load() > 2 {
+		t.Errorf("expected at most %d concurrent tasks, got %d", 2, peak.Load())
+	}
+	if l.Inflight() != 0 {
+		t.Errorf("expected all slots to be released, got %d inflight", l.Inflight())
+	}
+}
+
+func TestAdaptiveLimitSharedLimiter(t *testing.T) {
+	l := NewAIMDLimiter(AIMDConfig{InitialLimit: 1, MaxLimit: 1})
+	r := NewRunner(WithAdaptiveLimit("db", l), WithAdaptiveLimit("replica", l))
+
+	for _, tags := range [][]string{{"db", "db"}, {"db", "replica"}} {
+		task := New(context.Background(), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+			if l.Inflight() != 1 {
+				t.Errorf("expected %d slot to be held, got %d", 1, l.Inflight())
+			}
+			return nil, nil
+		}), WithTags(tags...))
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		_, err := r.RunCtx(ctx, []*Task{task})
+		cancel()
+		if err != nil {
+			t.Fatalf("%v: didnt expect error, got %v", tags, err)
+		}
+	}
+	if l.Inflight() != 0 {
+		t.Errorf("expected all slots to be released, got %d inflight", l.Inflight())
+	}
+}
+
+func TestAdaptiveLimitTimeout(t *testing.T) {
+	l := NewAIMDLimiter(AIMDConfig{InitialLimit: 4})
+	r := NewRunner(WithAdaptiveLimit("downstream", l))
+
+	task := New(context.Background(), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}), WithTags("downstream"), WithTimeout(time.Millisecond))
+
+	if _, err := r.Run([]*Task{task}); !errors.Is(err, ErrTimedOut) {
+		t.Fatalf("expected %v, got %v", ErrTimedOut, err)
+	}
+	if l.Limit() != 2 {
+		t.Errorf("expected the timeout to halve the limit to %d, got %d", 2, l.Limit())
+	}
+}
+
+func TestAdaptiveLimitOppositeOrder(t *testing.T) {
+	a := NewAIMDLimiter(AIMDConfig{InitialLimit: 1, MaxLimit: 1})
+	b := NewAIMDLimiter(AIMDConfig{InitialLimit: 1, MaxLimit: 1})
+	r := NewRunner(WithAdaptiveLimit("a", a), WithAdaptiveLimit("b", b), WithAdaptiveLimit("c", a))
+
+	// tasks acquiring the limiters in opposite orders could each hold one and wait for the other forever
+	for _, tags := range [][]string{{"a", "b"}, {"b", "a"}, {"c", "b"}, {"b", "c", "a"}} {
+		task := New(context.Background(), WithFunc(noop), WithTags(tags...))
+		if got := r.limiters(task); !reflect.DeepEqual(got, []*AIMDLimiter{a, b}) {
+			t.Errorf("%v: expected the limiters in canonical order", tags)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package task
+
+import (
+	"context"
+	"fmt"
+)
+
+// Predicate represents a function that checks the values passed to a task. It returns false if the check fails
+// and an error if the check itself could not be performed.
+type Predicate func(ctx context.Context, values ...interface{}) (bool, error)
+
+// AssertionError is returned by assertion tasks whose predicate does not hold. Err holds the error returned by the predicate, if any.
+type AssertionError struct {
+	TaskID  string
+	Message string
+	Err     error
+}
+
+// Error implements the error interface.
+func (e *AssertionError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("assertion %q of task %s failed: %v", e.Message, e.TaskID, e.Err)
+	}
+	return fmt.Sprintf("assertion %q of task %s failed", e.Message, e.TaskID)
+}
+
+// Unwrap returns the error returned by the predicate.
+func (e *AssertionError) Unwrap() error {
+	return e.Err
+}
+
+// Assert creates a new Task that checks the values passed to it with the predicate fn and fails with an *AssertionError
+// carrying msg if the predicate does not hold, which reverts the run like any other failure. The task outputs nil.
+// Combined with DependsOn, an assertion validates exactly the outputs of the tasks it depends on.
+//
+// Example usage:
+//
+//	check := task.Assert(ctx, func(ctx context.Context, values ...interface{}) (bool, error) {
+//		return values[0].(User).ID != "", nil
+//	}, "created user has an id")
+//	if err := check.DependsOn(createUser); err != nil {
+//		return err
+//	}
+//	if err := check.AddSubtasks(processUser); err != nil {
+//		return err
+//	}
+func Assert(ctx context.Context, fn Predicate, msg string, cfgs ...TaskConfigFunc) *Task {
+	assert := WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		ok, err := fn(ctx, values...)
+		if ok && err == nil {
+			return nil, nil
+		}
+
+		aerr := &AssertionError{
+			Message: msg,
+			Err:     err,
+		}
+		if tc, derr := DecodeCtx(ctx); derr == nil && tc.Task != nil {
+			aerr.TaskID = tc.Task.ID
+		}
+		return nil, aerr
+	})
+
+	return New(ctx, append([]TaskConfigFunc{assert}, cfgs...)...)
+}
@@ -0,0 +1,51 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestAssert(t *testing.T) {
+	ctx := context.Background()
+
+	create := New(ctx, WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return "", nil
+	}))
+
+	check := Assert(ctx, func(ctx context.Context, values ...interface{}) (bool, error) {
+		return values[0].(string) != "", nil
+	}, "user id is not empty")
+	check.DependsOn(create)
+
+	_, err := Run([]*Task{create})
+
+	var aerr *AssertionError
+	if !errors.As(err, &aerr) {
+		t.Fatalf("expected an AssertionError, got %v", err)
+	}
+	if aerr.TaskID != check.ID || aerr.Message != "user id is not empty" {
+		t.Errorf("unexpected assertion error %v", aerr)
+	}
+}
+
+func TestAssertPredicateError(t *testing.T) {
+	cause := errors.New("schema unavailable")
+	check := Assert(context.Background(), func(ctx context.Context, values ...interface{}) (bool, error) {
+		return true, cause
+	}, "matches schema")
+
+	if _, err := Run([]*Task{check}); !errors.Is(err, cause) {
+		t.Errorf("expected the predicate error to be wrapped, got %v", err)
+	}
+}
+
+func TestAssertHolds(t *testing.T) {
+	check := Assert(context.Background(), func(ctx context.Context, values ...interface{}) (bool, error) {
+		return len(values) == 1, nil
+	}, "exactly one value")
+
+	if _, err := Run([]*Task{check}, 1); err != nil {
+		t.Errorf("didnt expect error, got %v", err)
+	}
+}
@@ -0,0 +1,219 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// CheckpointBatchSaver is implemented by a CheckpointStore that can save several checkpoints of a graph at once more efficiently than one by one,
+// e.g. in a single transaction or with a single fsync. It is used by BatchingCheckpointStore.
+type CheckpointBatchSaver interface {
+	SaveBatch(ctx context.Context, graphID string, cs []Checkpoint) error
+}
+
+// CheckpointFlusher is implemented by a CheckpointStore buffering checkpoints. After Flush returned without error, all checkpoints
+// of the graph saved before are durably written.
+type CheckpointFlusher interface {
+	Flush(ctx context.Context, graphID string) error
+}
+
+// BatchingCheckpointStore wraps a CheckpointStore and writes checkpoints in batches for throughput: saved checkpoints are buffered per graph
+// and written once size of them are buffered or interval passed since the first of them was buffered, whichever comes first.
+//
+// Runs started with Runner.Resume flush the checkpoints of a graph whenever a task with a Revert function completed, before any task depending on it starts,
+// so the completion of a compensable task is durably written before its side effects are considered committed. They flush again when the run succeeded.
+// The checkpoints of other tasks that are still buffered when the process crashes are lost, so these tasks run again when the graph is resumed.
+//
+// A write failing in the background is retried with the next write of the graph, and returned by the next call to Save or Flush for the graph.
+//
+// Example usage:
+//
+//	store := task.NewBatchingCheckpointStore(task.NewFileCheckpointStore("/var/lib/billing/checkpoints"), 100, 50*time.Millisecond)
+//	runner := task.NewRunner(task.WithCheckpointStore(store))
+type BatchingCheckpointStore struct {
+	store    CheckpointStore
+	size     int
+	interval time.Duration
+
+	mu     sync.Mutex
+	graphs map[string]*checkpointBatch
+}
+
+// checkpointBatch holds the buffered checkpoints of a graph.
+type checkpointBatch struct {
+	mu      sync.Mutex // serializes writes of the graph, so checkpoints are written in order
+	pending []Checkpoint
+	timer   *time.Timer
+	err     error // error of the last write in the background
+	users   int   // calls using the batch, guarded by the mutex of the store
+}
+
+// NewBatchingCheckpointStore creates a new BatchingCheckpointStore writing to store in batches of up to size checkpoints, at least one,
+// at the latest interval after a checkpoint was saved. An interval of zero only writes full batches and when flushed.
+func NewBatchingCheckpointStore(store CheckpointStore, size int, interval time.Duration) *BatchingCheckpointStore {
+	return &BatchingCheckpointStore{
+		store:    store,
+		size:     max(size, 1),
+		interval: interval,
+		graphs:   make(map[string]*checkpointBatch),
+	}
+}
+
+// batch returns the batch of a graph, creating it if needed. Callers have to release it once they are done.
+func (s *BatchingCheckpointStore) batch(graphID string) *checkpointBatch {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.graphs[graphID]
+	if !ok {
+		b = &checkpointBatch{}
+		s.graphs[graphID] = b
+	}
+	b.users++
+	return b
+}
+
+// release ends a use of the batch of a graph returned by batch and drops the batch if it is no longer needed.
+func (s *BatchingCheckpointStore) release(graphID string, b *checkpointBatch) {
+	s.mu.Lock()
+	b.users--
+	s.mu.Unlock()
+	s.tidy(graphID, b)
+}
+
+// tidy drops the batch of a graph once nobody uses it and it holds no checkpoints, pending write or error, so completed graphs don't leak.
+func (s *BatchingCheckpointStore) tidy(graphID string, b *checkpointBatch) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if b.users > 0 || s.graphs[graphID] != b {
+		return
+	}
+
+	b.mu.Lock()
+	idle := len(b.pending) == 0 && b.timer == nil && b.err == nil
+	b.mu.Unlock()
+	if idle {
+		delete(s.graphs, graphID)
+	}
+}
+
+// Save implements the CheckpointStore interface. It buffers c and only writes the batch of the graph if it is full.
+func (s *BatchingCheckpointStore) Save(ctx context.Context, graphID string, c Checkpoint) error {
+	b := s.batch(graphID)
+	defer s.release(graphID, b)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.pending = append(b.pending, c)
+	if len(b.pending) >= s.size {
+		return s.write(ctx, graphID, b)
+	}
+	if b.timer == nil && s.interval > 0 {
+		b.timer = time.AfterFunc(s.interval, func() {
+			b.mu.Lock()
+			b.timer = nil
+			b.err = s.write(context.Background(), graphID, b)
+			b.mu.Unlock()
+			s.tidy(graphID, b)
+		})
+	}
+
+	err := b.err
+	b.err = nil
+	return err
+}
+
+// Flush implements the CheckpointFlusher interface. It writes the buffered checkpoints of the graph.
+func (s *BatchingCheckpointStore) Flush(ctx context.Context, graphID string) error {
+	b := s.batch(graphID)
+	defer s.release(graphID, b)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return s.write(ctx, graphID, b)
+}
+
+// write writes the buffered checkpoints of a graph. They stay buffered if the write fails. The caller has to hold b.mu.
+func (s *BatchingCheckpointStore) write(ctx context.Context, graphID string, b *checkpointBatch) error {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	b.err = nil
+	if len(b.pending) == 0 {
+		return nil
+	}
+
+	var err error
+	if saver, ok := s.store.(CheckpointBatchSaver); ok {
+		err = saver.SaveBatch(ctx, graphID, b.pending)
+	} else {
+		for len(b.pending) > 0 && err == nil {
+			if err = s.store.Save(ctx, graphID, b.pending[0]); err == nil {
+				b.pending = b.pending[1:]
+			}
+		}
+	}
+	if err != nil {
+		return err
+	}
+	b.pending = nil
+	return nil
+}
+
+// Load implements the CheckpointStore interface. It writes the buffered checkpoints of the graph first.
+func (s *BatchingCheckpointStore) Load(ctx context.Context, graphID string) ([]Checkpoint, error) {
+	if err := s.Flush(ctx, graphID); err != nil {
+		return nil, err
+	}
+	return s.store.Load(ctx, graphID)
+}
+
+// Delete implements the CheckpointStore interface. It drops the buffered checkpoints of the graph.
+func (s *BatchingCheckpointStore) Delete(ctx context.Context, graphID string) error {
+	s.mu.Lock()
+	b, ok := s.graphs[graphID]
+	delete(s.graphs, graphID)
+	s.mu.Unlock()
+
+	if ok {
+		b.mu.Lock()
+		if b.timer != nil {
+			b.timer.Stop()
+			b.timer = nil
+		}
+		b.pending = nil
+		b.mu.Unlock()
+	}
+	return s.store.Delete(ctx, graphID)
+}
+
+// flushCheckpoints flushes the buffered checkpoints of the run, if its CheckpointStore buffers them.
+func (s *run) flushCheckpoints(task *Task) error {
+	flusher, ok := s.runner.Options.CheckpointStore.(CheckpointFlusher)
+	if !ok {
+		return nil
+	}
+	return s.storeWrite(task, func(ctx context.Context) error {
+		return flusher.Flush(ctx, s.graphID)
+	})
+}
+
+// flushGraph flushes the buffered checkpoints of a run that succeeded or keeps its checkpoints, if its CheckpointStore buffers them.
+func (s *run) flushGraph(ctx context.Context) error {
+	flusher, ok := s.runner.Options.CheckpointStore.(CheckpointFlusher)
+	if s.graphID == "" || !ok {
+		return nil
+	}
+
+	err := s.runner.storeCall(ctx, func(ctx context.Context) error {
+		return flusher.Flush(ctx, s.graphID)
+	})
+	if err != nil {
+		return fmt.Errorf("flush checkpoints of graph %s: %w", s.graphID, err)
+	}
+	return nil
+}
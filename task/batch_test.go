@@ -0,0 +1,148 @@
+package task
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// countingCheckpointStore is a MemoryCheckpointStore counting the batches written to it.
+type countingCheckpointStore struct {
+	*MemoryCheckpointStore
+
+	mu      sync.Mutex
+	batches int
+}
+
+func (s *countingCheckpointStore) SaveBatch(ctx context.Context, graphID string, cs []Checkpoint) error {
+	s.mu.Lock()
+	s.batches++
+	s.mu.Unlock()
+	return s.MemoryCheckpointStore.SaveBatch(ctx, graphID, cs)
+}
+
+// saveOnlyCheckpointStore hides the SaveBatch method of a MemoryCheckpointStore.
+type saveOnlyCheckpointStore struct {
+	CheckpointStore
+}
+
+func checkpoint(key string) Checkpoint {
+	envs, _ := EncodeParameters(JSONCodec, key)
+	return Checkpoint{TaskKey: key, Output: envs[0]}
+}
+
+func TestBatchingCheckpointStoreSize(t *testing.T) {
+	ctx := context.Background()
+	mem := NewMemoryCheckpointStore()
+	underlying := &countingCheckpointStore{MemoryCheckpointStore: mem}
+	store := NewBatchingCheckpointStore(underlying, 2, 0)
+
+	for _, key := range []string{"a", "b", "c"} {
+		if err := store.Save(ctx, "graph", checkpoint(key)); err != nil {
+			t.Fatalf("didnt expect error, got %v", err)
+		}
+	}
+
+	if keys := checkpointedTasks(t, mem, "graph"); !reflect.DeepEqual(keys, []string{"a", "b"}) {
+		t.Errorf("expected a full batch to be written, got %v", keys)
+	}
+	if underlying.batches != 1 {
+		t.Errorf("expected %d batch, got %d", 1, underlying.batches)
+	}
+
+	if keys := checkpointedTasks(t, store, "graph"); !reflect.DeepEqual(keys, []string{"a", "b", "c"}) {
+		t.Errorf("expected Load to flush the buffered checkpoints, got %v", keys)
+	}
+}
+
+func TestBatchingCheckpointStoreInterval(t *testing.T) {
+	ctx := context.Background()
+	mem := NewMemoryCheckpointStore()
+	store := NewBatchingCheckpointStore(saveOnlyCheckpointStore{mem}, 100, 5*time.Millisecond)
+
+	for _, key := range []string{"a", "b"} {
+		if err := store.Save(ctx, "graph", checkpoint(key)); err != nil {
+			t.Fatalf("didnt expect error, got %v", err)
+		}
+	}
+	if keys := checkpointedTasks(t, mem, "graph"); len(keys) != 0 {
+		t.Errorf("expected checkpoints to be buffered, got %v", keys)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if keys := checkpointedTasks(t, mem, "graph"); !reflect.DeepEqual(keys, []string{"a", "b"}) {
+		t.Errorf("expected checkpoints to be written after the interval, got %v", keys)
+	}
+}
+
+func TestBatchingCheckpointStoreReleasesGraphs(t *testing.T) {
+	ctx := context.Background()
+	store := NewBatchingCheckpointStore(NewMemoryCheckpointStore(), 100, 5*time.Millisecond)
+
+	if err := store.Save(ctx, "flushed", checkpoint("a")); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if err := store.Flush(ctx, "flushed"); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if err := store.Save(ctx, "written", checkpoint("a")); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if len(store.graphs) != 0 {
+		t.Errorf("expected the batches of written graphs to be dropped, got %d", len(store.graphs))
+	}
+}
+
+func TestBatchingCheckpointStoreDelete(t *testing.T) {
+	ctx := context.Background()
+	mem := NewMemoryCheckpointStore()
+	store := NewBatchingCheckpointStore(mem, 100, 0)
+
+	if err := store.Save(ctx, "graph", checkpoint("a")); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if err := store.Delete(ctx, "graph"); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if keys := checkpointedTasks(t, store, "graph"); len(keys) != 0 {
+		t.Errorf("expected buffered checkpoints to be dropped, got %v", keys)
+	}
+}
+
+func TestBatchingCheckpointStoreCompensableTask(t *testing.T) {
+	ctx := context.Background()
+	mem := NewMemoryCheckpointStore()
+	runner := NewRunner(WithCheckpointStore(NewBatchingCheckpointStore(mem, 100, 0)))
+
+	var durable []string
+	value := func(name string) TaskConfigFunc {
+		return WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+			durable = checkpointedTasks(t, mem, "graph")
+			return name, nil
+		})
+	}
+	charge := New(ctx, WithName("charge"), value("charge"), WithRevertFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, nil
+	}))
+	audit := New(ctx, WithName("audit"), value("audit"))
+	notify := New(ctx, WithName("notify"), value("notify"))
+	audit.AddSubtasks(charge)
+	charge.AddSubtasks(notify)
+
+	if _, err := runner.Resume(ctx, "graph", []*Task{audit}); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+
+	if expected := []string{"audit", "charge"}; !reflect.DeepEqual(durable, expected) {
+		t.Errorf("expected %v to be durable before notify starts, got %v", expected, durable)
+	}
+	if keys := checkpointedTasks(t, mem, "graph"); !reflect.DeepEqual(keys, []string{"audit", "charge", "notify"}) {
+		t.Errorf("expected every checkpoint to be written once the run succeeded, got %v", keys)
+	}
+}
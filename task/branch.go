@@ -0,0 +1,90 @@
+package task
+
+import "context"
+
+// SelectorFunc selects the case of a Switch. It receives the same values the Run function of a task would receive.
+type SelectorFunc func(ctx context.Context, values ...interface{}) (interface{}, error)
+
+// Case is a branch of a Switch.
+//
+// Members:
+// - Value: the value the selector has to return for the branch to run, compared with ==
+// - Tasks: the tasks of the branch
+type Case struct {
+	Value interface{}
+	Tasks []*Task
+}
+
+// If returns a task that calls cond with the values it receives and outputs its result. Once it succeeded, thenTasks run
+// if the result is true and elseTasks if it is false, the tasks of the other branch and their subtasks are skipped, see WithCondition.
+// The cfgs configure the returned task, e.g. its name. If the tasks of a branch can't be added to its gate, e.g. because one of them
+// is sealed, nothing is changed and the error is returned.
+//
+// Each branch is gated by a task of its own depending on the returned task, and the tasks of the branch are added as its subtasks,
+// so they receive the values of the run like any subtask, including the output of the gate, which is the result of cond.
+// Tasks depending on tasks of both branches run after the branch that was taken and receive nil as the output of the skipped tasks.
+//
+// Example usage:
+//
+//	review, err := task.If(ctx, func(ctx context.Context, values ...interface{}) (bool, error) {
+//		return values[0].(Order).Total > 10_000, nil
+//	}, []*task.Task{manualReview}, []*task.Task{autoApprove}, task.WithName("needs-review"))
+//	if err != nil {
+//		return err
+//	}
+//	if err := review.DependsOn(createOrder); err != nil {
+//		return err
+//	}
+func If(ctx context.Context, cond ConditionFunc, thenTasks, elseTasks []*Task, cfgs ...TaskConfigFunc) (*Task, error) {
+	selector := func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return cond(ctx, values...)
+	}
+	return Switch(ctx, selector, []Case{{Value: true, Tasks: thenTasks}, {Value: false, Tasks: elseTasks}}, cfgs...)
+}
+
+// Switch returns a task that calls selector with the values it receives and outputs its result. Once it succeeded, the tasks
+// of every case whose Value equals the result run, the tasks of the other cases and their subtasks are skipped, see WithCondition.
+// If no case matches, all of them are skipped. The values of the cases have to be comparable. The cfgs configure the returned task.
+// Branches are gated like the branches of If, and errors are returned like by If.
+//
+// Example usage:
+//
+//	ship, err := task.Switch(ctx, func(ctx context.Context, values ...interface{}) (interface{}, error) {
+//		return values[0].(Order).Country, nil
+//	}, []task.Case{
+//		{Value: "DE", Tasks: []*task.Task{dhl}},
+//		{Value: "US", Tasks: []*task.Task{ups, customs}},
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	if err := ship.DependsOn(createOrder); err != nil {
+//		return err
+//	}
+func Switch(ctx context.Context, selector SelectorFunc, cases []Case, cfgs ...TaskConfigFunc) (*Task, error) {
+	// check every case first, so a failing case doesn't leave the tasks of the others attached to a discarded decision
+	for _, c := range cases {
+		if err := checkSealed(c.Tasks...); err != nil {
+			return nil, err
+		}
+	}
+
+	cfgs = append([]TaskConfigFunc{WithFunc(TaskFunc(selector))}, cfgs...)
+	t := New(ctx, cfgs...)
+
+	for _, c := range cases {
+		value := c.Value
+		gate := New(ctx, WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+			return values[0], nil
+		}), WithCondition(func(ctx context.Context, values ...interface{}) (bool, error) {
+			return values[0] == value, nil
+		}))
+		if err := gate.DependsOn(t); err != nil {
+			return nil, err
+		}
+		if err := gate.AddSubtasks(c.Tasks...); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
@@ -0,0 +1,115 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestIf(t *testing.T) {
+	ctx := context.Background()
+	value := func(name string) *Task {
+		return New(ctx, WithName(name), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+			return name, nil
+		}))
+	}
+
+	for _, total := range []int{50, 50_000} {
+		order := New(ctx, WithName("order"), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+			return total, nil
+		}))
+		review, notify, approve := value("review"), value("notify"), value("approve")
+		review.AddSubtasks(notify)
+		join := New(ctx, WithName("join"), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+			return values, nil
+		}))
+		join.DependsOn(review, approve)
+
+		decision, err := If(ctx, func(ctx context.Context, values ...interface{}) (bool, error) {
+			return values[0].(int) > 10_000, nil
+		}, []*Task{review}, []*Task{approve}, WithName("needs-review"))
+		if err != nil {
+			t.Fatalf("didnt expect error, got %v", err)
+		}
+		decision.DependsOn(order)
+
+		results, err := Run([]*Task{order})
+		if err != nil {
+			t.Fatalf("didnt expect error, got %v", err)
+		}
+
+		expected := map[*Task]Status{review: Succeeded, notify: Succeeded, approve: Skipped, join: Succeeded}
+		taken := "review"
+		if total <= 10_000 {
+			expected = map[*Task]Status{review: Skipped, notify: Skipped, approve: Succeeded, join: Succeeded}
+			taken = "approve"
+		}
+		for task, status := range expected {
+			if task.Status() != status {
+				t.Errorf("total %d: expected task %s to be %s, got %s", total, task.Name, status, task.Status())
+			}
+		}
+
+		if v, _ := results.Get("needs-review"); v != (total > 10_000) {
+			t.Errorf("total %d: expected the decision to output %v, got %v", total, total > 10_000, v)
+		}
+		v, _ := results.Get("join")
+		values := v.([]interface{})
+		if len(values) != 2 || (values[0] != taken && values[1] != taken) || (values[0] != nil && values[1] != nil) {
+			t.Errorf("total %d: expected join to receive the output of %s and nil, got %v", total, taken, values)
+		}
+	}
+}
+
+func TestSwitch(t *testing.T) {
+	ctx := context.Background()
+	var ran []string
+	value := func(name string) *Task {
+		return New(ctx, WithName(name), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+			ran = append(ran, name)
+			return name, nil
+		}))
+	}
+
+	for country, expected := range map[string][]string{"DE": {"dhl"}, "US": {"ups", "customs"}, "FR": nil} {
+		ran = nil
+		ship, err := Switch(ctx, func(ctx context.Context, values ...interface{}) (interface{}, error) {
+			return country, nil
+		}, []Case{
+			{Value: "DE", Tasks: []*Task{value("dhl")}},
+			{Value: "US", Tasks: []*Task{value("ups"), value("customs")}},
+		})
+		if err != nil {
+			t.Fatalf("didnt expect error, got %v", err)
+		}
+
+		if _, err := Run([]*Task{ship}); err != nil {
+			t.Fatalf("didnt expect error, got %v", err)
+		}
+		if len(ran) != len(expected) {
+			t.Fatalf("country %s: expected tasks %v to run, got %v", country, expected, ran)
+		}
+		for i := range expected {
+			if ran[i] != expected[i] {
+				t.Errorf("country %s: expected tasks %v to run, got %v", country, expected, ran)
+			}
+		}
+	}
+}
+
+func TestSwitchSealed(t *testing.T) {
+	ctx := context.Background()
+	open := New(ctx, WithFunc(noop))
+	sealed := New(ctx, WithFunc(noop))
+	sealed.Seal()
+
+	_, err := Switch(ctx, func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return "a", nil
+	}, []Case{{Value: "a", Tasks: []*Task{open}}, {Value: "b", Tasks: []*Task{sealed}}})
+	if !errors.Is(err, ErrSealed) {
+		t.Errorf("expected ErrSealed, got %v", err)
+	}
+	if tc, _ := open.Context.Value(CtxKey("ctx")).(*TaskContext); tc != nil && tc.Parent != nil {
+		t.Error("expected the tasks of the other cases to stay unchanged")
+	}
+}
@@ -0,0 +1,187 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// CancelReason describes why a task did not run to completion because its context was done.
+type CancelReason int
+
+const (
+	// CancelledByCaller means the caller cancelled the context the task was running in.
+	CancelledByCaller CancelReason = iota
+	// TimedOut means the deadline of the task context was exceeded.
+	TimedOut
+	// SiblingFailed means the task was cancelled because another task of the same run failed.
+	SiblingFailed
+	// Preempted means the task was cancelled to make room for more important work.
+	Preempted
+)
+
+// String returns a human readable representation of the CancelReason.
+func (r CancelReason) String() string {
+	switch r {
+	case CancelledByCaller:
+		return "cancelled by caller"
+	case TimedOut:
+		return "timed out"
+	case SiblingFailed:
+		return "cancelled because sibling failed"
+	case Preempted:
+		return "preempted"
+	default:
+		return fmt.Sprintf("CancelReason(%d)", int(r))
+	}
+}
+
+// Sentinel errors that can be passed to the cancel function of context.WithCancelCause to tell the runner why a task context was cancelled.
+// A *CancelledError matches the sentinel of its Reason with errors.Is.
+//
+// Example usage:
+//
+//	ctx, cancel := context.WithCancelCause(context.Background())
+//	t := New(ctx, WithFunc(fn))
+//	cancel(ErrPreempted)
+var (
+	ErrCancelledByCaller = errors.New("cancelled by caller")
+	ErrTimedOut          = errors.New("timed out")
+	ErrSiblingFailed     = errors.New("cancelled because sibling failed")
+	ErrPreempted         = errors.New("preempted")
+)
+
+// CancelledError is returned by Run when a task did not run to completion because its context was done.
+// Reason tells why the context was done and Cause holds the error reported by context.Cause.
+type CancelledError struct {
+	TaskID string
+	Reason CancelReason
+	Cause  error
+}
+
+// Error implements the error interface.
+func (e *CancelledError) Error() string {
+	return fmt.Sprintf("task %s %s: %v", e.TaskID, e.Reason, e.Cause)
+}
+
+// Unwrap returns the cause of the cancellation, so errors.Is(err, context.Canceled) keeps working.
+func (e *CancelledError) Unwrap() error {
+	return e.Cause
+}
+
+// Is reports whether target is the sentinel error of the cancellation reason.
+func (e *CancelledError) Is(target error) bool {
+	switch target {
+	case ErrCancelledByCaller:
+		return e.Reason == CancelledByCaller
+	case ErrTimedOut:
+		return e.Reason == TimedOut
+	case ErrSiblingFailed:
+		return e.Reason == SiblingFailed
+	case ErrPreempted:
+		return e.Reason == Preempted
+	}
+	return false
+}
+
+// cancelReason derives the CancelReason from the cause of a done context.
+func cancelReason(ctx context.Context) CancelReason {
+	cause := context.Cause(ctx)
+	switch {
+	case errors.Is(cause, ErrPreempted):
+		return Preempted
+	case errors.Is(cause, ErrSiblingFailed):
+		return SiblingFailed
+	case errors.Is(cause, ErrTimedOut), errors.Is(cause, context.DeadlineExceeded):
+		return TimedOut
+	default:
+		return CancelledByCaller
+	}
+}
+
+// uninformative reports whether err is a cancellation that says nothing about the health of the downstream of a task.
+// Timeouts are not: a task running into its deadline is the typical symptom of a slow or unavailable downstream.
+func uninformative(err error) bool {
+	var cerr *CancelledError
+	return errors.As(err, &cerr) && cerr.Reason != TimedOut
+}
+
+// newCancelledError creates a CancelledError for a task whose context ctx is done.
+func newCancelledError(t *Task, ctx context.Context) *CancelledError {
+	return &CancelledError{
+		TaskID: t.ID,
+		Reason: cancelReason(ctx),
+		Cause:  context.Cause(ctx),
+	}
+}
+
+// CheckCancelled returns nil as long as ctx is not done. Once it is done, it returns a *CancelledError describing why,
+// so a long running TaskFunc can simply return the error and Run reports the cancellation like any other.
+// If ctx is a task context, the error carries the ID of the task.
+//
+// Example usage:
+//
+//	for _, record := range records {
+//		if err := task.CheckCancelled(ctx); err != nil {
+//			return nil, err
+//		}
+//		process(record)
+//	}
+func CheckCancelled(ctx context.Context) error {
+	if ctx.Err() == nil {
+		return nil
+	}
+
+	cerr := &CancelledError{
+		Reason: cancelReason(ctx),
+		Cause:  context.Cause(ctx),
+	}
+	if tc, err := DecodeCtx(ctx); err == nil && tc.Task != nil {
+		cerr.TaskID = tc.Task.ID
+	}
+	return cerr
+}
+
+// Every returns a function that calls CheckCancelled only on every n-th invocation and returns nil otherwise.
+// It is meant for tight CPU-bound loops where checking the context on every iteration is too expensive.
+// The returned function is not safe for concurrent use. A value of n smaller than 1 checks on every call.
+//
+// Example usage:
+//
+//	check := task.Every(1000, ctx)
+//	for i := range items {
+//		if err := check(); err != nil {
+//			return nil, err
+//		}
+//		items[i] = transform(items[i])
+//	}
+func Every(n int, ctx context.Context) func() error {
+	if n < 1 {
+		n = 1
+	}
+
+	calls := 0
+	return func() error {
+		calls++
+		if calls < n {
+			return nil
+		}
+		calls = 0
+		return CheckCancelled(ctx)
+	}
+}
+
+// Sleep pauses for the duration d or until ctx is done, whichever happens first.
+// It returns the result of CheckCancelled if ctx is done before d has elapsed and nil otherwise.
+func Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return CheckCancelled(ctx)
+	}
+}
@@ -0,0 +1,143 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCancelledByCaller(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	task := New(ctx, WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		t.Error("task should not run")
+		return nil, nil
+	}))
+
+	_, err := Run([]*Task{task})
+
+	var cerr *CancelledError
+	if !errors.As(err, &cerr) {
+		t.Fatalf("expected a CancelledError, got %v", err)
+	}
+	if cerr.Reason != CancelledByCaller {
+		t.Errorf("expected reason %q, got %q", CancelledByCaller, cerr.Reason)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Error("expected error to wrap context.Canceled")
+	}
+	if !errors.Is(err, ErrCancelledByCaller) {
+		t.Error("expected error to match ErrCancelledByCaller")
+	}
+}
+
+func TestCancelReasons(t *testing.T) {
+	tests := []struct {
+		cause  error
+		reason CancelReason
+	}{
+		{cause: ErrPreempted, reason: Preempted},
+		{cause: ErrSiblingFailed, reason: SiblingFailed},
+		{cause: ErrTimedOut, reason: TimedOut},
+		{cause: errors.New("shutdown"), reason: CancelledByCaller},
+	}
+
+	for _, tt := range tests {
+		ctx, cancel := context.WithCancelCause(context.Background())
+		cancel(tt.cause)
+
+		task := New(ctx, WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+			return nil, nil
+		}))
+
+		_, err := Run([]*Task{task})
+
+		var cerr *CancelledError
+		if !errors.As(err, &cerr) {
+			t.Fatalf("expected a CancelledError, got %v", err)
+		}
+		if cerr.Reason != tt.reason {
+			t.Errorf("expected reason %q, got %q", tt.reason, cerr.Reason)
+		}
+		if !errors.Is(err, tt.cause) {
+			t.Errorf("expected error to wrap %v", tt.cause)
+		}
+	}
+}
+
+func TestCancelledWhileRunning(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	task := New(ctx, WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}))
+
+	_, err := Run([]*Task{task})
+	if !errors.Is(err, ErrTimedOut) {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("expected error to wrap context.DeadlineExceeded")
+	}
+}
+
+func TestCheckCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+
+	task := New(ctx, WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		if err := CheckCancelled(ctx); err != nil {
+			t.Fatal("didnt expect task to be cancelled")
+		}
+
+		cancel(ErrPreempted)
+
+		return nil, CheckCancelled(ctx)
+	}))
+
+	_, err := Run([]*Task{task})
+
+	var cerr *CancelledError
+	if !errors.As(err, &cerr) {
+		t.Fatalf("expected a CancelledError, got %v", err)
+	}
+	if cerr.TaskID != task.ID {
+		t.Errorf("expected task id %s, got %s", task.ID, cerr.TaskID)
+	}
+	if cerr.Reason != Preempted {
+		t.Errorf("expected reason %q, got %q", Preempted, cerr.Reason)
+	}
+}
+
+func TestEvery(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	check := Every(3, ctx)
+
+	for i := 1; i <= 6; i++ {
+		err := check()
+		if i%3 == 0 && err == nil {
+			t.Errorf("expected call %d to report the cancellation", i)
+		}
+		if i%3 != 0 && err != nil {
+			t.Errorf("expected call %d to skip the check", i)
+		}
+	}
+}
+
+func TestSleep(t *testing.T) {
+	if err := Sleep(context.Background(), time.Millisecond); err != nil {
+		t.Errorf("didnt expect error, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+
+	if err := Sleep(ctx, time.Minute); !errors.Is(err, ErrTimedOut) {
+		t.Errorf("expected a timeout, got %v", err)
+	}
+}
@@ -0,0 +1,326 @@
+package task
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ErrNoCheckpointStore is returned by Runner.Resume if the Runner has no CheckpointStore.
+var ErrNoCheckpointStore = errors.New("no checkpoint store")
+
+// Checkpoint records the output of a task that completed in a run.
+//
+// Members:
+// - TaskKey: the name of the task, or its ID if it has no name
+// - Output: the output of the task, encoded with JSONCodec
+type Checkpoint struct {
+	TaskKey string            `json:"task_key"`
+	Output  ParameterEnvelope `json:"output"`
+}
+
+// CheckpointReader is the read path of a CheckpointStore.
+type CheckpointReader interface {
+	Load(ctx context.Context, graphID string) ([]Checkpoint, error)
+}
+
+// CheckpointWriter is the write path of a CheckpointStore.
+type CheckpointWriter interface {
+	Save(ctx context.Context, graphID string, c Checkpoint) error
+	Delete(ctx context.Context, graphID string) error
+}
+
+// CheckpointStore persists the checkpoints of runs started with Runner.Resume, keyed by graph ID. Implementations
+// backed by SQL databases or Redis only have to store and return the checkpoints of a graph in the order they were saved.
+// Implementations must be safe for concurrent use. See SplitCheckpointStore for sending reads and writes to different backends.
+type CheckpointStore interface {
+	CheckpointReader
+	CheckpointWriter
+}
+
+// SplitCheckpointStore returns a CheckpointStore writing checkpoints with w and reading them with r, e.g. a primary database and one of its replicas,
+// so heavy queries by other tools can go to the replica without slowing down the runs writing to the primary. Writes of a BatchingCheckpointStore
+// are only batched if w implements CheckpointBatchSaver.
+//
+// Runner.Resume reads the checkpoints of a graph once before it starts. A replica lagging behind the primary returns fewer checkpoints,
+// so tasks that completed in an earlier run execute again. Only use replicas for r that are synchronously replicated, or resume graphs
+// long enough after they were interrupted.
+func SplitCheckpointStore(w CheckpointWriter, r CheckpointReader) CheckpointStore {
+	return splitCheckpointStore{
+		CheckpointReader: r,
+		CheckpointWriter: w,
+	}
+}
+
+// splitCheckpointStore is a CheckpointStore combining a CheckpointReader and a CheckpointWriter.
+type splitCheckpointStore struct {
+	CheckpointReader
+	CheckpointWriter
+}
+
+// SaveBatch implements the CheckpointBatchSaver interface, saving the checkpoints one by one if the CheckpointWriter can't save batches.
+func (s splitCheckpointStore) SaveBatch(ctx context.Context, graphID string, cs []Checkpoint) error {
+	if saver, ok := s.CheckpointWriter.(CheckpointBatchSaver); ok {
+		return saver.SaveBatch(ctx, graphID, cs)
+	}
+	for _, c := range cs {
+		if err := s.Save(ctx, graphID, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WithCheckpointStore returns a RunnerConfigFunc that makes runs started with Runner.Resume record the output of every completed task in store.
+func WithCheckpointStore(store CheckpointStore) RunnerConfigFunc {
+	return func(o *RunOptions) {
+		o.CheckpointStore = store
+	}
+}
+
+// checkpointKey returns the key under which the output of a task is checkpointed.
+func checkpointKey(task *Task) string {
+	if task.Name != "" {
+		return task.Name
+	}
+	return task.ID
+}
+
+// Resume executes the tasks like RunCtx, recording the output of every completed task in the CheckpointStore of the Runner under graphID.
+// Tasks that already completed in an earlier run with the same graphID are not executed again: their recorded outputs are used instead,
+// so a graph interrupted by a crash continues from where it stopped. Use Resume for the first run of a graph as well.
+//
+// Tasks are matched with their checkpoints by name, or by ID for tasks without a name, so named tasks are recommended as IDs depend
+// on the order in which tasks are created. Outputs are encoded with JSONCodec and their types have to be registered with RegisterParameter.
+// Failing to record a checkpoint fails the run. When a task fails, the checkpointed tasks are kept instead of reverted, so resuming the graph
+// continues with the failed task; only tasks that completed without being checkpointed, e.g. while the run was winding down, are reverted.
+// When the run fails for any other reason, e.g. because ctx was cancelled, every completed task is reverted, including tasks completed
+// by earlier runs, and the checkpoints of the graph are deleted. Checkpoints of successful runs are kept, so resuming a completed graph
+// returns its results without executing any task. Delete them from the store once they are no longer needed.
+//
+// Example usage:
+//
+//	runner := task.NewRunner(task.WithCheckpointStore(task.NewFileCheckpointStore("/var/lib/billing/checkpoints")))
+//
+//	results, err := runner.Resume(ctx, "invoice-2024-01", tasks)
+func (r *Runner) Resume(ctx context.Context, graphID string, tasks []*Task, values ...interface{}) (*Results, error) {
+	store := r.Options.CheckpointStore
+	if store == nil {
+		return nil, ErrNoCheckpointStore
+	}
+
+	var checkpoints []Checkpoint
+	err := r.storeCall(ctx, func(ctx context.Context) (err error) {
+		checkpoints, err = store.Load(ctx, graphID)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("load checkpoints of graph %s: %w", graphID, err)
+	}
+
+	restored := make(map[string]interface{}, len(checkpoints))
+	for _, c := range checkpoints {
+		outputs, err := DecodeParameters(c.Output)
+		if err != nil {
+			return nil, fmt.Errorf("decode checkpoint of task %s: %w", c.TaskKey, err)
+		}
+		restored[c.TaskKey] = outputs[0]
+	}
+
+	s := r.newRun(ctx, graphID, restored)
+	results, err := s.start(tasks, values...)
+	if err != nil && !s.resumable {
+		derr := r.storeCall(context.WithoutCancel(ctx), func(ctx context.Context) error {
+			return store.Delete(ctx, graphID)
+		})
+		if derr != nil {
+			err = errors.Join(err, fmt.Errorf("delete checkpoints of graph %s: %w", graphID, derr))
+		}
+	}
+	return results, err
+}
+
+// checkpoint records the output of a completed task, if the run was started with Runner.Resume.
+func (s *run) checkpoint(task *Task, val interface{}) error {
+	if s.graphID == "" {
+		return nil
+	}
+
+	outputs, err := EncodeParameters(JSONCodec, val)
+	if err != nil {
+		return fmt.Errorf("checkpoint task %s: %w", task.ID, err)
+	}
+
+	c := Checkpoint{
+		TaskKey: checkpointKey(task),
+		Output:  outputs[0],
+	}
+	err = s.storeWrite(task, func(ctx context.Context) error {
+		return s.runner.Options.CheckpointStore.Save(ctx, s.graphID, c)
+	})
+	if err != nil {
+		return fmt.Errorf("checkpoint task %s: %w", task.ID, err)
+	}
+
+	// the completion of a compensable task has to be durable before tasks depending on it start
+	if task.Revert != nil {
+		if err := s.flushCheckpoints(task); err != nil {
+			return fmt.Errorf("checkpoint task %s: %w", task.ID, err)
+		}
+	}
+	return nil
+}
+
+// MemoryCheckpointStore is a CheckpointStore keeping checkpoints in memory. It is meant for tests, as checkpoints don't survive a restart.
+type MemoryCheckpointStore struct {
+	mu     sync.Mutex
+	graphs map[string][]Checkpoint
+}
+
+// NewMemoryCheckpointStore creates a new, empty MemoryCheckpointStore.
+func NewMemoryCheckpointStore() *MemoryCheckpointStore {
+	return &MemoryCheckpointStore{
+		graphs: make(map[string][]Checkpoint),
+	}
+}
+
+// Save implements the CheckpointStore interface.
+func (s *MemoryCheckpointStore) Save(_ context.Context, graphID string, c Checkpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.graphs[graphID] = append(s.graphs[graphID], c)
+	return nil
+}
+
+// SaveBatch implements the CheckpointBatchSaver interface.
+func (s *MemoryCheckpointStore) SaveBatch(_ context.Context, graphID string, cs []Checkpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.graphs[graphID] = append(s.graphs[graphID], cs...)
+	return nil
+}
+
+// Load implements the CheckpointStore interface.
+func (s *MemoryCheckpointStore) Load(_ context.Context, graphID string) ([]Checkpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Checkpoint(nil), s.graphs[graphID]...), nil
+}
+
+// Delete implements the CheckpointStore interface.
+func (s *MemoryCheckpointStore) Delete(_ context.Context, graphID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.graphs, graphID)
+	return nil
+}
+
+// FileCheckpointStore is a CheckpointStore keeping the checkpoints of every graph in a file of its own within a directory.
+// Every checkpoint is appended to the file as a line of JSON and synced to disk before Save returns.
+type FileCheckpointStore struct {
+	mu  sync.Mutex
+	dir string
+}
+
+// NewFileCheckpointStore creates a new FileCheckpointStore writing to dir. The directory is created on the first Save.
+func NewFileCheckpointStore(dir string) *FileCheckpointStore {
+	return &FileCheckpointStore{
+		dir: dir,
+	}
+}
+
+// path returns the path of the file holding the checkpoints of a graph.
+func (s *FileCheckpointStore) path(graphID string) string {
+	return filepath.Join(s.dir, url.PathEscape(graphID)+".jsonl")
+}
+
+// Save implements the CheckpointStore interface.
+func (s *FileCheckpointStore) Save(ctx context.Context, graphID string, c Checkpoint) error {
+	return s.SaveBatch(ctx, graphID, []Checkpoint{c})
+}
+
+// SaveBatch implements the CheckpointBatchSaver interface. The checkpoints are appended to the file of the graph and synced to disk at once.
+func (s *FileCheckpointStore) SaveBatch(_ context.Context, graphID string, cs []Checkpoint) error {
+	var lines []byte
+	for _, c := range cs {
+		line, err := json.Marshal(c)
+		if err != nil {
+			return err
+		}
+		lines = append(append(lines, line...), '\n')
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.path(graphID), os.O_APPEND|os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return err
+	}
+
+	// terminate a truncated line left behind by a crash, so the new checkpoints start on a line of their own
+	if info, err := f.Stat(); err == nil && info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := f.ReadAt(last, info.Size()-1); err == nil && last[0] != '\n' {
+			lines = append([]byte{'\n'}, lines...)
+		}
+	}
+
+	if _, err := f.Write(lines); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Load implements the CheckpointStore interface. A graph without checkpoints yields no checkpoints and no error.
+// Truncated lines, left behind by a crash while saving, are skipped.
+func (s *FileCheckpointStore) Load(_ context.Context, graphID string) ([]Checkpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.Open(s.path(graphID))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var checkpoints []Checkpoint
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 16*1024*1024)
+	for scanner.Scan() {
+		var c Checkpoint
+		if err := json.Unmarshal(scanner.Bytes(), &c); err != nil {
+			continue
+		}
+		checkpoints = append(checkpoints, c)
+	}
+	return checkpoints, scanner.Err()
+}
+
+// Delete implements the CheckpointStore interface.
+func (s *FileCheckpointStore) Delete(_ context.Context, graphID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.Remove(s.path(graphID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
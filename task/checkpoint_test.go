@@ -0,0 +1,276 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestResume(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryCheckpointStore()
+	runner := NewRunner(WithCheckpointStore(store))
+
+	var calls []string
+	graph := func() []*Task {
+		create := New(ctx, WithName("create"), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+			calls = append(calls, "create")
+			return "user-1", nil
+		}))
+		process := New(ctx, WithName("process"), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+			calls = append(calls, "process")
+			return values[0].(string) + " processed", nil
+		}))
+		create.AddSubtasks(process)
+		return []*Task{create}
+	}
+
+	// simulate a crash after create completed
+	envs, _ := EncodeParameters(JSONCodec, "user-1")
+	if err := store.Save(ctx, "graph-1", Checkpoint{TaskKey: "create", Output: envs[0]}); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+
+	results, err := runner.Resume(ctx, "graph-1", graph())
+	if err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if !reflect.DeepEqual(calls, []string{"process"}) {
+		t.Errorf("expected only process to run, got %v", calls)
+	}
+	if !reflect.DeepEqual(results.Values(), []interface{}{"user-1", "user-1 processed"}) {
+		t.Errorf("expected restored and new outputs, got %v", results.Values())
+	}
+
+	calls = nil
+	results, err = runner.Resume(ctx, "graph-1", graph())
+	if err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if len(calls) != 0 {
+		t.Errorf("expected no task to run for a completed graph, got %v", calls)
+	}
+	if results.Len() != 2 {
+		t.Errorf("expected %d results, got %d", 2, results.Len())
+	}
+}
+
+func TestResumeFailureKeepsCheckpoints(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryCheckpointStore()
+	runner := NewRunner(WithCheckpointStore(store))
+
+	envs, _ := EncodeParameters(JSONCodec, "user-1")
+	_ = store.Save(ctx, "graph-1", Checkpoint{TaskKey: "create", Output: envs[0]})
+
+	failing := true
+	reverted := false
+	graph := func() []*Task {
+		create := New(ctx, WithName("create"), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+			t.Error("didnt expect the completed task to run")
+			return nil, nil
+		}), WithRevertFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+			reverted = true
+			return nil, nil
+		}))
+		create.AddSubtasks(New(ctx, WithName("process"), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+			if failing {
+				return nil, errors.New("foobar")
+			}
+			return values[0].(string) + " processed", nil
+		})))
+		return []*Task{create}
+	}
+
+	if _, err := runner.Resume(ctx, "graph-1", graph()); err == nil {
+		t.Fatal("expected an error")
+	}
+	if reverted {
+		t.Error("didnt expect the restored task to be reverted")
+	}
+	if checkpoints, _ := store.Load(ctx, "graph-1"); len(checkpoints) != 1 {
+		t.Errorf("expected the checkpoints to be kept, got %v", checkpoints)
+	}
+
+	failing = false
+	results, err := runner.Resume(ctx, "graph-1", graph())
+	if err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if !reflect.DeepEqual(results.Values(), []interface{}{"user-1", "user-1 processed"}) {
+		t.Errorf("expected the run to continue from the failed task, got %v", results.Values())
+	}
+}
+
+func TestResumeCancelDeletesCheckpoints(t *testing.T) {
+	store := NewMemoryCheckpointStore()
+	runner := NewRunner(WithCheckpointStore(store))
+
+	envs, _ := EncodeParameters(JSONCodec, "user-1")
+	_ = store.Save(context.Background(), "graph-1", Checkpoint{TaskKey: "create", Output: envs[0]})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var reverted []interface{}
+	create := New(context.Background(), WithName("create"), WithFunc(noop), WithRevertFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		reverted = values
+		return nil, nil
+	}))
+	create.AddSubtasks(New(context.Background(), WithName("process"), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		cancel()
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})))
+
+	if _, err := runner.Resume(ctx, "graph-1", []*Task{create}); err == nil {
+		t.Fatal("expected an error")
+	}
+	if !reflect.DeepEqual(reverted, []interface{}{"user-1"}) {
+		t.Errorf("expected the restored task to be reverted with its output, got %v", reverted)
+	}
+	if checkpoints, _ := store.Load(context.Background(), "graph-1"); len(checkpoints) != 0 {
+		t.Errorf("expected checkpoints to be deleted, got %v", checkpoints)
+	}
+}
+
+func TestResumePointerOutput(t *testing.T) {
+	ctx := context.Background()
+	runner := NewRunner(WithCheckpointStore(NewMemoryCheckpointStore()))
+
+	failing := true
+	graph := func() []*Task {
+		create := New(ctx, WithName("create"), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+			return &invoice{ID: "inv_1"}, nil
+		}))
+		create.AddSubtasks(New(ctx, WithName("process"), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+			inv, ok := values[0].(*invoice)
+			if !ok {
+				return nil, fmt.Errorf("expected *invoice, got %T", values[0])
+			}
+			if failing {
+				return nil, errors.New("foobar")
+			}
+			return inv.ID, nil
+		})))
+		return []*Task{create}
+	}
+
+	if _, err := runner.Resume(ctx, "graph-1", graph()); err == nil || err.Error() != "foobar" {
+		t.Fatalf("expected foobar, got %v", err)
+	}
+
+	failing = false
+	results, err := runner.Resume(ctx, "graph-1", graph())
+	if err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if id, _ := GetAs[string](results, "process"); id != "inv_1" {
+		t.Errorf("expected %q, got %q", "inv_1", id)
+	}
+}
+
+func TestResumeUnregisteredOutput(t *testing.T) {
+	type unregistered struct{}
+
+	runner := NewRunner(WithCheckpointStore(NewMemoryCheckpointStore()))
+	task := New(context.Background(), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return unregistered{}, nil
+	}))
+
+	if _, err := runner.Resume(context.Background(), "graph-1", []*Task{task}); !errors.Is(err, ErrUnknownParameterType) {
+		t.Errorf("expected an unknown parameter type error, got %v", err)
+	}
+}
+
+func TestResumeWithoutStore(t *testing.T) {
+	if _, err := NewRunner().Resume(context.Background(), "graph-1", nil); !errors.Is(err, ErrNoCheckpointStore) {
+		t.Errorf("expected a missing checkpoint store error, got %v", err)
+	}
+}
+
+func TestFileCheckpointStore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store := NewFileCheckpointStore(filepath.Join(dir, "checkpoints"))
+
+	if checkpoints, err := store.Load(ctx, "graph/1"); err != nil || len(checkpoints) != 0 {
+		t.Fatalf("expected no checkpoints, got %v, %v", checkpoints, err)
+	}
+
+	envs, _ := EncodeParameters(JSONCodec, "foo", 42)
+	expected := []Checkpoint{{TaskKey: "a", Output: envs[0]}, {TaskKey: "b", Output: envs[1]}}
+	for _, c := range expected {
+		if err := store.Save(ctx, "graph/1", c); err != nil {
+			t.Fatalf("didnt expect error, got %v", err)
+		}
+	}
+
+	// a crash while saving leaves a truncated line behind
+	f, err := os.OpenFile(store.path("graph/1"), os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	_, _ = f.WriteString(`{"task_key":"c","out`)
+	_ = f.Close()
+
+	envs, _ = EncodeParameters(JSONCodec, true)
+	expected = append(expected, Checkpoint{TaskKey: "d", Output: envs[0]})
+	if err := store.Save(ctx, "graph/1", expected[2]); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+
+	checkpoints, err := store.Load(ctx, "graph/1")
+	if err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if !reflect.DeepEqual(checkpoints, expected) {
+		t.Errorf("expected checkpoints %v, got %v", expected, checkpoints)
+	}
+
+	if err := store.Delete(ctx, "graph/1"); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if checkpoints, _ := store.Load(ctx, "graph/1"); len(checkpoints) != 0 {
+		t.Errorf("expected checkpoints to be deleted, got %v", checkpoints)
+	}
+}
+
+func TestSplitCheckpointStore(t *testing.T) {
+	ctx := context.Background()
+	primary, replica := NewMemoryCheckpointStore(), NewMemoryCheckpointStore()
+	runner := NewRunner(WithCheckpointStore(SplitCheckpointStore(primary, replica)))
+
+	// the replica knows about create, the primary doesn't
+	envs, _ := EncodeParameters(JSONCodec, "user-1")
+	if err := replica.Save(ctx, "graph-1", Checkpoint{TaskKey: "create", Output: envs[0]}); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+
+	var calls []string
+	create := New(ctx, WithName("create"), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		calls = append(calls, "create")
+		return "user-1", nil
+	}))
+	process := New(ctx, WithName("process"), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		calls = append(calls, "process")
+		return "processed", nil
+	}))
+	create.AddSubtasks(process)
+
+	if _, err := runner.Resume(ctx, "graph-1", []*Task{create}); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if !reflect.DeepEqual(calls, []string{"process"}) {
+		t.Errorf("expected checkpoints to be read from the replica, got calls %v", calls)
+	}
+
+	checkpoints, _ := primary.Load(ctx, "graph-1")
+	if len(checkpoints) != 1 || checkpoints[0].TaskKey != "process" {
+		t.Errorf("expected checkpoints to be written to the primary, got %v", checkpoints)
+	}
+}
@@ -0,0 +1,160 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is wrapped into the error of an attempt that was not made because the circuit of the task is open, see WithCircuitBreaker.
+var ErrCircuitOpen = errors.New("circuit open")
+
+// CircuitState is the state of the circuit of a task.
+type CircuitState int
+
+const (
+	// CircuitClosed means attempts of the task are made.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen means attempts of the task fail with ErrCircuitOpen until the cooldown passed.
+	CircuitOpen
+	// CircuitHalfOpen means the cooldown passed and a single trial attempt decides whether the circuit closes or opens again.
+	CircuitHalfOpen
+)
+
+// String returns a human readable representation of the CircuitState.
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("CircuitState(%d)", int(s))
+	}
+}
+
+// CircuitBreaker keeps a circuit per task name. After threshold consecutive failed attempts of a task, its circuit opens and further attempts
+// fail right away with ErrCircuitOpen instead of calling the Run function, protecting a downstream system during an outage.
+// Once the cooldown passed, a single trial attempt is made: its success closes the circuit, its failure opens it for another cooldown.
+// Timed out attempts count as failures, otherwise cancelled attempts don't count. A CircuitBreaker is safe for concurrent use.
+type CircuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+// circuit is the state of the circuit of a single task name.
+type circuit struct {
+	failures int
+	openedAt time.Time
+	state    CircuitState
+	trial    bool // whether the trial attempt of a half-open circuit is running
+}
+
+// NewCircuitBreaker creates a new CircuitBreaker opening the circuit of a task after threshold consecutive failures, at least one, for cooldown.
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		threshold: max(threshold, 1),
+		cooldown:  cooldown,
+		circuits:  make(map[string]*circuit),
+	}
+}
+
+// State returns the state of the circuit of the task named name.
+func (b *CircuitBreaker) State(name string) CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.circuits[name]
+	if !ok {
+		return CircuitClosed
+	}
+	if c.state == CircuitOpen && time.Since(c.openedAt) >= b.cooldown {
+		return CircuitHalfOpen
+	}
+	return c.state
+}
+
+// allow returns an error wrapping ErrCircuitOpen if no attempt of the task may be made, and whether the attempt is the trial of a half-open circuit.
+// Every nil error must be followed by a call to report.
+func (b *CircuitBreaker) allow(task *Task) (bool, error) {
+	if task.Name == "" {
+		return false, nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.circuits[task.Name]
+	if !ok {
+		c = &circuit{}
+		b.circuits[task.Name] = c
+	}
+
+	if c.state == CircuitOpen {
+		if time.Since(c.openedAt) < b.cooldown {
+			return false, fmt.Errorf("task %s: %w", task.Name, ErrCircuitOpen)
+		}
+		c.state = CircuitHalfOpen
+	}
+	if c.state == CircuitHalfOpen {
+		if c.trial {
+			return false, fmt.Errorf("task %s: %w", task.Name, ErrCircuitOpen)
+		}
+		c.trial = true
+		return true, nil
+	}
+	return false, nil
+}
+
+// report records the outcome of an attempt of the task allowed by allow.
+func (b *CircuitBreaker) report(task *Task, trial bool, err error) {
+	if task.Name == "" {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.circuits[task.Name]
+	if trial {
+		c.trial = false
+	}
+
+	switch {
+	case uninformative(err):
+		// cancellations other than timeouts say nothing about the health of the downstream, a cancelled trial lets the next attempt try again
+	case err != nil:
+		c.failures++
+		if trial || c.failures >= b.threshold {
+			c.state = CircuitOpen
+			c.openedAt = time.Now()
+		}
+	default:
+		c.failures = 0
+		c.state = CircuitClosed
+	}
+}
+
+// WithCircuitBreaker returns a RunnerConfigFunc that guards every named task executed by the Runner with a circuit, see CircuitBreaker.
+// The circuits are kept by the Runner, so repeated failures of a task in one run short-circuit its executions in the following runs.
+// Tasks without a name are not guarded. The state of a circuit is available with Options.CircuitBreaker.State.
+//
+// Example usage:
+//
+//	runner := task.NewRunner(task.WithCircuitBreaker(5, 30*time.Second))
+//
+//	_, err := runner.Run(tasks)
+//	if errors.Is(err, task.ErrCircuitOpen) {
+//		// the payment provider is down, try again later
+//	}
+func WithCircuitBreaker(threshold int, cooldown time.Duration) RunnerConfigFunc {
+	return func(o *RunOptions) {
+		o.CircuitBreaker = NewCircuitBreaker(threshold, cooldown)
+	}
+}
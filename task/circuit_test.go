@@ -0,0 +1,141 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	runner := NewRunner(WithCircuitBreaker(2, 20*time.Millisecond))
+	b := runner.Options.CircuitBreaker
+
+	calls := 0
+	healthy := false
+	charge := func() *Task {
+		return New(context.Background(), WithName("charge"), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+			calls++
+			if !healthy {
+				return nil, errors.New("provider down")
+			}
+			return "charged", nil
+		}))
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := runner.Run([]*Task{charge()}); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("expected the task to fail, got %v", err)
+		}
+	}
+	if b.State("charge") != CircuitOpen {
+		t.Fatalf("expected the circuit to be open, got %s", b.State("charge"))
+	}
+
+	if _, err := runner.Run([]*Task{charge()}); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected the task not to run while the circuit is open, got %d calls", calls)
+	}
+
+	// a failing trial opens the circuit again
+	time.Sleep(25 * time.Millisecond)
+	if b.State("charge") != CircuitHalfOpen {
+		t.Fatalf("expected the circuit to be half-open, got %s", b.State("charge"))
+	}
+	if _, err := runner.Run([]*Task{charge()}); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected the trial to fail, got %v", err)
+	}
+	if b.State("charge") != CircuitOpen {
+		t.Fatalf("expected the circuit to be open, got %s", b.State("charge"))
+	}
+
+	// a successful trial closes the circuit
+	time.Sleep(25 * time.Millisecond)
+	healthy = true
+	if _, err := runner.Run([]*Task{charge()}); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if b.State("charge") != CircuitClosed {
+		t.Errorf("expected the circuit to be closed, got %s", b.State("charge"))
+	}
+}
+
+func TestCircuitBreakerResetOnSuccess(t *testing.T) {
+	runner := NewRunner(WithCircuitBreaker(2, time.Minute))
+
+	fail := true
+	newTask := func() *Task {
+		return New(context.Background(), WithName("flaky"), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+			if fail {
+				return nil, errors.New("foobar")
+			}
+			return nil, nil
+		}))
+	}
+
+	for _, f := range []bool{true, false, true} {
+		fail = f
+		_, _ = runner.Run([]*Task{newTask()})
+	}
+	if state := runner.Options.CircuitBreaker.State("flaky"); state != CircuitClosed {
+		t.Errorf("expected a success to reset the failure count, got %s", state)
+	}
+}
+
+func TestCircuitBreakerIgnoresCancellation(t *testing.T) {
+	runner := NewRunner(WithCircuitBreaker(1, time.Minute))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	task := New(context.Background(), WithName("slow"), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		cancel()
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}))
+
+	if _, err := runner.RunCtx(ctx, []*Task{task}); err == nil {
+		t.Fatal("expected an error")
+	}
+	if state := runner.Options.CircuitBreaker.State("slow"); state != CircuitClosed {
+		t.Errorf("expected cancellations not to open the circuit, got %s", state)
+	}
+}
+
+func TestCircuitBreakerOpensOnTimeouts(t *testing.T) {
+	runner := NewRunner(WithCircuitBreaker(2, time.Minute))
+	newTask := func() *Task {
+		return New(context.Background(), WithName("hanging"), WithTimeout(time.Millisecond), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}))
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := runner.Run([]*Task{newTask()}); !errors.Is(err, ErrTimedOut) {
+			t.Fatalf("expected %v, got %v", ErrTimedOut, err)
+		}
+	}
+	if state := runner.Options.CircuitBreaker.State("hanging"); state != CircuitOpen {
+		t.Errorf("expected timeouts to open the circuit, got %s", state)
+	}
+	if _, err := runner.Run([]*Task{newTask()}); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("expected %v, got %v", ErrCircuitOpen, err)
+	}
+}
+
+func TestCircuitBreakerUnnamed(t *testing.T) {
+	runner := NewRunner(WithCircuitBreaker(1, time.Minute))
+
+	calls := 0
+	for i := 0; i < 3; i++ {
+		task := New(context.Background(), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+			calls++
+			return nil, errors.New("foobar")
+		}))
+		_, _ = runner.Run([]*Task{task})
+	}
+	if calls != 3 {
+		t.Errorf("expected tasks without a name not to be guarded, got %d calls", calls)
+	}
+}
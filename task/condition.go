@@ -0,0 +1,55 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// ConditionFunc decides whether a task runs. It receives the same values the Run function of the task would receive.
+type ConditionFunc func(ctx context.Context, values ...interface{}) (bool, error)
+
+// WithCondition returns a TaskConfigFunc that only runs the task if cond returns true, so branching workflows don't need to wrap
+// their logic inside every task body. cond is called once, right before the task would start, with the values the task would receive.
+// Results and the TaskContext are available from its context like in the Run function of the task.
+//
+// If cond returns false, the task is skipped: its status is Skipped, it has no output in Results and its dependents receive nil
+// as its output. Its subtasks are skipped as well, without calling their conditions, as they belong to the branch that is not taken.
+// A skipped task is not reverted. If cond returns an error or panics, the task fails with that error.
+//
+// Example usage:
+//
+//	notify := task.New(ctx, task.WithFunc(sendMail), task.WithCondition(func(ctx context.Context, values ...interface{}) (bool, error) {
+//		return values[0].(User).WantsMail, nil
+//	}))
+//	if err := notify.DependsOn(createUser); err != nil {
+//		return err
+//	}
+func WithCondition(cond ConditionFunc) TaskConfigFunc {
+	return func(t *Task) {
+		t.Condition = cond
+	}
+}
+
+// condition calls the condition of the task, if it has one, and reports whether the task runs.
+func (s *run) condition(task *Task, values []interface{}) (bool, error) {
+	if task.Condition == nil {
+		return true, nil
+	}
+
+	ctx := context.WithValue(s.baseContext(task), CtxKey("results"), s.results)
+	ctx = context.WithValue(ctx, CtxKey("ctx"), s.taskContext(task))
+
+	f := func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return task.Condition(ctx, values...)
+	}
+	ok, err := invoke(ctx, task, f, values, s.runner.Options.GoroutineDump)
+	if err != nil {
+		return false, fmt.Errorf("condition of task %s: %w", task.ID, err)
+	}
+	if !ok.(bool) {
+		s.log(ctx, slog.LevelInfo, "task skipped", task)
+		return false, nil
+	}
+	return true, nil
+}
@@ -0,0 +1,88 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestWithCondition(t *testing.T) {
+	ctx := context.Background()
+	var ran []string
+	f := func(name string) TaskFunc {
+		return func(ctx context.Context, values ...interface{}) (interface{}, error) {
+			ran = append(ran, name)
+			return name, nil
+		}
+	}
+	cond := func(ok bool) ConditionFunc {
+		return func(ctx context.Context, values ...interface{}) (bool, error) {
+			if _, err := DecodeCtx(ctx); err != nil {
+				return false, err
+			}
+			return ok, nil
+		}
+	}
+
+	user := New(ctx, WithName("user"), WithFunc(f("user")))
+	mail := New(ctx, WithName("mail"), WithFunc(f("mail")), WithCondition(cond(false)))
+	receipt := New(ctx, WithName("receipt"), WithFunc(f("receipt")))
+	sms := New(ctx, WithName("sms"), WithFunc(f("sms")), WithCondition(cond(true)))
+	summary := New(ctx, WithName("summary"), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return values, nil
+	}))
+	mail.AddSubtasks(receipt)
+	user.AddSubtasks(mail, sms)
+	summary.DependsOn(mail, sms)
+
+	results, err := Run([]*Task{user})
+	if err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+
+	if len(ran) != 2 || ran[0] != "user" || ran[1] != "sms" {
+		t.Errorf("expected tasks user and sms to run, got %v", ran)
+	}
+	for task, status := range map[*Task]Status{mail: Skipped, receipt: Skipped, sms: Succeeded, summary: Succeeded} {
+		if task.Status() != status {
+			t.Errorf("expected task %s to be %s, got %s", task.Name, status, task.Status())
+		}
+	}
+	if _, ok := results.Get("mail"); ok {
+		t.Error("expected no result for the skipped task")
+	}
+
+	v, _ := results.Get("summary")
+	values := v.([]interface{})
+	if len(values) != 2 || values[0] != nil || values[1] != "sms" {
+		t.Errorf("expected the summary to receive [<nil> sms], got %v", values)
+	}
+}
+
+func TestWithConditionError(t *testing.T) {
+	ctx := context.Background()
+	reverted := false
+	first := New(ctx, WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, nil
+	}), WithRevertFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		reverted = true
+		return nil, nil
+	}))
+	second := New(ctx, WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, nil
+	}), WithCondition(func(ctx context.Context, values ...interface{}) (bool, error) {
+		return false, errors.New("foobar")
+	}))
+	first.AddSubtasks(second)
+
+	_, err := Run([]*Task{first})
+	if err == nil || err.Error() != "condition of task "+second.ID+": foobar" {
+		t.Fatalf("expected the error of the condition, got %v", err)
+	}
+	if second.Status() != Failed {
+		t.Errorf("expected %s, got %s", Failed, second.Status())
+	}
+	if !reverted {
+		t.Error("expected the first task to be reverted")
+	}
+}
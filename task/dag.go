@@ -0,0 +1,184 @@
+package task
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DependsOn declares that the task can only start after all given tasks succeeded. A task with declared dependencies
+// receives only the outputs of its dependencies, in the order they were declared, instead of the outputs of all previous tasks.
+// Dependencies and dependents are part of the same graph, so passing either of them to Run executes both.
+// Run fails with a *CycleError if the dependencies and subtasks of a graph form a cycle.
+// If the task or any of the given tasks is sealed, nothing is changed and an error wrapping ErrSealed is returned, see Seal.
+//
+// Example usage:
+//
+//	user := task.New(ctx, task.WithFunc(createUser))
+//	account := task.New(ctx, task.WithFunc(createAccount))
+//	welcome := task.New(ctx, task.WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+//		u, a := values[0].(User), values[1].(Account)
+//		return sendWelcomeMail(u, a)
+//	}))
+//	if err := welcome.DependsOn(user, account); err != nil {
+//		return err
+//	}
+//
+//	_, err := task.Run([]*task.Task{user, account})
+func (t *Task) DependsOn(others ...*Task) error {
+	if err := checkSealed(append([]*Task{t}, others...)...); err != nil {
+		return err
+	}
+
+	t.Dependencies = append(t.Dependencies, others...)
+	for _, other := range others {
+		other.dependents = append(other.dependents, t)
+	}
+	return nil
+}
+
+// CycleError is returned by Run when the subtasks and dependencies of the graph form a cycle.
+// Tasks holds the tasks that are part of or blocked by a cycle.
+type CycleError struct {
+	Tasks []*Task
+}
+
+// Error implements the error interface.
+func (e *CycleError) Error() string {
+	ids := make([]string, 0, len(e.Tasks))
+	for _, t := range e.Tasks {
+		ids = append(ids, t.ID)
+	}
+	return fmt.Sprintf("dependency cycle between tasks %s", strings.Join(ids, ", "))
+}
+
+// Sort returns all tasks reachable from the roots via subtasks and dependencies in topological order, so every task comes
+// after its parents and dependencies. Tasks that are ready at the same time keep the order in which they were discovered,
+// which makes the result deterministic for a given graph. Sort fails like Run for graphs with cycles or duplicate names.
+func Sort(roots []*Task) ([]*Task, error) {
+	g, err := newGraph(roots)
+	if err != nil {
+		return nil, err
+	}
+	return g.sort()
+}
+
+// graph holds the edges of a task graph. A task is ready to run once all of its parents and dependencies succeeded.
+type graph struct {
+	nodes   []*Task
+	pending map[*Task]int
+	next    map[*Task][]*Task
+}
+
+// newGraph discovers all tasks reachable from the roots via subtasks and dependencies and checks that they form
+// a directed acyclic graph by sorting them topologically.
+func newGraph(roots []*Task) (*graph, error) {
+	g := &graph{
+		pending: map[*Task]int{},
+		next:    map[*Task][]*Task{},
+	}
+
+	seen := make(map[*Task]bool, len(roots))
+	queue := make([]*Task, 0, len(roots))
+	visit := func(t *Task) {
+		if !seen[t] {
+			seen[t] = true
+			queue = append(queue, t)
+		}
+	}
+
+	for _, t := range roots {
+		visit(t)
+	}
+	for len(queue) > 0 {
+		t := queue[0]
+		queue = queue[1:]
+		g.nodes = append(g.nodes, t)
+
+		for _, st := range t.Subtasks {
+			g.next[t] = append(g.next[t], st)
+			g.pending[st]++
+			visit(st)
+		}
+		for _, dep := range t.Dependencies {
+			g.next[dep] = append(g.next[dep], t)
+			g.pending[t]++
+			visit(dep)
+		}
+		for _, dependent := range t.dependents {
+			visit(dependent)
+		}
+	}
+
+	names := map[string]bool{}
+	for _, t := range g.nodes {
+		if t.Name == "" {
+			continue
+		}
+		if names[t.Name] {
+			return nil, fmt.Errorf("duplicate task name %q", t.Name)
+		}
+		names[t.Name] = true
+	}
+
+	if _, err := g.sort(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// roots returns the tasks without parents or dependencies in discovery order.
+func (g *graph) roots() []*Task {
+	var roots []*Task
+	for _, t := range g.nodes {
+		if g.pending[t] == 0 {
+			roots = append(roots, t)
+		}
+	}
+	return roots
+}
+
+// complete marks t as succeeded and returns the tasks that became ready to run because of it.
+func (g *graph) complete(t *Task) []*Task {
+	var ready []*Task
+	for _, n := range g.next[t] {
+		g.pending[n]--
+		if g.pending[n] == 0 {
+			ready = append(ready, n)
+		}
+	}
+	return ready
+}
+
+// sort returns the tasks of the graph in topological order, or a *CycleError if the graph contains a cycle.
+func (g *graph) sort() ([]*Task, error) {
+	pending := make(map[*Task]int, len(g.pending))
+	for t, n := range g.pending {
+		pending[t] = n
+	}
+
+	order := make([]*Task, 0, len(g.nodes))
+	for _, t := range g.nodes {
+		if pending[t] == 0 {
+			order = append(order, t)
+		}
+	}
+	for i := 0; i < len(order); i++ {
+		for _, n := range g.next[order[i]] {
+			pending[n]--
+			if pending[n] == 0 {
+				order = append(order, n)
+			}
+		}
+	}
+
+	if len(order) < len(g.nodes) {
+		cerr := &CycleError{}
+		for _, t := range g.nodes {
+			if pending[t] > 0 {
+				cerr.Tasks = append(cerr.Tasks, t)
+			}
+		}
+		return nil, cerr
+	}
+	return order, nil
+}
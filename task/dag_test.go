@@ -0,0 +1,98 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestDependsOn(t *testing.T) {
+	ctx := context.Background()
+
+	user := New(ctx, WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return "user", nil
+	}))
+	account := New(ctx, WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return "account", nil
+	}))
+	unrelated := New(ctx, WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return "unrelated", nil
+	}))
+	user.AddSubtasks(unrelated)
+
+	var got []interface{}
+	welcome := New(ctx, WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		got = values
+		return "welcome", nil
+	}))
+	welcome.DependsOn(account, user)
+
+	for _, r := range []*Runner{NewRunner(), NewRunner(WithConcurrency(4))} {
+		got = nil
+
+		result, err := r.Run([]*Task{user}, "initial")
+		if err != nil {
+			t.Fatalf("didnt expect error, got %v", err)
+		}
+		if result.Len() != 4 {
+			t.Errorf("expected dependencies to be part of the run, got %d results", result.Len())
+		}
+		if len(got) != 2 || got[0] != "account" || got[1] != "user" {
+			t.Errorf("expected only the dependency outputs in declared order, got %v", got)
+		}
+	}
+}
+
+func TestDependsOnWaitsForAllDependencies(t *testing.T) {
+	ctx := context.Background()
+
+	var order []string
+	step := func(name string) *Task {
+		return New(ctx, WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+			order = append(order, name)
+			return name, nil
+		}))
+	}
+
+	a, b, c, d := step("a"), step("b"), step("c"), step("d")
+	a.AddSubtasks(b, c)
+	d.DependsOn(b, c)
+	c.AddSubtasks(d)
+
+	if _, err := Run([]*Task{a}); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+
+	expected := []string{"a", "b", "c", "d"}
+	if len(order) != len(expected) {
+		t.Fatalf("expected every task to run exactly once, got %v", order)
+	}
+	for i := range expected {
+		if order[i] != expected[i] {
+			t.Fatalf("expected order %v, got %v", expected, order)
+		}
+	}
+}
+
+func TestDependencyCycle(t *testing.T) {
+	ctx := context.Background()
+	noop := WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		t.Error("no task should run when the graph has a cycle")
+		return nil, nil
+	})
+
+	a, b, c := New(ctx, noop), New(ctx, noop), New(ctx, noop)
+	a.AddSubtasks(b)
+	b.DependsOn(c)
+	c.DependsOn(b)
+
+	_, err := Run([]*Task{a})
+
+	var cerr *CycleError
+	if !errors.As(err, &cerr) {
+		t.Fatalf("expected a CycleError, got %v", err)
+	}
+	if len(cerr.Tasks) != 2 {
+		t.Errorf("expected the 2 tasks of the cycle, got %d", len(cerr.Tasks))
+	}
+}
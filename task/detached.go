@@ -0,0 +1,66 @@
+package task
+
+import (
+	"context"
+	"log/slog"
+)
+
+// FailureHandler is called with the error of a run started with SubmitDetached that failed, as nobody awaits its results.
+type FailureHandler func(ctx context.Context, runID string, err error)
+
+// WithFailureHandler returns a RunnerConfigFunc that calls h with the error of every run started with SubmitDetached that failed,
+// e.g. to alert whoever owns the workflow. h is called on the goroutine of the run after every task that succeeded was reverted.
+//
+// Example usage:
+//
+//	runner := task.NewRunner(task.WithFailureHandler(func(ctx context.Context, runID string, err error) {
+//		alerts.Send(ctx, "saga "+runID+" failed", err)
+//	}))
+func WithFailureHandler(h FailureHandler) RunnerConfigFunc {
+	return func(o *RunOptions) {
+		o.FailureHandler = h
+	}
+}
+
+// SubmitDetached executes the tasks in the background like Submit for runs whose results nobody awaits, e.g. sagas started by an API handler.
+// The run and the contexts of its tasks are detached from cancellation, so it keeps going after the request that started it completed,
+// e.g. when the tasks were created with the context of the request, while the values of the contexts stay available. Nothing refers to the run once it completed, so detached runs don't pile up in memory.
+//
+// The returned ID identifies the run in Runner.Snapshot, the events of Subscribe and the logs of the Runner. A failure is passed
+// to the FailureHandler of the Runner and logged as "detached run failed" if the Runner has a logger.
+//
+// Example usage:
+//
+//	func (h *Handler) CreateOrder(w http.ResponseWriter, r *http.Request) {
+//		runID := h.runner.SubmitDetached(r.Context(), orderSaga(order))
+//		w.Header().Set("X-Run-ID", runID)
+//		w.WriteHeader(http.StatusAccepted)
+//	}
+func (r *Runner) SubmitDetached(ctx context.Context, tasks []*Task, values ...interface{}) string {
+	ctx = context.WithoutCancel(ctx)
+	s := r.newRun(ctx, "", nil)
+	s.detached = true
+
+	go func() {
+		_, err := s.start(tasks, values...)
+		if err == nil {
+			return
+		}
+
+		if l := r.Options.Logger; l != nil {
+			l.LogAttrs(ctx, slog.LevelError, "detached run failed", slog.String("run_id", s.id), slog.Any("error", err))
+		}
+		if h := r.Options.FailureHandler; h != nil {
+			h(ctx, s.id, err)
+		}
+	}()
+	return s.id
+}
+
+// baseContext returns the context the executions and reverts of the task derive from.
+func (s *run) baseContext(task *Task) context.Context {
+	if s.detached {
+		return context.WithoutCancel(task.Context)
+	}
+	return task.Context
+}
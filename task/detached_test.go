@@ -0,0 +1,57 @@
+package task
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSubmitDetached(t *testing.T) {
+	type failure struct {
+		runID string
+		err   error
+	}
+	failures := make(chan failure, 1)
+	var buf bytes.Buffer
+	runner := NewRunner(WithLogger(slog.New(slog.NewJSONHandler(&buf, nil))), WithFailureHandler(func(ctx context.Context, runID string, err error) {
+		failures <- failure{runID: runID, err: err}
+	}))
+
+	// the run outlives the request that started it
+	ctx, cancel := context.WithCancel(context.Background())
+	release := make(chan struct{})
+	task := New(ctx, WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		<-release
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, errors.New("foobar")
+	}))
+
+	runID := runner.SubmitDetached(ctx, []*Task{task})
+	cancel()
+	close(release)
+
+	select {
+	case f := <-failures:
+		if f.runID != runID {
+			t.Errorf("expected run ID %s, got %s", runID, f.runID)
+		}
+		if f.err == nil || f.err.Error() != "foobar" {
+			t.Errorf("expected error %q, got %v", "foobar", f.err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the failure handler to be called")
+	}
+
+	if !strings.Contains(buf.String(), `"msg":"detached run failed","run_id":"`+runID+`"`) {
+		t.Errorf("expected the failure to be logged, got %s", buf.String())
+	}
+	if len(runner.Snapshot()) != 0 {
+		t.Error("expected no runs to be retained")
+	}
+}
@@ -0,0 +1,123 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// DurabilityMode describes how a run reacts to a CheckpointStore or IdempotencyStore failing to record an output.
+type DurabilityMode int
+
+const (
+	// FailOnStoreError fails the run as soon as an output can't be recorded. It is the default.
+	FailOnStoreError DurabilityMode = iota
+	// BufferOnStoreError keeps outputs that can't be recorded in memory and keeps running. The buffered outputs are written
+	// in order before the next output is recorded and when the run completes. The run fails only if they still can't be written then.
+	BufferOnStoreError
+)
+
+// String returns a human readable representation of the DurabilityMode.
+func (m DurabilityMode) String() string {
+	switch m {
+	case FailOnStoreError:
+		return "fail on store error"
+	case BufferOnStoreError:
+		return "buffer on store error"
+	default:
+		return fmt.Sprintf("DurabilityMode(%d)", int(m))
+	}
+}
+
+// DurabilityPolicy describes how a run deals with a CheckpointStore or IdempotencyStore that is unavailable.
+// Every call to a store is retried according to Retry. Reads that still fail, e.g. loading the checkpoints of Resume or looking up
+// an idempotency key, fail the run submission or the task. Writes that still fail are handled according to Mode.
+//
+// Members:
+// - Mode: what happens when an output can't be recorded after all retries
+// - Retry: the policy describing how often a failing call to a store is retried, values below 2 disable retries
+type DurabilityPolicy struct {
+	Mode  DurabilityMode
+	Retry RetryPolicy
+}
+
+// WithDurabilityPolicy returns a RunnerConfigFunc that applies p to the calls to the CheckpointStore and IdempotencyStore of the Runner,
+// so a persistence backend going down mid-run is handled in a defined way. Without a policy, calls are not retried and a failing write fails the run.
+//
+// Buffered outputs are lost if the process crashes before they are written, so a resumed run executes such tasks again,
+// and an idempotency key whose output is buffered is not reused by other tasks of the run.
+//
+// Example usage:
+//
+//	runner := task.NewRunner(task.WithCheckpointStore(store), task.WithDurabilityPolicy(task.DurabilityPolicy{
+//		Mode:  task.BufferOnStoreError,
+//		Retry: task.RetryPolicy{Attempts: 3, Backoff: task.ExponentialBackoff(100*time.Millisecond, time.Second)},
+//	}))
+func WithDurabilityPolicy(p DurabilityPolicy) RunnerConfigFunc {
+	return func(o *RunOptions) {
+		o.Durability = p
+	}
+}
+
+// storeCall calls op, retrying it according to the DurabilityPolicy of the Runner.
+func (r *Runner) storeCall(ctx context.Context, op func(ctx context.Context) error) error {
+	p := r.Options.Durability.Retry
+	for attempt := 1; ; attempt++ {
+		err := op(ctx)
+		if err == nil || attempt >= p.Attempts || ctx.Err() != nil {
+			return err
+		}
+
+		if p.Backoff != nil {
+			if Sleep(ctx, p.Backoff.Backoff(attempt)) != nil {
+				return err
+			}
+		}
+	}
+}
+
+// bufferedWrite is a write to a store that failed and is retried later.
+type bufferedWrite struct {
+	task *Task
+	op   func(ctx context.Context) error
+}
+
+// storeWrite records the output of a task with op according to the DurabilityPolicy of the Runner. Buffered writes are flushed first,
+// so outputs are recorded in completion order. It is only called by the goroutine scheduling the run.
+func (s *run) storeWrite(task *Task, op func(ctx context.Context) error) error {
+	if s.runner.Options.Durability.Mode != BufferOnStoreError {
+		return s.runner.storeCall(s.ctx, op)
+	}
+
+	err := s.flush(s.ctx)
+	if err == nil {
+		if err = s.runner.storeCall(s.ctx, op); err == nil {
+			return nil
+		}
+	}
+
+	s.log(task.Context, slog.LevelWarn, "task output buffered", task, slog.Any("error", err))
+	s.buffered = append(s.buffered, bufferedWrite{task: task, op: op})
+	return nil
+}
+
+// flush writes the buffered outputs in order. It stops at the first write that fails and returns its error.
+func (s *run) flush(ctx context.Context) error {
+	for len(s.buffered) > 0 {
+		if err := s.runner.storeCall(ctx, s.buffered[0].op); err != nil {
+			return err
+		}
+		s.buffered[0] = bufferedWrite{} // Clear the pointers for garbage collection
+		s.buffered = s.buffered[1:]
+	}
+	return nil
+}
+
+// flushAll writes the buffered outputs when the run completes. The writes are made even if the run was cancelled,
+// so the outputs of tasks that completed are not lost.
+func (s *run) flushAll() error {
+	if err := s.flush(context.WithoutCancel(s.ctx)); err != nil {
+		return fmt.Errorf("flush buffered output of task %s: %w", s.buffered[0].task.ID, err)
+	}
+	return nil
+}
@@ -0,0 +1,208 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+var errStoreDown = errors.New("store down")
+
+// flakyCheckpointStore is a MemoryCheckpointStore failing while it is down, or for the next failures calls.
+type flakyCheckpointStore struct {
+	*MemoryCheckpointStore
+
+	mu       sync.Mutex
+	down     bool
+	failures int
+	calls    int
+}
+
+func (s *flakyCheckpointStore) fail() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	if s.down {
+		return errStoreDown
+	}
+	if s.failures > 0 {
+		s.failures--
+		return errStoreDown
+	}
+	return nil
+}
+
+func (s *flakyCheckpointStore) setDown(down bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.down = down
+}
+
+func (s *flakyCheckpointStore) Save(ctx context.Context, graphID string, c Checkpoint) error {
+	if err := s.fail(); err != nil {
+		return err
+	}
+	return s.MemoryCheckpointStore.Save(ctx, graphID, c)
+}
+
+func (s *flakyCheckpointStore) Load(ctx context.Context, graphID string) ([]Checkpoint, error) {
+	if err := s.fail(); err != nil {
+		return nil, err
+	}
+	return s.MemoryCheckpointStore.Load(ctx, graphID)
+}
+
+func newFlakyCheckpointStore() *flakyCheckpointStore {
+	return &flakyCheckpointStore{MemoryCheckpointStore: NewMemoryCheckpointStore()}
+}
+
+// checkpointedTasks returns the task keys of the checkpoints of graphID in the order they were saved.
+func checkpointedTasks(t *testing.T, store CheckpointStore, graphID string) []string {
+	checkpoints, err := store.Load(context.Background(), graphID)
+	if err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	var keys []string
+	for _, c := range checkpoints {
+		keys = append(keys, c.TaskKey)
+	}
+	return keys
+}
+
+func TestDurabilityFailOnStoreError(t *testing.T) {
+	store := newFlakyCheckpointStore()
+	runner := NewRunner(WithCheckpointStore(store))
+
+	reverted := false
+	a := New(context.Background(), WithName("a"), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		store.setDown(true)
+		return "a", nil
+	}), WithRevertFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		reverted = true
+		return nil, nil
+	}))
+
+	if _, err := runner.Resume(context.Background(), "graph", []*Task{a}); !errors.Is(err, errStoreDown) {
+		t.Fatalf("expected the store error, got %v", err)
+	}
+	if !reverted {
+		t.Error("expected the task to be reverted")
+	}
+}
+
+func TestDurabilityRetry(t *testing.T) {
+	store := newFlakyCheckpointStore()
+	store.failures = 2
+	runner := NewRunner(WithCheckpointStore(store), WithDurabilityPolicy(DurabilityPolicy{
+		Retry: RetryPolicy{Attempts: 3},
+	}))
+
+	a := New(context.Background(), WithName("a"), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return "a", nil
+	}))
+
+	// the load fails twice and succeeds on its third attempt
+	if _, err := runner.Resume(context.Background(), "graph", []*Task{a}); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if store.calls != 4 {
+		t.Errorf("expected %d calls to the store, got %d", 4, store.calls)
+	}
+	if keys := checkpointedTasks(t, store, "graph"); !reflect.DeepEqual(keys, []string{"a"}) {
+		t.Errorf("expected checkpoints %v, got %v", []string{"a"}, keys)
+	}
+}
+
+func TestDurabilityBuffer(t *testing.T) {
+	store := newFlakyCheckpointStore()
+	runner := NewRunner(WithCheckpointStore(store), WithDurabilityPolicy(DurabilityPolicy{Mode: BufferOnStoreError}))
+
+	value := func(v string, down bool) *Task {
+		return New(context.Background(), WithName(v), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+			store.setDown(down)
+			return v, nil
+		}))
+	}
+	a, b, c := value("a", true), value("b", true), value("c", false)
+	a.AddSubtasks(b)
+	b.AddSubtasks(c)
+
+	if _, err := runner.Resume(context.Background(), "graph", []*Task{a}); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	expected := []string{"a", "b", "c"}
+	if keys := checkpointedTasks(t, store, "graph"); !reflect.DeepEqual(keys, expected) {
+		t.Errorf("expected buffered checkpoints to be flushed in order %v, got %v", expected, keys)
+	}
+}
+
+func TestDurabilityBufferFlushFails(t *testing.T) {
+	store := newFlakyCheckpointStore()
+	runner := NewRunner(WithCheckpointStore(store), WithDurabilityPolicy(DurabilityPolicy{Mode: BufferOnStoreError}))
+
+	var calls []string
+	a := New(context.Background(), WithName("a"), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		calls = append(calls, "a")
+		store.setDown(true)
+		return "a", nil
+	}), WithRevertFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		calls = append(calls, "revert a")
+		return nil, nil
+	}))
+	b := New(context.Background(), WithName("b"), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		calls = append(calls, "b")
+		return "b", nil
+	}))
+	a.AddSubtasks(b)
+
+	_, err := runner.Resume(context.Background(), "graph", []*Task{a})
+	if !errors.Is(err, errStoreDown) {
+		t.Fatalf("expected the store error, got %v", err)
+	}
+	if expected := []string{"a", "b", "revert a"}; !reflect.DeepEqual(calls, expected) {
+		t.Errorf("expected the run to continue and fail at the end %v, got %v", expected, calls)
+	}
+}
+
+func TestDurabilityIdempotencyBuffer(t *testing.T) {
+	store := &flakyIdempotencyStore{MemoryIdempotencyStore: NewMemoryIdempotencyStore(), down: true}
+	runner := NewRunner(WithIdempotencyStore(store), WithDurabilityPolicy(DurabilityPolicy{Mode: BufferOnStoreError}))
+
+	a := New(context.Background(), WithIdempotencyKey("charge-1"), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return "charged", nil
+	}))
+	b := New(context.Background(), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		store.mu.Lock()
+		defer store.mu.Unlock()
+		store.down = false
+		return nil, nil
+	}))
+	a.AddSubtasks(b)
+
+	if _, err := runner.Run([]*Task{a}); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if _, ok, _ := store.MemoryIdempotencyStore.Get(context.Background(), "charge-1"); !ok {
+		t.Error("expected the buffered output to be recorded")
+	}
+}
+
+// flakyIdempotencyStore is a MemoryIdempotencyStore failing to record outputs while it is down.
+type flakyIdempotencyStore struct {
+	*MemoryIdempotencyStore
+
+	mu   sync.Mutex
+	down bool
+}
+
+func (s *flakyIdempotencyStore) Put(ctx context.Context, key string, output ParameterEnvelope) error {
+	s.mu.Lock()
+	down := s.down
+	s.mu.Unlock()
+	if down {
+		return errStoreDown
+	}
+	return s.MemoryIdempotencyStore.Put(ctx, key, output)
+}
@@ -0,0 +1,152 @@
+package task
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// EventType is the type of an Event.
+type EventType int
+
+const (
+	// TaskStarted is emitted when a task starts executing. Tasks restored from a checkpoint don't start.
+	TaskStarted EventType = iota
+	// TaskSucceeded is emitted when a task completed successfully, including tasks restored from a checkpoint.
+	TaskSucceeded
+	// TaskFailed is emitted when a task failed after all of its retries or was cancelled.
+	TaskFailed
+	// TaskSkipped is emitted when a task was skipped because its condition returned false or it is a subtask of a skipped task.
+	TaskSkipped
+	// RevertStarted is emitted when the Revert function of a task is called because the run failed.
+	RevertStarted
+	// GraphCompleted is emitted when a run returned.
+	GraphCompleted
+)
+
+// String returns a human readable representation of the EventType.
+func (t EventType) String() string {
+	switch t {
+	case TaskStarted:
+		return "task started"
+	case TaskSucceeded:
+		return "task succeeded"
+	case TaskFailed:
+		return "task failed"
+	case TaskSkipped:
+		return "task skipped"
+	case RevertStarted:
+		return "revert started"
+	case GraphCompleted:
+		return "graph completed"
+	default:
+		return fmt.Sprintf("EventType(%d)", int(t))
+	}
+}
+
+// Event is a state transition of a task or a run, see Runner.Subscribe.
+//
+// Members:
+// - Type: the type of the event
+// - Task: the task the event is about, nil for GraphCompleted
+// - RunID: the ID of the run the event belongs to, see Results.RunID
+// - GraphID: the graph ID passed to Runner.Resume, empty for other runs
+// - Err: the error the task failed with for TaskFailed, the error returned by the run for GraphCompleted
+// - Time: the point in time the event occurred
+type Event struct {
+	Type    EventType
+	Task    *Task
+	RunID   string
+	GraphID string
+	Err     error
+	Time    time.Time
+}
+
+// Subscribe sends an Event to ch for every task and run state transition of the runs the Runner executes, so UIs and monitors can
+// react to them without polling. It returns a function that ends the subscription; ch is never closed.
+//
+// Events are sent without blocking, so a slow subscriber never holds up a run: an event is dropped if ch is not ready to receive it.
+// Use a buffered channel sized for the expected burst of events. Events of concurrent tasks may arrive in any order.
+// Runs of a Runner not created with NewRunner don't emit events.
+//
+// Example usage:
+//
+//	events := make(chan task.Event, 1024)
+//	unsubscribe := runner.Subscribe(events)
+//	defer unsubscribe()
+//
+//	go func() {
+//		for e := range events {
+//			if e.Task == nil {
+//				fmt.Printf("%s %s\n", e.Type, e.RunID)
+//				continue
+//			}
+//			fmt.Printf("%s %s\n", e.Type, e.Task.Name)
+//		}
+//	}()
+func (r *Runner) Subscribe(ch chan<- Event) func() {
+	return r.events.subscribe(ch)
+}
+
+// eventBus keeps track of the subscribers of a Runner.
+type eventBus struct {
+	mu   sync.RWMutex
+	subs []*subscriber
+}
+
+// subscriber is a single subscription, compared by identity so the same channel can be subscribed several times.
+type subscriber struct {
+	ch chan<- Event
+}
+
+// subscribe adds a subscriber and returns a function removing it.
+func (b *eventBus) subscribe(ch chan<- Event) func() {
+	if b == nil {
+		return func() {}
+	}
+
+	sub := &subscriber{ch: ch}
+	b.mu.Lock()
+	b.subs = append(b.subs, sub)
+	b.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			for i, other := range b.subs {
+				if other == sub {
+					b.subs = append(b.subs[:i], b.subs[i+1:]...)
+					return
+				}
+			}
+		})
+	}
+}
+
+// publish sends e to every subscriber that is ready to receive it.
+func (b *eventBus) publish(e Event) {
+	if b == nil {
+		return
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if len(b.subs) == 0 {
+		return
+	}
+
+	e.Time = time.Now()
+	for _, sub := range b.subs {
+		select {
+		case sub.ch <- e:
+		default:
+		}
+	}
+}
+
+// emit publishes an event about a task of the run.
+func (s *run) emit(typ EventType, task *Task, err error) {
+	s.runner.events.publish(Event{Type: typ, Task: task, RunID: s.id, GraphID: s.graphID, Err: err})
+}
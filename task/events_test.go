@@ -0,0 +1,87 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestSubscribe(t *testing.T) {
+	ctx := context.Background()
+	runner := NewRunner()
+	events := make(chan Event, 16)
+	unsubscribe := runner.Subscribe(events)
+
+	first := New(ctx, WithName("first"), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, nil
+	}), WithRevertFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, nil
+	}))
+	second := New(ctx, WithName("second"), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, errors.New("foobar")
+	}))
+	first.AddSubtasks(second)
+
+	_, err := runner.Run([]*Task{first})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	expected := []struct {
+		typ  EventType
+		task *Task
+	}{
+		{TaskStarted, first},
+		{TaskSucceeded, first},
+		{TaskStarted, second},
+		{TaskFailed, second},
+		{RevertStarted, first},
+		{GraphCompleted, nil},
+	}
+	if len(events) != len(expected) {
+		t.Fatalf("expected %d events, got %d", len(expected), len(events))
+	}
+	for _, want := range expected {
+		e := <-events
+		if e.Type != want.typ || e.Task != want.task {
+			t.Errorf("expected %s event, got %s", want.typ, e.Type)
+		}
+		if e.Time.IsZero() {
+			t.Errorf("expected the time of the %s event to be set", e.Type)
+		}
+		if (e.Type == TaskFailed || e.Type == GraphCompleted) && e.Err == nil {
+			t.Errorf("expected the %s event to carry an error", e.Type)
+		}
+	}
+
+	unsubscribe()
+	unsubscribe()
+	if _, err := runner.Run([]*Task{New(ctx, WithFunc(noop))}); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if len(events) != 0 {
+		t.Errorf("expected no events after unsubscribing, got %d", len(events))
+	}
+}
+
+func TestSubscribeDropsEvents(t *testing.T) {
+	runner := NewRunner()
+	events := make(chan Event, 1)
+	defer runner.Subscribe(events)()
+
+	tasks := []*Task{New(context.Background(), WithFunc(noop)), New(context.Background(), WithFunc(noop))}
+	if _, err := runner.Run(tasks); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+
+	if len(events) != 1 {
+		t.Fatalf("expected %d event, got %d", 1, len(events))
+	}
+	if e := <-events; e.Type != TaskStarted {
+		t.Errorf("expected %s event, got %s", TaskStarted, e.Type)
+	}
+}
+
+func noop(ctx context.Context, values ...interface{}) (interface{}, error) {
+	return nil, nil
+}
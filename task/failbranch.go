@@ -0,0 +1,207 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// FailurePolicy decides how much of a run fails when a task fails, see WithFailurePolicy.
+type FailurePolicy int
+
+const (
+	// FailRun cancels the whole run when a task fails and reverts every task that succeeded. It is the default.
+	FailRun FailurePolicy = iota
+	// FailBranch cancels and reverts only the branch of the failed task and lets the other branches complete.
+	FailBranch
+)
+
+// String returns a human readable representation of the FailurePolicy.
+func (p FailurePolicy) String() string {
+	switch p {
+	case FailRun:
+		return "fail run"
+	case FailBranch:
+		return "fail branch"
+	default:
+		return fmt.Sprintf("FailurePolicy(%d)", int(p))
+	}
+}
+
+// WithFailurePolicy returns a RunnerConfigFunc that sets the policy deciding how much of a run fails when a task fails.
+//
+// Under FailBranch, the branches of a run are the subtrees of its roots, or, for a run with a single root, the subtrees of the first
+// task where the graph fans out; the tasks leading up to it form the trunk. A branch holds every task reachable from its head via subtasks
+// and dependents. When a task belonging to a single branch fails, the running tasks of that branch are cancelled with ErrSiblingFailed,
+// its remaining tasks are skipped and, once the other branches completed, the tasks of the branch that succeeded are reverted.
+// The run then returns its Results together with the error of every failed branch. A failure of a trunk task, of a task shared by
+// several branches that is not reachable from a failed branch, or a cancellation of the run itself still fails the whole run.
+//
+// Example usage:
+//
+//	runner := task.NewRunner(task.WithConcurrency(8), task.WithFailurePolicy(task.FailBranch))
+//
+//	results, err := runner.Run([]*task.Task{fetchOrders})
+//	if err != nil {
+//		log.Printf("some branches were reverted: %v", err)
+//	}
+func WithFailurePolicy(policy FailurePolicy) RunnerConfigFunc {
+	return func(o *RunOptions) {
+		o.FailurePolicy = policy
+	}
+}
+
+// branch is a subtree of a run that fails on its own under FailBranch.
+type branch struct {
+	tasks map[*Task]bool
+	err   error // the error of the task that failed the branch
+}
+
+// branches keeps track of the branches of a run and the contexts of their running tasks. A nil *branches disables FailBranch.
+type branches struct {
+	owner  map[*Task]*branch // the branch of every task belonging to exactly one branch
+	failed []*branch         // in the order they failed
+
+	mu      sync.Mutex
+	cancels map[*Task]context.CancelCauseFunc
+}
+
+// newBranches splits the graph with the given roots into branches.
+func newBranches(g *graph, roots []*Task) *branches {
+	heads := roots
+	for len(heads) == 1 && len(g.next[heads[0]]) > 0 {
+		next := unique(g.next[heads[0]])
+		heads = next
+		if len(next) > 1 {
+			break
+		}
+	}
+
+	b := &branches{
+		owner:   make(map[*Task]*branch),
+		cancels: make(map[*Task]context.CancelCauseFunc),
+	}
+	shared := make(map[*Task]bool)
+	for _, head := range heads {
+		br := &branch{tasks: make(map[*Task]bool)}
+		queue := []*Task{head}
+		for len(queue) > 0 {
+			t := queue[0]
+			queue = queue[1:]
+			if br.tasks[t] {
+				continue
+			}
+			br.tasks[t] = true
+			if other, ok := b.owner[t]; ok && other != br || shared[t] {
+				shared[t] = true
+				delete(b.owner, t)
+			} else {
+				b.owner[t] = br
+			}
+			queue = append(queue, g.next[t]...)
+		}
+	}
+	return b
+}
+
+// unique returns tasks without duplicates, keeping the first occurrence of every task.
+func unique(tasks []*Task) []*Task {
+	seen := make(map[*Task]bool, len(tasks))
+	out := make([]*Task, 0, len(tasks))
+	for _, t := range tasks {
+		if !seen[t] {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// of returns the branch task belongs to, nil if it belongs to the trunk or to several branches.
+func (b *branches) of(task *Task) *branch {
+	if b == nil {
+		return nil
+	}
+	return b.owner[task]
+}
+
+// cancelled reports whether task belongs to a branch that failed, so it must not start.
+func (b *branches) cancelled(task *Task) bool {
+	if b == nil {
+		return false
+	}
+	for _, br := range b.failed {
+		if br.tasks[task] {
+			return true
+		}
+	}
+	return false
+}
+
+// fail marks br as failed with err and cancels its running tasks. Failures of a branch that already failed are ignored.
+func (b *branches) fail(br *branch, err error) {
+	if br.err != nil {
+		return
+	}
+	br.err = err
+	b.failed = append(b.failed, br)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for t, cancel := range b.cancels {
+		if br.tasks[t] {
+			cancel(ErrSiblingFailed)
+		}
+	}
+}
+
+// track registers the cancel function of a running task and returns a function unregistering it.
+func (b *branches) track(task *Task, cancel context.CancelCauseFunc) func() {
+	if b == nil {
+		return func() {}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.cancels[task] = cancel
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.cancels, task)
+	}
+}
+
+// err returns the errors of the failed branches, nil if no branch failed.
+func (b *branches) err() error {
+	if b == nil || len(b.failed) == 0 {
+		return nil
+	}
+	if len(b.failed) == 1 {
+		return b.failed[0].err
+	}
+
+	errs := make([]error, 0, len(b.failed))
+	for _, br := range b.failed {
+		errs = append(errs, br.err)
+	}
+	return errors.Join(errs...)
+}
+
+// revertBranches reverts the tasks of the failed branches that succeeded, given in reverse completion order, and skips their remaining tasks.
+// It returns cause unchanged if every revert succeeded and a *RevertError wrapping cause otherwise.
+func (s *run) revertBranches(cause error, succeeded []*Task, values ...interface{}) error {
+	for _, task := range s.nodes {
+		if task.Status() == Pending {
+			task.setStatus(Skipped)
+		}
+	}
+
+	tasks := make([]*Task, 0, len(succeeded))
+	for _, task := range succeeded {
+		if s.branches.cancelled(task) {
+			tasks = append(tasks, task)
+		}
+	}
+	return s.revert(cause, tasks, values...)
+}
@@ -0,0 +1,112 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestFailBranch(t *testing.T) {
+	ctx := context.Background()
+	boom := errors.New("boom")
+
+	var mu sync.Mutex
+	reverted := map[string]bool{}
+	revert := func(name string) TaskConfigFunc {
+		return WithRevertFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			reverted[name] = true
+			return nil, nil
+		})
+	}
+
+	started := make(chan struct{})
+	cancelled := make(chan error, 1)
+
+	root := New(ctx, WithName("root"), WithFunc(noop), revert("root"))
+	a := New(ctx, WithName("a"), WithFunc(noop), revert("a"))
+	slow := New(ctx, WithName("slow"), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		close(started)
+		<-ctx.Done()
+		cancelled <- context.Cause(ctx)
+		return nil, ctx.Err()
+	}))
+	fail := New(ctx, WithName("fail"), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		<-started
+		return nil, boom
+	}))
+	b := New(ctx, WithName("b"), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return "b", nil
+	}), revert("b"))
+	for _, err := range []error{root.AddSubtasks(a, b), a.AddSubtasks(slow, fail)} {
+		if err != nil {
+			t.Fatalf("didnt expect error, got %v", err)
+		}
+	}
+
+	results, err := NewRunner(WithConcurrency(4), WithFailurePolicy(FailBranch)).Run([]*Task{root})
+	if !errors.Is(err, boom) {
+		t.Fatalf("expected %v, got %v", boom, err)
+	}
+	if cerr := <-cancelled; !errors.Is(cerr, ErrSiblingFailed) {
+		t.Errorf("expected the running task of the branch to be cancelled with ErrSiblingFailed, got %v", cerr)
+	}
+	if v, err := GetAs[string](results, "b"); err != nil || v != "b" {
+		t.Errorf("expected the independent branch to complete, got %v, %v", v, err)
+	}
+	if !reverted["a"] {
+		t.Error("expected the failed branch to be reverted")
+	}
+	if reverted["root"] || reverted["b"] {
+		t.Errorf("expected only the failed branch to be reverted, got %v", reverted)
+	}
+	if b.Status() != Succeeded {
+		t.Errorf("expected status %s, got %s", Succeeded, b.Status())
+	}
+}
+
+func TestFailBranchTrunk(t *testing.T) {
+	ctx := context.Background()
+	boom := errors.New("boom")
+
+	root := New(ctx, WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, boom
+	}))
+	if err := root.AddSubtasks(New(ctx, WithFunc(noop)), New(ctx, WithFunc(noop))); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+
+	results, err := NewRunner(WithFailurePolicy(FailBranch)).Run([]*Task{root})
+	if !errors.Is(err, boom) {
+		t.Errorf("expected %v, got %v", boom, err)
+	}
+	if results != nil {
+		t.Errorf("expected no results, got %v", results)
+	}
+}
+
+func TestFailBranchSharedDependent(t *testing.T) {
+	ctx := context.Background()
+	boom := errors.New("boom")
+
+	a := New(ctx, WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, boom
+	}))
+	b := New(ctx, WithFunc(noop))
+	join := New(ctx, WithFunc(noop))
+	if err := join.DependsOn(a, b); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+
+	if _, err := NewRunner(WithFailurePolicy(FailBranch)).Run([]*Task{a, b}); !errors.Is(err, boom) {
+		t.Errorf("expected %v, got %v", boom, err)
+	}
+	if b.Status() != Succeeded {
+		t.Errorf("expected status %s, got %s", Succeeded, b.Status())
+	}
+	if join.Status() != Skipped {
+		t.Errorf("expected status %s, got %s", Skipped, join.Status())
+	}
+}
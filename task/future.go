@@ -0,0 +1,150 @@
+package task
+
+import (
+	"context"
+	"runtime/debug"
+	"sync"
+)
+
+// Future is the handle of a task graph that runs in the background. It is resolved once the run completed
+// and can be awaited by any number of goroutines. A Future returned by Submit also reports the status of the tasks of the run
+// and can cancel it.
+//
+// Example usage:
+//
+//	f := task.Submit(ctx, []*task.Task{createUser})
+//
+//	// do other work
+//
+//	results, err := f.Await(ctx)
+type Future struct {
+	done    chan struct{}
+	results *Results
+	err     error
+
+	run    *run               // the run resolving the Future, nil if it wasn't created by Submit
+	cancel context.CancelFunc // cancels the run, nil if it wasn't created by Submit
+}
+
+// ResolveFunc resolves a Future with the results and error of a run. Only the first call has an effect, it is safe for concurrent use.
+type ResolveFunc func(results *Results, err error)
+
+// NewFuture creates a Future that is not resolved yet together with the function resolving it.
+// It is meant for packages executing task graphs on their own, like the pool subpackage.
+func NewFuture() (*Future, ResolveFunc) {
+	f := &Future{done: make(chan struct{})}
+
+	var once sync.Once
+	return f, func(results *Results, err error) {
+		once.Do(func() {
+			f.results = results
+			f.err = err
+			close(f.done)
+		})
+	}
+}
+
+// Done returns a channel that is closed once the Future is resolved.
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// Await waits until the Future is resolved and returns the results and error of the run, like Run.
+// If ctx is done first, Await returns the error of CheckCancelled and the run keeps going.
+func (f *Future) Await(ctx context.Context) (*Results, error) {
+	select {
+	case <-f.done:
+		return f.results, f.err
+	case <-ctx.Done():
+		return nil, CheckCancelled(ctx)
+	}
+}
+
+// Status returns the status of every task of the run, which keeps being available after the run completed.
+// It has no tasks if the Future wasn't returned by Submit or the graph of the run wasn't built yet.
+func (f *Future) Status() RunSnapshot {
+	if f.run == nil {
+		return RunSnapshot{}
+	}
+	return f.run.snapshot()
+}
+
+// Cancel cancels the run like cancelling the context passed to Submit: no further tasks are started, the running tasks are cancelled
+// and every task that succeeded is reverted. The Future is resolved with a *CancelledError once that is done.
+// Cancel has no effect on a run that completed or a Future that wasn't returned by Submit.
+func (f *Future) Cancel() {
+	if f.cancel != nil {
+		f.cancel()
+	}
+}
+
+// AwaitAs waits until f is resolved and returns the output of the task with the given name as a T, like GetAs.
+// It returns the error of the run if the run failed.
+//
+// Example usage:
+//
+//	f := runner.Submit(ctx, []*task.Task{createUser})
+//	user, err := task.AwaitAs[User](ctx, f, "create-user")
+func AwaitAs[T any](ctx context.Context, f *Future, name string) (T, error) {
+	results, err := f.Await(ctx)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return GetAs[T](results, name)
+}
+
+// Then returns a Future that is resolved with the outcome of fn, which is called with the results and error of f once f is resolved.
+// fn runs on its own goroutine and is called for failed runs as well, so it can recover from the error or submit the next task graph.
+// If fn panics, the returned Future is resolved with a *PanicError instead of crashing the program.
+//
+// Example usage:
+//
+//	f := task.Submit(ctx, []*task.Task{createUser}).Then(func(results *task.Results, err error) (*task.Results, error) {
+//		if err != nil {
+//			return nil, err
+//		}
+//		return task.Submit(ctx, []*task.Task{sendWelcomeMail}, results.Values()...).Await(ctx)
+//	})
+func (f *Future) Then(fn func(results *Results, err error) (*Results, error)) *Future {
+	next, resolve := NewFuture()
+	go func() {
+		<-f.done
+		resolve(continuation(fn, f.results, f.err))
+	}()
+	return next
+}
+
+// continuation calls the fn passed to Then and converts a panic into a *PanicError.
+func continuation(fn func(results *Results, err error) (*Results, error), results *Results, err error) (next *Results, nextErr error) {
+	defer func() {
+		if r := recover(); r != nil {
+			next, nextErr = nil, &PanicError{
+				Value: r,
+				Stack: debug.Stack(),
+			}
+		}
+	}()
+	return fn(results, err)
+}
+
+// Submit executes the tasks in the background with the options of the Runner, like RunCtx, and returns a Future for the results.
+// The Future reports the status of the tasks of the run and can cancel it.
+func (r *Runner) Submit(ctx context.Context, tasks []*Task, values ...interface{}) *Future {
+	ctx, cancel := context.WithCancel(ctx)
+	f, resolve := NewFuture()
+	f.run = r.newRun(ctx, "", nil)
+	f.cancel = cancel
+
+	go func() {
+		defer cancel()
+		resolve(f.run.start(tasks, values...))
+	}()
+	return f
+}
+
+// Submit executes the tasks in the background like RunCtx and returns a Future for the results, so the caller can
+// start a task graph and collect its results later instead of blocking on Run.
+func Submit(ctx context.Context, tasks []*Task, values ...interface{}) *Future {
+	return NewRunner().Submit(ctx, tasks, values...)
+}
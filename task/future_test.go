@@ -0,0 +1,161 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestSubmit(t *testing.T) {
+	release := make(chan struct{})
+	task := New(context.Background(), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		<-release
+		return values[0], nil
+	}))
+
+	f := Submit(context.Background(), []*Task{task}, "foo")
+
+	select {
+	case <-f.Done():
+		t.Fatal("didnt expect future to be resolved")
+	default:
+	}
+
+	close(release)
+	results, err := f.Await(context.Background())
+	if err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if results.Values()[0] != "foo" {
+		t.Errorf("expected output %q, got %v", "foo", results.Values()[0])
+	}
+}
+
+func TestFutureAwaitCancelled(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	f := Submit(context.Background(), []*Task{New(context.Background(), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		<-release
+		return nil, nil
+	}))})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := f.Await(ctx); !errors.Is(err, ErrCancelledByCaller) {
+		t.Errorf("expected await to be cancelled, got %v", err)
+	}
+}
+
+func TestFutureThen(t *testing.T) {
+	ctx := context.Background()
+	double := func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return values[0].(int) * 2, nil
+	}
+
+	f := Submit(ctx, []*Task{New(ctx, WithFunc(double))}, 1).Then(func(results *Results, err error) (*Results, error) {
+		if err != nil {
+			return nil, err
+		}
+		return Submit(ctx, []*Task{New(ctx, WithFunc(double))}, results.Values()...).Await(ctx)
+	})
+
+	results, err := f.Await(ctx)
+	if err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if results.Values()[0] != 4 {
+		t.Errorf("expected output %d, got %v", 4, results.Values()[0])
+	}
+
+	failed := Submit(ctx, []*Task{New(ctx, WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, errors.New("foobar")
+	}))}).Then(func(results *Results, err error) (*Results, error) {
+		return nil, err
+	})
+	if _, err := failed.Await(ctx); err == nil {
+		t.Error("expected the error to be passed along the chain")
+	}
+}
+
+func TestFutureThenPanic(t *testing.T) {
+	ctx := context.Background()
+	f := Submit(ctx, []*Task{New(ctx, WithFunc(noop))}).Then(func(results *Results, err error) (*Results, error) {
+		panic("foobar")
+	})
+
+	_, err := f.Await(ctx)
+	var perr *PanicError
+	if !errors.As(err, &perr) {
+		t.Fatalf("expected a PanicError, got %v", err)
+	}
+	if perr.Value != "foobar" || len(perr.Stack) == 0 {
+		t.Errorf("unexpected panic error %+v", perr)
+	}
+	if err.Error() != "continuation panicked: foobar" {
+		t.Errorf("unexpected error message %q", err.Error())
+	}
+}
+
+func TestFutureStatusAndCancel(t *testing.T) {
+	started := make(chan struct{})
+	reverted := false
+	first := New(context.Background(), WithName("first"), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return "foo", nil
+	}), WithRevertFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		reverted = true
+		return nil, nil
+	}))
+	blocking := New(context.Background(), WithName("blocking"), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}))
+	first.AddSubtasks(blocking)
+
+	f := NewRunner().Submit(context.Background(), []*Task{first})
+	<-started
+
+	statuses := map[string]Status{}
+	for _, task := range f.Status().Tasks {
+		statuses[task.Name] = task.Status
+	}
+	if statuses["first"] != Succeeded || statuses["blocking"] != Running {
+		t.Errorf("expected first to be %s and blocking %s, got %v", Succeeded, Running, statuses)
+	}
+
+	f.Cancel()
+	if _, err := AwaitAs[string](context.Background(), f, "first"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the run to be cancelled, got %v", err)
+	}
+	if !reverted {
+		t.Error("expected the first task to be reverted")
+	}
+	for _, task := range f.Status().Tasks {
+		if task.Name == "blocking" && task.Status != Cancelled {
+			t.Errorf("expected blocking to be %s after the run, got %s", Cancelled, task.Status)
+		}
+	}
+}
+
+func TestAwaitAs(t *testing.T) {
+	task := New(context.Background(), WithName("foo"), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return 42, nil
+	}))
+
+	f := Submit(context.Background(), []*Task{task})
+	v, err := AwaitAs[int](context.Background(), f, "foo")
+	if err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if v != 42 {
+		t.Errorf("expected %d, got %d", 42, v)
+	}
+	if _, err := AwaitAs[int](context.Background(), f, "bar"); !errors.Is(err, ErrResultNotFound) {
+		t.Errorf("expected error %v, got %v", ErrResultNotFound, err)
+	}
+
+	if snapshot := (&Future{}).Status(); len(snapshot.Tasks) != 0 {
+		t.Errorf("expected no tasks for a Future not returned by Submit, got %d", len(snapshot.Tasks))
+	}
+}
@@ -0,0 +1,157 @@
+package graph
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/codecreationlabs/async/task"
+)
+
+// ContextReport describes what a task will see in its context at run time.
+//
+// Members:
+// - ID: the ID of the task
+// - Name: the name of the task
+// - Parent: the ID of the parent task found in the context of the task, empty for root tasks
+// - Context: the chain of values of the context of the task, as printed by fmt
+// - Deadline: the deadline of the context of the task, zero if it has none
+// - Tags: the tags of the task
+// - SerialKey: the serial key of the task
+// - IdempotencyKey: the idempotency key of the task
+// - Warnings: the mistakes found in the way the context of the task was derived
+type ContextReport struct {
+	ID             string
+	Name           string
+	Parent         string
+	Context        string
+	Deadline       time.Time
+	Tags           []string
+	SerialKey      string
+	IdempotencyKey string
+	Warnings       []string
+}
+
+// Contexts reports the context values and metadata every task reachable from tasks via subtasks and dependencies will see at run time,
+// in the order ToDOT renders them. It helps to reason about what a deep subtask inherits from the WithValue chain built by task.New
+// and AddSubtasks. Besides the values in the report, the Runner adds the Results of the run and a TaskContext of its own for every execution.
+//
+// Contexts warns about tasks whose context belongs to another task, names a parent the task is not a subtask of, misses values
+// added to the context of its parent after AddSubtasks, e.g. because the parent was added as a subtask afterwards, or that are
+// subtasks of several tasks but only inherit the context of one of them.
+func Contexts(tasks []*task.Task) []ContextReport {
+	nodes := walk(tasks)
+	parents := make(map[*task.Task][]*task.Task)
+	for _, t := range nodes {
+		for _, sub := range t.Subtasks {
+			parents[sub] = append(parents[sub], t)
+		}
+	}
+
+	reports := make([]ContextReport, 0, len(nodes))
+	for _, t := range nodes {
+		r := ContextReport{
+			ID:             t.ID,
+			Name:           t.Name,
+			Tags:           t.Tags,
+			SerialKey:      t.SerialKey,
+			IdempotencyKey: t.IdempotencyKey,
+		}
+		if t.Context == nil {
+			r.Warnings = append(r.Warnings, "task has no context")
+			reports = append(reports, r)
+			continue
+		}
+
+		r.Context = fmt.Sprint(t.Context)
+		r.Deadline, _ = t.Context.Deadline()
+
+		tc, err := task.DecodeCtx(t.Context)
+		switch {
+		case err != nil:
+			r.Warnings = append(r.Warnings, "context has no TaskContext")
+		case tc.Task != t:
+			r.Warnings = append(r.Warnings, fmt.Sprintf("context belongs to task %s", taskID(tc.Task)))
+		}
+
+		var parent *task.Task
+		if tc != nil {
+			parent = tc.Parent
+		}
+		if parent != nil {
+			r.Parent = parent.ID
+		}
+		if len(parents[t]) > 1 {
+			r.Warnings = append(r.Warnings, fmt.Sprintf("task is a subtask of %d tasks, its context only inherits from its last parent", len(parents[t])))
+		}
+
+		switch {
+		case parent == nil && len(parents[t]) > 0:
+			r.Warnings = append(r.Warnings, fmt.Sprintf("task is a subtask of task %s, but its context has no parent", parents[t][0].ID))
+		case parent != nil && !contains(parents[t], parent):
+			r.Warnings = append(r.Warnings, fmt.Sprintf("context names task %s as parent, but task is not a subtask of it", parent.ID))
+		case parent != nil && parent.Context != nil && !strings.HasPrefix(r.Context, fmt.Sprint(parent.Context)):
+			r.Warnings = append(r.Warnings, fmt.Sprintf("context misses values added to the context of parent %s after the task was added as a subtask", parent.ID))
+		}
+
+		reports = append(reports, r)
+	}
+	return reports
+}
+
+// PrintContexts writes the reports of Contexts for the tasks to w in a human readable form.
+//
+// Example usage:
+//
+//	graph.PrintContexts(os.Stderr, []*task.Task{createUser})
+func PrintContexts(w io.Writer, tasks []*task.Task) error {
+	var b strings.Builder
+	for _, r := range Contexts(tasks) {
+		b.WriteString(r.ID)
+		if r.Name != "" {
+			fmt.Fprintf(&b, " %q", r.Name)
+		}
+		b.WriteString("\n")
+		if r.Parent != "" {
+			fmt.Fprintf(&b, "\tparent: %s\n", r.Parent)
+		}
+		fmt.Fprintf(&b, "\tcontext: %s\n", r.Context)
+		if !r.Deadline.IsZero() {
+			fmt.Fprintf(&b, "\tdeadline: %s\n", r.Deadline.Format(time.RFC3339))
+		}
+		if len(r.Tags) > 0 {
+			fmt.Fprintf(&b, "\ttags: %s\n", strings.Join(r.Tags, ", "))
+		}
+		if r.SerialKey != "" {
+			fmt.Fprintf(&b, "\tserial key: %s\n", r.SerialKey)
+		}
+		if r.IdempotencyKey != "" {
+			fmt.Fprintf(&b, "\tidempotency key: %s\n", r.IdempotencyKey)
+		}
+		for _, warning := range r.Warnings {
+			fmt.Fprintf(&b, "\twarning: %s\n", warning)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// taskID returns the ID of t, or "<nil>" if t is nil.
+func taskID(t *task.Task) string {
+	if t == nil {
+		return "<nil>"
+	}
+	return t.ID
+}
+
+// contains reports whether tasks contains t.
+func contains(tasks []*task.Task, t *task.Task) bool {
+	for _, other := range tasks {
+		if other == t {
+			return true
+		}
+	}
+	return false
+}
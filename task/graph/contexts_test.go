@@ -0,0 +1,92 @@
+package graph
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/codecreationlabs/async/task"
+)
+
+type tenantKey struct{}
+
+func TestContexts(t *testing.T) {
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	root := task.New(ctx, task.WithName("root"), task.WithTags("billing"))
+	child := task.New(ctx, task.WithName("child"))
+	grandchild := task.New(ctx, task.WithName("grandchild"))
+	root.AddSubtasks(child)
+	child.AddSubtasks(grandchild)
+
+	reports := Contexts([]*task.Task{root})
+	if len(reports) != 3 {
+		t.Fatalf("expected %d reports, got %d", 3, len(reports))
+	}
+	for _, r := range reports {
+		if len(r.Warnings) > 0 {
+			t.Errorf("didnt expect warnings for task %s, got %v", r.Name, r.Warnings)
+		}
+	}
+
+	r := reports[2]
+	if r.ID != grandchild.ID || r.Parent != child.ID {
+		t.Errorf("expected report of task %s with parent %s, got %s with parent %s", grandchild.ID, child.ID, r.ID, r.Parent)
+	}
+	expected := fmt.Sprintf("TaskContext(%s, parent %s)", grandchild.ID, child.ID)
+	if !strings.HasSuffix(r.Context, expected+")") {
+		t.Errorf("expected context to end with %s, got %s", expected, r.Context)
+	}
+	if !strings.HasPrefix(r.Context, fmt.Sprint(root.Context)) {
+		t.Errorf("expected context to inherit from the root context %s, got %s", root.Context, r.Context)
+	}
+}
+
+func TestContextsWarnings(t *testing.T) {
+	ctx := context.Background()
+	root := task.New(ctx, task.WithName("root"))
+	child := task.New(ctx, task.WithName("child"))
+	other := task.New(ctx, task.WithName("other"))
+	shared := task.New(ctx, task.WithName("shared"))
+	root.AddSubtasks(child, other)
+	child.AddSubtasks(shared)
+	other.AddSubtasks(shared)
+
+	// adopted keeps the context created by AddSubtasks of a task that is not part of the graph
+	adopted := task.New(ctx, task.WithName("adopted"))
+	task.New(ctx).AddSubtasks(adopted)
+	root.Subtasks = append(root.Subtasks, adopted)
+
+	// stale is added before its parent becomes a subtask of root, so its context misses the values added for its parent
+	parent := task.New(ctx, task.WithName("parent"))
+	stale := task.New(ctx, task.WithName("stale"))
+	parent.AddSubtasks(stale)
+	root.AddSubtasks(parent)
+
+	expected := map[string]string{
+		"shared":  "task is a subtask of 2 tasks, its context only inherits from its last parent",
+		"adopted": "but task is not a subtask of it",
+		"stale":   fmt.Sprintf("context misses values added to the context of parent %s", parent.ID),
+	}
+	for _, r := range Contexts([]*task.Task{root}) {
+		want, ok := expected[r.Name]
+		if !ok {
+			if len(r.Warnings) > 0 {
+				t.Errorf("didnt expect warnings for task %s, got %v", r.Name, r.Warnings)
+			}
+			continue
+		}
+		if len(r.Warnings) != 1 || !strings.Contains(r.Warnings[0], want) {
+			t.Errorf("expected warning %q for task %s, got %v", want, r.Name, r.Warnings)
+		}
+	}
+
+	var b bytes.Buffer
+	if err := PrintContexts(&b, []*task.Task{root}); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if !strings.Contains(b.String(), "\twarning: "+expected["stale"]) {
+		t.Errorf("expected the warning of task stale in\n%s", b.String())
+	}
+}
@@ -0,0 +1,117 @@
+package graph
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/codecreationlabs/async/task"
+)
+
+// RenderOptions holds the settings of a rendered task graph.
+//
+// Members:
+// - Statuses: whether nodes are colored by the status of their task in its latest run, see task.Task.Status
+type RenderOptions struct {
+	Statuses bool
+}
+
+// RenderConfigFunc represents a function that can be used to configure the rendering of a task graph.
+type RenderConfigFunc func(o *RenderOptions)
+
+// WithStatuses returns a RenderConfigFunc that colors every node by the status of its task, so a rendered graph shows
+// how far a run got and where it failed.
+func WithStatuses() RenderConfigFunc {
+	return func(o *RenderOptions) {
+		o.Statuses = true
+	}
+}
+
+// dotColors are the fill colors of the nodes by status.
+var dotColors = map[task.Status]string{
+	task.Pending:   "white",
+	task.Running:   "lightblue",
+	task.Succeeded: "palegreen",
+	task.Failed:    "salmon",
+	task.Reverted:  "orange",
+	task.Skipped:   "lightgray",
+	task.Cancelled: "khaki",
+}
+
+// ToDOT renders all tasks reachable from tasks via subtasks and dependencies in the DOT language of Graphviz.
+// Every node is labeled with the name and ID of its task. Solid edges lead from a task to its subtasks,
+// dashed edges from a dependency to the task consuming its output. Unlike FromTasks, ToDOT also renders graphs with cycles,
+// so dependency mistakes can be inspected.
+//
+// Example usage:
+//
+//	os.WriteFile("graph.dot", []byte(graph.ToDOT([]*task.Task{createUser}, graph.WithStatuses())), 0o644)
+//	// dot -Tsvg graph.dot -o graph.svg
+func ToDOT(tasks []*task.Task, cfgs ...RenderConfigFunc) string {
+	var o RenderOptions
+	for _, cfg := range cfgs {
+		cfg(&o)
+	}
+
+	var b strings.Builder
+	b.WriteString("digraph tasks {\n")
+	b.WriteString("\tnode [shape=box];\n")
+
+	nodes := walk(tasks)
+	for _, t := range nodes {
+		label := dotEscape(t.ID)
+		if t.Name != "" {
+			label = dotEscape(t.Name) + `\n` + label
+		}
+		fmt.Fprintf(&b, "\t%s [label=\"%s\"", dotQuote(t.ID), label)
+		if o.Statuses {
+			fmt.Fprintf(&b, ", style=filled, fillcolor=%s, tooltip=%s", dotColors[t.Status()], dotQuote(t.Status().String()))
+		}
+		b.WriteString("];\n")
+	}
+	for _, t := range nodes {
+		for _, sub := range t.Subtasks {
+			fmt.Fprintf(&b, "\t%s -> %s;\n", dotQuote(t.ID), dotQuote(sub.ID))
+		}
+		for _, dep := range t.Dependencies {
+			fmt.Fprintf(&b, "\t%s -> %s [style=dashed];\n", dotQuote(dep.ID), dotQuote(t.ID))
+		}
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// walk returns all tasks reachable from tasks via subtasks and dependencies in the order they are discovered, visiting every task once.
+func walk(tasks []*task.Task) []*task.Task {
+	seen := make(map[*task.Task]bool)
+	var nodes []*task.Task
+
+	var visit func(t *task.Task)
+	visit = func(t *task.Task) {
+		if t == nil || seen[t] {
+			return
+		}
+		seen[t] = true
+		nodes = append(nodes, t)
+		for _, dep := range t.Dependencies {
+			visit(dep)
+		}
+		for _, sub := range t.Subtasks {
+			visit(sub)
+		}
+	}
+	for _, t := range tasks {
+		visit(t)
+	}
+	return nodes
+}
+
+// dotQuote returns s as a quoted DOT string.
+func dotQuote(s string) string {
+	return `"` + dotEscape(s) + `"`
+}
+
+// dotEscape escapes the backslashes and double quotes of s, so it can be used within a quoted DOT string.
+func dotEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+}
@@ -0,0 +1,68 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/codecreationlabs/async/task"
+)
+
+func TestToDOT(t *testing.T) {
+	ctx := context.Background()
+	foo := task.New(ctx, task.WithName(`say "hi"`))
+	bar := task.New(ctx)
+	baz := task.New(ctx, task.WithName("baz"))
+	foo.AddSubtasks(bar)
+	baz.DependsOn(foo, bar)
+
+	expected := fmt.Sprintf(`digraph tasks {
+	node [shape=box];
+	"%[1]s" [label="say \"hi\"\n%[1]s"];
+	"%[2]s" [label="%[2]s"];
+	"%[3]s" [label="baz\n%[3]s"];
+	"%[1]s" -> "%[2]s";
+	"%[1]s" -> "%[3]s" [style=dashed];
+	"%[2]s" -> "%[3]s" [style=dashed];
+}
+`, foo.ID, bar.ID, baz.ID)
+
+	if got := ToDOT([]*task.Task{foo, baz}); got != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, got)
+	}
+}
+
+func TestToDOTCycle(t *testing.T) {
+	ctx := context.Background()
+	foo := task.New(ctx, task.WithName("foo"))
+	bar := task.New(ctx, task.WithName("bar"))
+	foo.DependsOn(bar)
+	bar.DependsOn(foo)
+
+	dot := ToDOT([]*task.Task{foo})
+	for _, edge := range []string{
+		fmt.Sprintf(`"%s" -> "%s" [style=dashed];`, bar.ID, foo.ID),
+		fmt.Sprintf(`"%s" -> "%s" [style=dashed];`, foo.ID, bar.ID),
+	} {
+		if !strings.Contains(dot, edge) {
+			t.Errorf("expected edge %s in\n%s", edge, dot)
+		}
+	}
+}
+
+func TestToDOTStatuses(t *testing.T) {
+	ctx := context.Background()
+	foo := task.New(ctx, task.WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, nil
+	}))
+
+	if _, err := task.Run([]*task.Task{foo}); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+
+	expected := fmt.Sprintf(`"%[1]s" [label="%[1]s", style=filled, fillcolor=palegreen, tooltip="succeeded"];`, foo.ID)
+	if dot := ToDOT([]*task.Task{foo}, WithStatuses()); !strings.Contains(dot, expected) {
+		t.Errorf("expected node %s in\n%s", expected, dot)
+	}
+}
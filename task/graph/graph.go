@@ -0,0 +1,98 @@
+// Package graph provides a flat, serializable representation of task graphs, so graphs can be persisted, inspected or shipped to another process.
+package graph
+
+import (
+	"encoding/json"
+
+	"github.com/codecreationlabs/async/task"
+)
+
+// Node represents a single task of a Graph. Edges refer to other nodes by task ID.
+//
+// Members:
+// - ID: the ID of the task
+// - Name: the name of the task
+// - Status: the status of the task in its latest run, see task.Task.Status
+// - Parameters: the parameters of the task, encoded with task.JSONCodec
+// - Subtasks: the IDs of the subtasks of the task
+// - Dependencies: the IDs of the tasks whose outputs the task consumes
+// - Tags: the tags of the task
+// - SerialKey: the serial key of the task
+// - Revertible: whether the task has a Revert function
+type Node struct {
+	ID           string                   `json:"id"`
+	Name         string                   `json:"name,omitempty"`
+	Status       string                   `json:"status"`
+	Parameters   []task.ParameterEnvelope `json:"parameters,omitempty"`
+	Subtasks     []string                 `json:"subtasks,omitempty"`
+	Dependencies []string                 `json:"dependencies,omitempty"`
+	Tags         []string                 `json:"tags,omitempty"`
+	SerialKey    string                   `json:"serial_key,omitempty"`
+	Revertible   bool                     `json:"revertible,omitempty"`
+}
+
+// Graph is a flat representation of a task graph. Nodes are in topological order, see task.Sort.
+type Graph struct {
+	Nodes []Node `json:"nodes"`
+}
+
+// FromTasks creates the Graph of all tasks reachable from the roots via subtasks and dependencies.
+// It fails like task.Run for graphs with cycles or duplicate names and for parameters of types not registered with task.RegisterParameter.
+func FromTasks(roots ...*task.Task) (*Graph, error) {
+	tasks, err := task.Sort(roots)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &Graph{
+		Nodes: make([]Node, 0, len(tasks)),
+	}
+	for _, t := range tasks {
+		params, err := task.EncodeParameters(task.JSONCodec, t.Parameters...)
+		if err != nil {
+			return nil, err
+		}
+
+		g.Nodes = append(g.Nodes, Node{
+			ID:           t.ID,
+			Name:         t.Name,
+			Status:       t.Status().String(),
+			Parameters:   params,
+			Subtasks:     ids(t.Subtasks),
+			Dependencies: ids(t.Dependencies),
+			Tags:         t.Tags,
+			SerialKey:    t.SerialKey,
+			Revertible:   t.Revert != nil,
+		})
+	}
+	return g, nil
+}
+
+// Export serializes the graph of all tasks reachable from the roots to JSON.
+//
+// Example usage:
+//
+//	data, err := graph.Export(createUser)
+//	if err != nil {
+//		return err
+//	}
+//	os.WriteFile("graph.json", data, 0o644)
+func Export(roots ...*task.Task) ([]byte, error) {
+	g, err := FromTasks(roots...)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(g)
+}
+
+// ids returns the IDs of the tasks.
+func ids(tasks []*task.Task) []string {
+	if len(tasks) == 0 {
+		return nil
+	}
+	ids := make([]string, 0, len(tasks))
+	for _, t := range tasks {
+		ids = append(ids, t.ID)
+	}
+	return ids
+}
@@ -0,0 +1,86 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/codecreationlabs/async/task"
+)
+
+func TestExport(t *testing.T) {
+	ctx := context.Background()
+	noop := func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, nil
+	}
+
+	foo := task.New(ctx, task.WithName("foo"), task.WithParameters("alice", 42), task.WithRevertFunc(noop))
+	bar := task.New(ctx, task.WithName("bar"), task.WithTags("mail"))
+	baz := task.New(ctx, task.WithName("baz"), task.WithSerialKey("account-1"))
+	foo.AddSubtasks(bar)
+	baz.DependsOn(foo, bar)
+
+	data, err := Export(foo)
+	if err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+
+	var g Graph
+	if err := json.Unmarshal(data, &g); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+
+	if len(g.Nodes) != 3 {
+		t.Fatalf("expected %d nodes, got %d", 3, len(g.Nodes))
+	}
+
+	expected := []Node{
+		{ID: foo.ID, Name: "foo", Status: "pending", Subtasks: []string{bar.ID}, Revertible: true},
+		{ID: bar.ID, Name: "bar", Status: "pending", Tags: []string{"mail"}},
+		{ID: baz.ID, Name: "baz", Status: "pending", Dependencies: []string{foo.ID, bar.ID}, SerialKey: "account-1"},
+	}
+	for i := range expected {
+		got := g.Nodes[i]
+		got.Parameters = nil
+		if !reflect.DeepEqual(got, expected[i]) {
+			t.Errorf("expected node %+v, got %+v", expected[i], got)
+		}
+	}
+
+	params, err := task.DecodeParameters(g.Nodes[0].Parameters...)
+	if err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if !reflect.DeepEqual(params, []interface{}{"alice", 42}) {
+		t.Errorf("expected parameters %v, got %v", foo.Parameters, params)
+	}
+}
+
+func TestExportCycle(t *testing.T) {
+	a := task.New(context.Background())
+	b := task.New(context.Background())
+	a.DependsOn(b)
+	b.DependsOn(a)
+
+	if _, err := Export(a); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestExportStatus(t *testing.T) {
+	foo := task.New(context.Background(), task.WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, nil
+	}))
+	if _, err := task.Run([]*task.Task{foo}); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+
+	g, err := FromTasks(foo)
+	if err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if g.Nodes[0].Status != "succeeded" {
+		t.Errorf("expected status %q, got %q", "succeeded", g.Nodes[0].Status)
+	}
+}
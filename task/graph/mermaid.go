@@ -0,0 +1,77 @@
+package graph
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/codecreationlabs/async/task"
+)
+
+// mermaidColors are the fill colors of the nodes by status.
+var mermaidColors = map[task.Status]string{
+	task.Pending:   "#ffffff",
+	task.Running:   "#add8e6",
+	task.Succeeded: "#98fb98",
+	task.Failed:    "#fa8072",
+	task.Reverted:  "#ffa500",
+	task.Skipped:   "#d3d3d3",
+	task.Cancelled: "#f0e68c",
+}
+
+// ToMermaid renders all tasks reachable from tasks via subtasks and dependencies as a Mermaid flowchart, which can be embedded
+// in Markdown documents and GitHub issues. Nodes and edges are rendered like ToDOT: solid arrows lead from a task to its subtasks,
+// dotted arrows from a dependency to the task consuming its output.
+//
+// Example usage:
+//
+//	fmt.Printf("```mermaid\n%s```\n", graph.ToMermaid([]*task.Task{createUser}, graph.WithStatuses()))
+func ToMermaid(tasks []*task.Task, cfgs ...RenderConfigFunc) string {
+	var o RenderOptions
+	for _, cfg := range cfgs {
+		cfg(&o)
+	}
+
+	var b strings.Builder
+	b.WriteString("flowchart TD\n")
+
+	// task IDs may contain characters Mermaid doesn't allow in node IDs, so nodes are numbered
+	nodes := walk(tasks)
+	ids := make(map[*task.Task]string, len(nodes))
+	for i, t := range nodes {
+		ids[t] = fmt.Sprintf("n%d", i)
+	}
+
+	for _, t := range nodes {
+		label := mermaidEscape(t.ID)
+		if t.Name != "" {
+			label = mermaidEscape(t.Name) + "<br/>" + label
+		}
+		fmt.Fprintf(&b, "\t%s[\"%s\"]\n", ids[t], label)
+	}
+	for _, t := range nodes {
+		for _, sub := range t.Subtasks {
+			fmt.Fprintf(&b, "\t%s --> %s\n", ids[t], ids[sub])
+		}
+		for _, dep := range t.Dependencies {
+			fmt.Fprintf(&b, "\t%s -.-> %s\n", ids[dep], ids[t])
+		}
+	}
+
+	if o.Statuses {
+		used := make(map[task.Status]bool)
+		for _, t := range nodes {
+			status := t.Status()
+			if !used[status] {
+				used[status] = true
+				fmt.Fprintf(&b, "\tclassDef %s fill:%s\n", status, mermaidColors[status])
+			}
+			fmt.Fprintf(&b, "\tclass %s %s\n", ids[t], status)
+		}
+	}
+	return b.String()
+}
+
+// mermaidEscape replaces the characters of s that end or format a quoted Mermaid label with entity codes.
+func mermaidEscape(s string) string {
+	return strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;").Replace(s)
+}
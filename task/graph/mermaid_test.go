@@ -0,0 +1,58 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/codecreationlabs/async/task"
+)
+
+func TestToMermaid(t *testing.T) {
+	ctx := context.Background()
+	foo := task.New(ctx, task.WithName(`say "hi"`))
+	bar := task.New(ctx)
+	baz := task.New(ctx, task.WithName("baz"))
+	foo.AddSubtasks(bar)
+	baz.DependsOn(foo, bar)
+
+	expected := fmt.Sprintf(`flowchart TD
+	n0["say #quot;hi#quot;<br/>%s"]
+	n1["%s"]
+	n2["baz<br/>%s"]
+	n0 --> n1
+	n0 -.-> n2
+	n1 -.-> n2
+`, foo.ID, bar.ID, baz.ID)
+
+	if got := ToMermaid([]*task.Task{foo, baz}); got != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, got)
+	}
+}
+
+func TestToMermaidStatuses(t *testing.T) {
+	ctx := context.Background()
+	noop := func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, nil
+	}
+	foo := task.New(ctx, task.WithFunc(noop))
+	bar := task.New(ctx, task.WithFunc(noop))
+	foo.AddSubtasks(bar)
+
+	if _, err := task.Run([]*task.Task{foo}); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+
+	expected := fmt.Sprintf(`flowchart TD
+	n0["%s"]
+	n1["%s"]
+	n0 --> n1
+	classDef succeeded fill:#98fb98
+	class n0 succeeded
+	class n1 succeeded
+`, foo.ID, bar.ID)
+
+	if got := ToMermaid([]*task.Task{foo}, WithStatuses()); got != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, got)
+	}
+}
@@ -0,0 +1,94 @@
+package task
+
+import (
+	"context"
+	"sync"
+)
+
+// Group runs functions as tasks of a Runner on goroutines of their own, like golang.org/x/sync/errgroup.Group, so code using errgroup
+// gains the retries, hooks and reverts of the package with minimal changes. The first function that fails cancels the context of the group,
+// and Wait reverts the functions that succeeded. A Group must be created with Runner.Group and must not be reused after Wait returned.
+//
+// Example usage:
+//
+//	g, ctx := runner.Group(ctx)
+//	g.Go(func() error {
+//		return reserveStock(ctx, order)
+//	}, task.WithRevertFunc(releaseStock))
+//	g.Go(func() error {
+//		return chargeCard(ctx, order)
+//	}, task.WithRevertFunc(refundCard), task.WithRetry(3, nil))
+//	if err := g.Wait(); err != nil {
+//		return err
+//	}
+type Group struct {
+	runner *Runner
+	parent context.Context // the context tasks are created with, so reverts are not cancelled together with the group
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	wg     sync.WaitGroup
+
+	mu        sync.Mutex
+	err       error
+	succeeded []*Task // in completion order
+}
+
+// Group creates a new Group running functions with the options of the Runner, together with a context derived from ctx
+// that is cancelled when the first function fails or Wait returns, like errgroup.WithContext.
+func (r *Runner) Group(ctx context.Context) (*Group, context.Context) {
+	groupCtx, cancel := context.WithCancelCause(ctx)
+	return &Group{
+		runner: r,
+		parent: ctx,
+		ctx:    groupCtx,
+		cancel: cancel,
+	}, groupCtx
+}
+
+// Go runs f as a task on a goroutine of its own. The cfgs configure the task, e.g. its Revert function, name or retry policy.
+// The Revert function is called without values if the function succeeded and another function of the group failed.
+func (g *Group) Go(f func() error, cfgs ...TaskConfigFunc) {
+	run := func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, f()
+	}
+	t := New(g.parent, append(cfgs, WithFunc(run))...)
+
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+
+		_, err := g.runner.RunCtx(g.ctx, []*Task{t})
+
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		if err == nil {
+			g.succeeded = append(g.succeeded, t)
+			return
+		}
+		if g.err == nil {
+			g.err = err
+			g.cancel(err)
+		}
+	}()
+}
+
+// Wait blocks until all functions of the group returned, then returns the first error, if any, like errgroup.Group.Wait.
+// If a function failed, the functions that succeeded are reverted in reverse completion order first, and an error
+// of a Revert function is reported in a *RevertError wrapping the first error.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	defer g.cancel(nil)
+
+	if g.err == nil {
+		return nil
+	}
+
+	tasks := make([]*Task, 0, len(g.succeeded))
+	for i := len(g.succeeded) - 1; i >= 0; i-- {
+		tasks = append(tasks, g.succeeded[i])
+	}
+
+	s := g.runner.newRun(g.parent, "", nil)
+	defer s.cancel(nil)
+	return s.revert(g.err, tasks)
+}
@@ -0,0 +1,76 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestGroup(t *testing.T) {
+	g, ctx := NewRunner().Group(context.Background())
+
+	var mu sync.Mutex
+	var done []int
+	for i := 0; i < 3; i++ {
+		i := i
+		g.Go(func() error {
+			mu.Lock()
+			defer mu.Unlock()
+			done = append(done, i)
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if len(done) != 3 {
+		t.Errorf("expected %d functions to run, got %d", 3, len(done))
+	}
+	if ctx.Err() == nil {
+		t.Error("expected the context to be cancelled after Wait")
+	}
+}
+
+func TestGroupFailure(t *testing.T) {
+	g, ctx := NewRunner().Group(context.Background())
+
+	reverted := make(chan error, 1)
+	succeeded := make(chan struct{})
+	g.Go(func() error {
+		close(succeeded)
+		return nil
+	}, WithRevertFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		reverted <- ctx.Err()
+		return nil, nil
+	}))
+
+	<-succeeded
+	attempts := 0
+	g.Go(func() error {
+		attempts++
+		return errors.New("foobar")
+	}, WithRetry(2, nil))
+	g.Go(func() error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	err := g.Wait()
+	if err == nil || err.Error() != "foobar" {
+		t.Fatalf("expected error %q, got %v", "foobar", err)
+	}
+	if attempts != 2 {
+		t.Errorf("expected %d attempts, got %d", 2, attempts)
+	}
+
+	select {
+	case err := <-reverted:
+		if err != nil {
+			t.Errorf("didnt expect the revert to be cancelled, got %v", err)
+		}
+	default:
+		t.Error("expected the function that succeeded to be reverted")
+	}
+}
@@ -0,0 +1,102 @@
+package task
+
+import (
+	"crypto/rand"
+	"fmt"
+	"sync/atomic"
+)
+
+// IDGenerator represents a function returning a new, unique task ID on every call. It must be safe for concurrent use.
+type IDGenerator func() string
+
+// idGenerator holds the IDGenerator used by New, nil means CounterID.
+var idGenerator atomic.Value
+
+// CounterID is the default IDGenerator. It returns IDs of the form "task_<n>", where n counts the tasks created by the process.
+// The IDs are unique within a process only and repeat after a restart.
+func CounterID() string {
+	return fmt.Sprintf("task_%d", counter.Add(1)-1)
+}
+
+// Sequence generates IDs of the form "<prefix>_<n>", where n counts the IDs returned by the Sequence, starting at zero.
+// Unlike CounterID, which counts all tasks of the process, a Sequence counts within a scope of its own, e.g. a single graph or test,
+// so IDs stay small and predictable. A Sequence is safe for concurrent use.
+//
+// Task IDs are assigned by New, before the task is part of any run, and are used to key Results, checkpoints and idempotency keys,
+// so they are not derived from the ID of the run executing the task. To get IDs scoped to a unit of work, create a Sequence for it.
+//
+// Example usage:
+//
+//	seq := task.NewSequence("order_42")
+//	charge := task.New(ctx, task.WithIDGenerator(seq.Next), task.WithFunc(chargeCard)) // order_42_0
+//	ship := task.New(ctx, task.WithIDGenerator(seq.Next), task.WithFunc(shipOrder))    // order_42_1
+type Sequence struct {
+	prefix string
+	n      atomic.Int64
+}
+
+// NewSequence creates a new Sequence generating IDs starting with prefix.
+func NewSequence(prefix string) *Sequence {
+	return &Sequence{prefix: prefix}
+}
+
+// Next returns the next ID of the sequence. It is an IDGenerator.
+func (s *Sequence) Next() string {
+	return fmt.Sprintf("%s_%d", s.prefix, s.n.Add(1)-1)
+}
+
+// Reset restarts the sequence at zero. IDs returned before are returned again, so it is only safe once they are no longer in use.
+func (s *Sequence) Reset() {
+	s.n.Store(0)
+}
+
+// UUID is an IDGenerator returning random (version 4) UUIDs, which are unique across processes and restarts.
+func UUID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("task: generate uuid: %v", err))
+	}
+	b[6] = b[6]&0x0f | 0x40 // version 4
+	b[8] = b[8]&0x3f | 0x80 // variant 10
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// SetIDGenerator makes New use gen to assign IDs to all tasks created afterwards. A nil gen restores CounterID.
+// It is meant to be called once during program initialization, e.g. to use UUIDs in every process of a distributed deployment.
+//
+// Example usage:
+//
+//	func main() {
+//		task.SetIDGenerator(task.UUID)
+//		...
+//	}
+func SetIDGenerator(gen IDGenerator) {
+	if gen == nil {
+		gen = CounterID
+	}
+	idGenerator.Store(gen)
+}
+
+// nextID returns the ID of a new task.
+func nextID() string {
+	if gen, ok := idGenerator.Load().(IDGenerator); ok {
+		return gen()
+	}
+	return CounterID()
+}
+
+// WithIDGenerator returns a TaskConfigFunc that replaces the ID of the task with one returned by gen.
+func WithIDGenerator(gen IDGenerator) TaskConfigFunc {
+	return func(t *Task) {
+		t.ID = gen()
+	}
+}
+
+// WithID returns a TaskConfigFunc that sets the ID of the task to id, e.g. for deterministic graphs that are persisted
+// or executed by another process. The caller is responsible for the uniqueness of the ID.
+func WithID(id string) TaskConfigFunc {
+	return func(t *Task) {
+		t.ID = id
+	}
+}
@@ -0,0 +1,99 @@
+package task
+
+import (
+	"context"
+	"regexp"
+	"testing"
+)
+
+func TestUUID(t *testing.T) {
+	pattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+	seen := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		id := UUID()
+		if !pattern.MatchString(id) {
+			t.Fatalf("expected a version 4 uuid, got %s", id)
+		}
+		if seen[id] {
+			t.Fatalf("expected unique ids, got %s twice", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestSetIDGenerator(t *testing.T) {
+	defer SetIDGenerator(nil)
+
+	SetIDGenerator(func() string {
+		return "fixed"
+	})
+	if id := New(context.Background()).ID; id != "fixed" {
+		t.Errorf("expected id %q, got %q", "fixed", id)
+	}
+
+	SetIDGenerator(nil)
+	if id := New(context.Background()).ID; !regexp.MustCompile(`^task_\d+$`).MatchString(id) {
+		t.Errorf("expected a counter id, got %q", id)
+	}
+}
+
+func TestWithID(t *testing.T) {
+	if id := New(context.Background(), WithID("create-user")).ID; id != "create-user" {
+		t.Errorf("expected id %q, got %q", "create-user", id)
+	}
+
+	n := 0
+	gen := func() string {
+		n++
+		return "gen"
+	}
+	if id := New(context.Background(), WithIDGenerator(gen)).ID; id != "gen" || n != 1 {
+		t.Errorf("expected id %q from the generator, got %q", "gen", id)
+	}
+}
+
+func TestSequence(t *testing.T) {
+	seq := NewSequence("order_42")
+	ctx := context.Background()
+
+	charge := New(ctx, WithIDGenerator(seq.Next))
+	ship := New(ctx, WithIDGenerator(seq.Next))
+	if charge.ID != "order_42_0" || ship.ID != "order_42_1" {
+		t.Errorf("expected IDs order_42_0 and order_42_1, got %s and %s", charge.ID, ship.ID)
+	}
+
+	seq.Reset()
+	if id := seq.Next(); id != "order_42_0" {
+		t.Errorf("expected order_42_0 after reset, got %s", id)
+	}
+}
+
+func TestRunID(t *testing.T) {
+	runner := NewRunner()
+	events := make(chan Event, 8)
+	defer runner.Subscribe(events)()
+
+	for _, expected := range []string{"run_0", "run_1"} {
+		task := New(context.Background(), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+			return nil, nil
+		}))
+		results, err := runner.Run([]*Task{task})
+		if err != nil {
+			t.Fatalf("didnt expect error, got %v", err)
+		}
+		if results.RunID() != expected {
+			t.Errorf("expected run ID %s, got %s", expected, results.RunID())
+		}
+	}
+
+	for len(events) > 0 {
+		if e := <-events; e.RunID == "" {
+			t.Errorf("expected the %s event to carry the run ID", e.Type)
+		}
+	}
+
+	if results, err := (&Runner{}).Run(nil); err != nil || results.RunID() != "" {
+		t.Errorf("expected no run ID for a Runner not created with NewRunner, got %q (%v)", results.RunID(), err)
+	}
+}
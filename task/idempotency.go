@@ -0,0 +1,159 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+// IdempotencyReader is the read path of an IdempotencyStore.
+type IdempotencyReader interface {
+	// Get returns the output recorded for key. The second return value is false if there is none.
+	Get(ctx context.Context, key string) (ParameterEnvelope, bool, error)
+}
+
+// IdempotencyWriter is the write path of an IdempotencyStore.
+type IdempotencyWriter interface {
+	Put(ctx context.Context, key string, output ParameterEnvelope) error
+	Delete(ctx context.Context, key string) error
+}
+
+// IdempotencyStore keeps the outputs of tasks with an idempotency key, so a task that already ran is not executed again.
+// Implementations must be safe for concurrent use. See SplitIdempotencyStore for sending reads and writes to different backends.
+type IdempotencyStore interface {
+	IdempotencyReader
+	IdempotencyWriter
+}
+
+// SplitIdempotencyStore returns an IdempotencyStore recording outputs with w and looking them up with r, e.g. a primary database and one of its replicas.
+//
+// A replica lagging behind the primary doesn't know outputs recorded shortly before, so a task whose output was recorded
+// within the replication lag executes again. Only use replicas for r that are synchronously replicated if that is not acceptable.
+func SplitIdempotencyStore(w IdempotencyWriter, r IdempotencyReader) IdempotencyStore {
+	return struct {
+		IdempotencyReader
+		IdempotencyWriter
+	}{r, w}
+}
+
+// WithIdempotencyKey returns a TaskConfigFunc that identifies the side effects of the task with key, e.g. "charge-order-1234".
+// When the Runner has an IdempotencyStore, a task whose key already has a recorded output is not executed again and returns the recorded output instead,
+// which makes retrying whole task graphs safe for payment or user creation workflows. Without an IdempotencyStore, the key has no effect.
+//
+// Example usage:
+//
+//	charge := task.New(ctx, task.WithFunc(chargeCard), task.WithIdempotencyKey("charge-"+order.ID))
+func WithIdempotencyKey(key string) TaskConfigFunc {
+	return func(t *Task) {
+		t.IdempotencyKey = key
+	}
+}
+
+// WithIdempotencyStore returns a RunnerConfigFunc that records the output of every task with an idempotency key in store
+// and reuses recorded outputs instead of executing such tasks again.
+//
+// Outputs are encoded with JSONCodec and their types have to be registered with RegisterParameter. Failing to record an output fails the run.
+// A task with a reused output counts as completed in the run, so it is reverted if the run fails. Reverting a task successfully
+// deletes its recorded output, so the task runs again the next time. Tasks sharing a key that run at the same time are not
+// detected, use WithSerialKey with the same key to prevent that.
+func WithIdempotencyStore(store IdempotencyStore) RunnerConfigFunc {
+	return func(o *RunOptions) {
+		o.IdempotencyStore = store
+	}
+}
+
+// recorded returns the output recorded for the idempotency key of the task, if the Runner has an IdempotencyStore.
+func (s *run) recorded(ctx context.Context, task *Task) (interface{}, bool, error) {
+	store := s.runner.Options.IdempotencyStore
+	if store == nil || task.IdempotencyKey == "" {
+		return nil, false, nil
+	}
+
+	var env ParameterEnvelope
+	var ok bool
+	err := s.runner.storeCall(ctx, func(ctx context.Context) (err error) {
+		env, ok, err = store.Get(ctx, task.IdempotencyKey)
+		return err
+	})
+	if err != nil || !ok {
+		return nil, false, err
+	}
+
+	outputs, err := DecodeParameters(env)
+	if err != nil {
+		return nil, false, err
+	}
+
+	s.log(ctx, slog.LevelInfo, "task output reused", task, slog.String("idempotency_key", task.IdempotencyKey))
+	return outputs[0], true, nil
+}
+
+// record records the output of the task under its idempotency key, if the Runner has an IdempotencyStore.
+func (s *run) record(task *Task, val interface{}) error {
+	store := s.runner.Options.IdempotencyStore
+	if store == nil || task.IdempotencyKey == "" {
+		return nil
+	}
+
+	outputs, err := EncodeParameters(JSONCodec, val)
+	if err != nil {
+		return err
+	}
+	return s.storeWrite(task, func(ctx context.Context) error {
+		return store.Put(ctx, task.IdempotencyKey, outputs[0])
+	})
+}
+
+// forget deletes the output recorded under the idempotency key of a reverted task, if the Runner has an IdempotencyStore.
+func (s *run) forget(ctx context.Context, task *Task) error {
+	store := s.runner.Options.IdempotencyStore
+	if store == nil || task.IdempotencyKey == "" {
+		return nil
+	}
+	return s.runner.storeCall(ctx, func(ctx context.Context) error {
+		return store.Delete(ctx, task.IdempotencyKey)
+	})
+}
+
+// MemoryIdempotencyStore is an IdempotencyStore keeping outputs in memory, so they are reused within a single process only.
+type MemoryIdempotencyStore struct {
+	mu      sync.Mutex
+	outputs map[string]ParameterEnvelope
+}
+
+// NewMemoryIdempotencyStore creates a new, empty MemoryIdempotencyStore.
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{
+		outputs: make(map[string]ParameterEnvelope),
+	}
+}
+
+// Get implements the IdempotencyStore interface.
+func (s *MemoryIdempotencyStore) Get(_ context.Context, key string) (ParameterEnvelope, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	env, ok := s.outputs[key]
+	return env, ok, nil
+}
+
+// Put implements the IdempotencyStore interface.
+func (s *MemoryIdempotencyStore) Put(_ context.Context, key string, output ParameterEnvelope) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.outputs[key] = output
+	return nil
+}
+
+// Delete implements the IdempotencyStore interface.
+func (s *MemoryIdempotencyStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.outputs, key)
+	return nil
+}
+
+// idempotencyError wraps an error of the IdempotencyStore with the task it occurred for.
+func idempotencyError(task *Task, err error) error {
+	return fmt.Errorf("idempotency key %s of task %s: %w", task.IdempotencyKey, task.ID, err)
+}
@@ -0,0 +1,144 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestWithIdempotencyKey(t *testing.T) {
+	ctx := context.Background()
+	runner := NewRunner(WithIdempotencyStore(NewMemoryIdempotencyStore()))
+
+	charges := 0
+	charge := func() *Task {
+		return New(ctx, WithIdempotencyKey("charge-order-1"), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+			charges++
+			return "ch_1", nil
+		}))
+	}
+
+	for i := 0; i < 2; i++ {
+		results, err := runner.Run([]*Task{charge()})
+		if err != nil {
+			t.Fatalf("didnt expect error, got %v", err)
+		}
+		if results.Values()[0] != "ch_1" {
+			t.Errorf("expected output %q, got %v", "ch_1", results.Values()[0])
+		}
+	}
+	if charges != 1 {
+		t.Errorf("expected the task to run %d time, got %d", 1, charges)
+	}
+
+	if _, err := NewRunner().Run([]*Task{charge()}); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if charges != 2 {
+		t.Error("expected the key to have no effect without a store")
+	}
+}
+
+func TestIdempotencyKeyPointerOutput(t *testing.T) {
+	ctx := context.Background()
+	runner := NewRunner(WithIdempotencyStore(NewMemoryIdempotencyStore()))
+
+	for i := 0; i < 2; i++ {
+		charge := New(ctx, WithIdempotencyKey("invoice-1"), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+			return &invoice{ID: "inv_1"}, nil
+		}))
+		if err := charge.AddSubtasks(New(ctx, WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+			if _, ok := values[0].(*invoice); !ok {
+				return nil, fmt.Errorf("expected *invoice, got %T", values[0])
+			}
+			return nil, nil
+		}))); err != nil {
+			t.Fatalf("didnt expect error, got %v", err)
+		}
+
+		if _, err := runner.Run([]*Task{charge}); err != nil {
+			t.Fatalf("run %d: didnt expect error, got %v", i, err)
+		}
+	}
+}
+
+func TestIdempotencyKeyForgottenOnRevert(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryIdempotencyStore()
+	runner := NewRunner(WithIdempotencyStore(store))
+
+	charges, refunds := 0, 0
+	charge := New(ctx, WithIdempotencyKey("charge-order-1"), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		charges++
+		return "ch_1", nil
+	}), WithRevertFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		refunds++
+		return nil, nil
+	}))
+	charge.AddSubtasks(New(ctx, WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, errors.New("foobar")
+	})))
+
+	if _, err := runner.Run([]*Task{charge}); err == nil {
+		t.Fatal("expected an error")
+	}
+	if refunds != 1 {
+		t.Errorf("expected the task to be reverted once, got %d", refunds)
+	}
+	if _, ok, _ := store.Get(ctx, "charge-order-1"); ok {
+		t.Error("expected the output of the reverted task to be deleted")
+	}
+
+	if _, err := runner.Run([]*Task{charge}); err == nil {
+		t.Fatal("expected an error")
+	}
+	if charges != 2 {
+		t.Errorf("expected the reverted task to run again, got %d runs", charges)
+	}
+}
+
+func TestIdempotencyStoreError(t *testing.T) {
+	type unregistered struct{}
+
+	runner := NewRunner(WithIdempotencyStore(NewMemoryIdempotencyStore()))
+	task := New(context.Background(), WithIdempotencyKey("key"), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return unregistered{}, nil
+	}))
+
+	if _, err := runner.Run([]*Task{task}); !errors.Is(err, ErrUnknownParameterType) {
+		t.Errorf("expected an unknown parameter type error, got %v", err)
+	}
+}
+
+func TestSplitIdempotencyStore(t *testing.T) {
+	ctx := context.Background()
+	primary, replica := NewMemoryIdempotencyStore(), NewMemoryIdempotencyStore()
+	runner := NewRunner(WithIdempotencyStore(SplitIdempotencyStore(primary, replica)))
+
+	charges := 0
+	charge := New(ctx, WithIdempotencyKey("charge-order-1"), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		charges++
+		return "charged", nil
+	}))
+
+	if _, err := runner.Run([]*Task{charge}); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if _, ok, _ := primary.Get(ctx, "charge-order-1"); !ok {
+		t.Error("expected the output to be recorded in the primary")
+	}
+	if _, ok, _ := replica.Get(ctx, "charge-order-1"); ok {
+		t.Error("expected the output not to be recorded in the replica")
+	}
+
+	// outputs are looked up in the replica only
+	envs, _ := EncodeParameters(JSONCodec, "charged")
+	_ = replica.Put(ctx, "charge-order-1", envs[0])
+	if _, err := runner.Run([]*Task{charge}); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if charges != 1 {
+		t.Errorf("expected the output in the replica to be reused, got %d charges", charges)
+	}
+}
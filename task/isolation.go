@@ -0,0 +1,35 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrGoexit is wrapped into the error of a task whose goroutine exited with runtime.Goexit, e.g. because it called testing.T.FailNow.
+var ErrGoexit = errors.New("goroutine exited")
+
+// WithIsolation returns a RunnerConfigFunc that executes every task on a goroutine of its own even when tasks run sequentially,
+// instead of on the goroutine that called Run. A task calling runtime.Goexit then fails with an error wrapping ErrGoexit instead of
+// terminating the caller of Run, and a task can't leave goroutine-local state behind, e.g. a locked OS thread.
+// Tasks still run one after another in the same order, at the cost of starting a goroutine per task.
+func WithIsolation() RunnerConfigFunc {
+	return func(o *RunOptions) {
+		o.Isolation = true
+	}
+}
+
+// spawn executes the task on a goroutine of its own and sends its completion to done, even if the goroutine exits with runtime.Goexit.
+func (s *run) spawn(task *Task, values []interface{}, done chan<- completion) {
+	go func() {
+		completed := false
+		defer func() {
+			if !completed {
+				done <- completion{task: task, err: fmt.Errorf("task %s: %w", task.ID, ErrGoexit)}
+			}
+		}()
+
+		c := s.execute(task, values)
+		completed = true
+		done <- c
+	}()
+}
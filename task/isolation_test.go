@@ -0,0 +1,52 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+)
+
+func TestWithIsolation(t *testing.T) {
+	ctx := context.Background()
+	var order []string
+	reverted := false
+	first := New(ctx, WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		order = append(order, "first")
+		return nil, nil
+	}), WithRevertFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		reverted = true
+		return nil, nil
+	}))
+	exiting := New(ctx, WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		order = append(order, "exiting")
+		runtime.Goexit()
+		return nil, nil
+	}))
+	first.AddSubtasks(exiting)
+
+	_, err := NewRunner(WithIsolation()).Run([]*Task{first})
+	if !errors.Is(err, ErrGoexit) {
+		t.Fatalf("expected error %v, got %v", ErrGoexit, err)
+	}
+	if len(order) != 2 || order[0] != "first" || order[1] != "exiting" {
+		t.Errorf("expected the tasks to run in order, got %v", order)
+	}
+	if !reverted {
+		t.Error("expected the first task to be reverted")
+	}
+	if exiting.Status() != Failed {
+		t.Errorf("expected %s, got %s", Failed, exiting.Status())
+	}
+}
+
+func TestGoexitConcurrent(t *testing.T) {
+	task := New(context.Background(), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		runtime.Goexit()
+		return nil, nil
+	}))
+
+	if _, err := NewRunner(WithConcurrency(2)).Run([]*Task{task}); !errors.Is(err, ErrGoexit) {
+		t.Errorf("expected error %v, got %v", ErrGoexit, err)
+	}
+}
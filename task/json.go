@@ -0,0 +1,74 @@
+package task
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// graphJSON is the JSON representation of a Task together with every task reachable from it.
+type graphJSON struct {
+	Root  string     `json:"root"`
+	Nodes []taskJSON `json:"nodes"`
+}
+
+// taskJSON is the JSON representation of a single Task. Edges refer to other tasks by ID.
+type taskJSON struct {
+	ID           string              `json:"id"`
+	Name         string              `json:"name,omitempty"`
+	Status       string              `json:"status"`
+	Parameters   []ParameterEnvelope `json:"parameters,omitempty"`
+	Subtasks     []string            `json:"subtasks,omitempty"`
+	Dependencies []string            `json:"dependencies,omitempty"`
+	Tags         []string            `json:"tags,omitempty"`
+	SerialKey    string              `json:"serial_key,omitempty"`
+}
+
+// MarshalJSON implements the json.Marshaler interface. The task is encoded as the ID of the root task and a flat list of nodes
+// holding the task and every task reachable from it via subtasks and dependencies, each once, in the order they are discovered.
+// Every node holds the ID, name, status, tags and serial key of its task and the IDs of its subtasks and dependencies, so shared subtasks
+// and cycles are encoded without repetition. Parameters are encoded with JSONCodec and have to be registered with RegisterParameter.
+// Functions and the context of the task are not encoded. See the graph subpackage for a representation in topological order.
+func (t *Task) MarshalJSON() ([]byte, error) {
+	g := graphJSON{Root: t.ID}
+
+	seen := map[*Task]bool{}
+	queue := []*Task{t}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		if n == nil || seen[n] {
+			continue
+		}
+		seen[n] = true
+
+		params, err := EncodeParameters(JSONCodec, n.Parameters...)
+		if err != nil {
+			return nil, fmt.Errorf("task %s: %w", n.ID, err)
+		}
+		g.Nodes = append(g.Nodes, taskJSON{
+			ID:           n.ID,
+			Name:         n.Name,
+			Status:       n.Status().String(),
+			Parameters:   params,
+			Subtasks:     taskIDs(n.Subtasks),
+			Dependencies: taskIDs(n.Dependencies),
+			Tags:         n.Tags,
+			SerialKey:    n.SerialKey,
+		})
+		queue = append(queue, n.Subtasks...)
+		queue = append(queue, n.Dependencies...)
+	}
+	return json.Marshal(g)
+}
+
+// taskIDs returns the IDs of the tasks.
+func taskIDs(tasks []*Task) []string {
+	if len(tasks) == 0 {
+		return nil
+	}
+	ids := make([]string, 0, len(tasks))
+	for _, t := range tasks {
+		ids = append(ids, taskID(t))
+	}
+	return ids
+}
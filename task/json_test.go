@@ -0,0 +1,109 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestTaskMarshalJSON(t *testing.T) {
+	ctx := context.Background()
+	foo := New(ctx, WithName("foo"), WithParameters(invoice{ID: "inv_1"}), WithTags("billing"))
+	bar := New(ctx, WithName("bar"))
+	baz := New(ctx, WithName("baz"), WithSerialKey("account-1"))
+	if err := foo.AddSubtasks(bar); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if err := baz.DependsOn(foo); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+
+	data, err := json.Marshal(foo)
+	if err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	var decoded graphJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+
+	if decoded.Root != foo.ID || len(decoded.Nodes) != 2 {
+		t.Fatalf("expected root %s with %d nodes, got %s", foo.ID, 2, data)
+	}
+	root := decoded.Nodes[0]
+	if root.ID != foo.ID || root.Name != "foo" || root.Status != "pending" {
+		t.Errorf("expected pending task %s named foo, got %+v", foo.ID, root)
+	}
+	if len(root.Tags) != 1 || root.Tags[0] != "billing" {
+		t.Errorf("expected tags %v, got %v", foo.Tags, root.Tags)
+	}
+	if !reflect.DeepEqual(root.Subtasks, []string{bar.ID}) || decoded.Nodes[1].ID != bar.ID {
+		t.Errorf("expected subtask %s, got %s", bar.ID, data)
+	}
+
+	params, err := DecodeParameters(root.Parameters...)
+	if err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if !reflect.DeepEqual(params[0], invoice{ID: "inv_1"}) {
+		t.Errorf("expected parameter %v, got %v", invoice{ID: "inv_1"}, params[0])
+	}
+
+	data, err = json.Marshal(baz)
+	if err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	decoded = graphJSON{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	dependent := decoded.Nodes[0]
+	if !reflect.DeepEqual(dependent.Dependencies, []string{foo.ID}) || dependent.SerialKey != "account-1" {
+		t.Errorf("expected dependency %s and serial key, got %s", foo.ID, data)
+	}
+	if len(decoded.Nodes) != 3 {
+		t.Errorf("expected the dependency and its subtask to be encoded, got %s", data)
+	}
+}
+
+func TestTaskMarshalJSONSharedAndCycles(t *testing.T) {
+	ctx := context.Background()
+	root := New(ctx)
+	left, right, shared := New(ctx), New(ctx), New(ctx)
+	if err := root.AddSubtasks(left, right); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if err := left.AddSubtasks(shared); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if err := right.AddSubtasks(shared); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	// AddSubtasks doesn't check for cycles
+	if err := shared.AddSubtasks(root); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+
+	data, err := json.Marshal(root)
+	if err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	var decoded graphJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if len(decoded.Nodes) != 4 {
+		t.Errorf("expected every task to be encoded once, got %s", data)
+	}
+}
+
+func TestTaskMarshalJSONUnknownParameter(t *testing.T) {
+	type unregistered struct{}
+
+	task := New(context.Background(), WithParameters(unregistered{}))
+	if _, err := json.Marshal(task); !errors.Is(err, ErrUnknownParameterType) {
+		t.Errorf("expected an unknown parameter type error, got %v", err)
+	}
+}
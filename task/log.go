@@ -0,0 +1,65 @@
+package task
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// WithLogger returns a RunnerConfigFunc that makes the Runner log structured events about every task it executes with l,
+// so task functions don't need to log their own start and finish.
+//
+// Events:
+// - "task started" at info level, when the first attempt of a task starts, carrying the seed of the run, see WithSeed
+// - "task attempt failed" at warn level, when an attempt failed and the task is retried
+// - "task succeeded" at info level and "task failed" at error level, when the task finished
+// - "task reverted" at info level and "task revert failed" at error level, when the Revert function of a task was called
+// - "task skipped" at info level, when the condition of a task returned false, see WithCondition
+// - "task output buffered" at warn level, when the output of a task couldn't be recorded and is buffered, see BufferOnStoreError
+// - "detached run failed" at error level, when a run started with SubmitDetached failed, carrying run_id and error only
+//
+// Every event carries the attributes run_id, task_id and task_name. Events about finished attempts also carry attempt and duration,
+// failures carry error.
+//
+// Example usage:
+//
+//	logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
+//	runner := task.NewRunner(task.WithLogger(logger))
+func WithLogger(l *slog.Logger) RunnerConfigFunc {
+	return func(o *RunOptions) {
+		o.Logger = l
+	}
+}
+
+// log logs an event about a task with the logger of the Runner. It does nothing if the Runner has no logger.
+func (s *run) log(ctx context.Context, level slog.Level, msg string, task *Task, attrs ...slog.Attr) {
+	l := s.runner.Options.Logger
+	if l == nil {
+		return
+	}
+
+	attrs = append([]slog.Attr{
+		slog.String("run_id", s.id),
+		slog.String("task_id", task.ID),
+		slog.String("task_name", task.Name),
+	}, attrs...)
+	l.LogAttrs(ctx, level, msg, attrs...)
+}
+
+// logFinished logs the outcome of a task that finished after attempt attempts.
+func (s *run) logFinished(ctx context.Context, task *Task, attempt int, d time.Duration, err error) {
+	if err != nil {
+		s.log(ctx, slog.LevelError, "task failed", task, slog.Int("attempt", attempt), slog.Duration("duration", d), slog.Any("error", err))
+		return
+	}
+	s.log(ctx, slog.LevelInfo, "task succeeded", task, slog.Int("attempt", attempt), slog.Duration("duration", d))
+}
+
+// logReverted logs the outcome of the Revert function of a task.
+func (s *run) logReverted(ctx context.Context, task *Task, d time.Duration, err error) {
+	if err != nil {
+		s.log(ctx, slog.LevelError, "task revert failed", task, slog.Duration("duration", d), slog.Any("error", err))
+		return
+	}
+	s.log(ctx, slog.LevelInfo, "task reverted", task, slog.Duration("duration", d))
+}
@@ -0,0 +1,60 @@
+package task
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+)
+
+func TestWithLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	ctx := context.Background()
+	create := New(ctx, WithName("create"), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, nil
+	}), WithRevertFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, nil
+	}))
+	create.AddSubtasks(New(ctx, WithName("process"), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, errors.New("foobar")
+	}), WithRetry(2, nil)))
+
+	if _, err := NewRunner(WithLogger(logger)).Run([]*Task{create}); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	type event struct {
+		Level    string `json:"level"`
+		Msg      string `json:"msg"`
+		TaskName string `json:"task_name"`
+		Attempt  int    `json:"attempt"`
+		Error    string `json:"error"`
+	}
+
+	expected := []event{
+		{Level: "INFO", Msg: "task started", TaskName: "create"},
+		{Level: "INFO", Msg: "task succeeded", TaskName: "create", Attempt: 1},
+		{Level: "INFO", Msg: "task started", TaskName: "process"},
+		{Level: "WARN", Msg: "task attempt failed", TaskName: "process", Attempt: 1, Error: "foobar"},
+		{Level: "ERROR", Msg: "task failed", TaskName: "process", Attempt: 2, Error: "foobar"},
+		{Level: "INFO", Msg: "task reverted", TaskName: "create"},
+	}
+
+	dec := json.NewDecoder(&buf)
+	for i, want := range expected {
+		var got event
+		if err := dec.Decode(&got); err != nil {
+			t.Fatalf("expected event %d, got %v", i, err)
+		}
+		if got != want {
+			t.Errorf("expected event %+v, got %+v", want, got)
+		}
+	}
+	if dec.More() {
+		t.Error("didnt expect further events")
+	}
+}
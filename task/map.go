@@ -0,0 +1,106 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+)
+
+// MapFunc processes a single item of a Map task.
+type MapFunc[In, Out any] func(ctx context.Context, item In) (Out, error)
+
+// Map returns a task that calls f for every item and outputs the results as a []Out in the order of the items, so "process each record"
+// workflows don't need a task per record. Up to concurrency items, at least one, are processed at the same time on separate goroutines.
+// If items is nil, the task processes the most recent value of type []In it receives, selected like the input of NewTyped,
+// so the collection can be the output of a preceding task. The cfgs configure the returned task.
+//
+// The first item that fails cancels the context of the items still being processed and fails the task with an error naming the index
+// of the item. A panic of f fails the task with a *PanicError. A retry of the task processes all items again.
+//
+// Example usage:
+//
+//	resize := task.Map(ctx, nil, func(ctx context.Context, img Image) (Thumbnail, error) {
+//		return thumbnail(ctx, img)
+//	}, 8, task.WithName("thumbnails"))
+//	if err := resize.DependsOn(listImages); err != nil {
+//		return err
+//	}
+func Map[In, Out any](ctx context.Context, items []In, f MapFunc[In, Out], concurrency int, cfgs ...TaskConfigFunc) *Task {
+	run := func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		in := items
+		if in == nil {
+			var err error
+			if in, err = typedInput[[]In](ctx, values); err != nil {
+				return nil, err
+			}
+		}
+		return mapItems(ctx, in, f, max(concurrency, 1))
+	}
+	return New(ctx, append([]TaskConfigFunc{WithFunc(run)}, cfgs...)...)
+}
+
+// mapItems calls f for every item with up to concurrency calls at the same time.
+func mapItems[In, Out any](ctx context.Context, items []In, f MapFunc[In, Out], concurrency int) ([]Out, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	out := make([]Out, len(items))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	var once sync.Once
+	var failure error
+
+	for i, item := range items {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(i int, item In) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			val, err := mapItem(ctx, item, f)
+			if err != nil {
+				once.Do(func() {
+					failure = fmt.Errorf("item %d: %w", i, err)
+					cancel()
+				})
+				return
+			}
+			out[i] = val
+		}(i, item)
+	}
+	wg.Wait()
+
+	if failure != nil {
+		return nil, failure
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// mapItem calls f for a single item, converting a panic into a *PanicError.
+func mapItem[In, Out any](ctx context.Context, item In, f MapFunc[In, Out]) (val Out, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			perr := &PanicError{
+				Value: r,
+				Stack: debug.Stack(),
+			}
+			if tc, derr := DecodeCtx(ctx); derr == nil && tc.Task != nil {
+				perr.TaskID = tc.Task.ID
+			}
+			err = perr
+		}
+	}()
+
+	return f(ctx, item)
+}
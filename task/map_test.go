@@ -0,0 +1,88 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMap(t *testing.T) {
+	ctx := context.Background()
+	var running, peak atomic.Int32
+	double := func(ctx context.Context, n int) (int, error) {
+		if cur := running.Add(1); cur > peak.Load() {
+			peak.Store(cur)
+		}
+		defer running.Add(-1)
+
+		time.Sleep(time.Millisecond)
+		return n * 2, nil
+	}
+
+	list := New(ctx, WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return []int{1, 2, 3, 4, 5, 6}, nil
+	}))
+	doubled := Map(ctx, nil, double, 2, WithName("doubled"))
+	doubled.DependsOn(list)
+
+	results, err := Run([]*Task{list})
+	if err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+
+	got, err := GetAs[[]int](results, "doubled")
+	if err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if expected := []int{2, 4, 6, 8, 10, 12}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+	if peak.Load() > 2 {
+		t.Errorf("expected at most %d items at the same time, got %d", 2, peak.Load())
+	}
+}
+
+func TestMapFailure(t *testing.T) {
+	ctx := context.Background()
+	var processed atomic.Int32
+	task := Map(ctx, []string{"a", "b", "c", "d"}, func(ctx context.Context, item string) (string, error) {
+		processed.Add(1)
+		if item == "b" {
+			return "", errors.New("foobar")
+		}
+		return strings.ToUpper(item), nil
+	}, 1)
+
+	_, err := Run([]*Task{task})
+	if err == nil || err.Error() != "item 1: foobar" {
+		t.Fatalf("expected the error of item 1, got %v", err)
+	}
+	if processed.Load() != 2 {
+		t.Errorf("expected processing to stop after the failed item, processed %d", processed.Load())
+	}
+
+	panicking := Map(ctx, []int{1}, func(ctx context.Context, item int) (int, error) {
+		panic("boom")
+	}, 1)
+	_, err = Run([]*Task{panicking})
+	var perr *PanicError
+	if !errors.As(err, &perr) || perr.TaskID != panicking.ID {
+		t.Errorf("expected a *PanicError of task %s, got %v", panicking.ID, err)
+	}
+}
+
+func TestMapMissingInput(t *testing.T) {
+	task := Map(context.Background(), nil, func(ctx context.Context, item int) (int, error) {
+		return item, nil
+	}, 1)
+
+	_, err := Run([]*Task{task})
+	var terr *TypeError
+	if !errors.As(err, &terr) {
+		t.Errorf("expected a *TypeError, got %v", err)
+	}
+}
@@ -0,0 +1,27 @@
+package task
+
+import "time"
+
+// MetricsCollector records metrics about the tasks executed by a Runner. Implementations must be safe for concurrent use.
+// The metrics subpackage provides an implementation backed by Prometheus.
+//
+// TaskStarted is called before the first attempt of a task, TaskSucceeded and TaskFailed once the task finished,
+// including all retries, with the total duration. TaskReverted is called for every task whose Revert function
+// was called, with the error it returned.
+type MetricsCollector interface {
+	TaskStarted(t *Task)
+	TaskSucceeded(t *Task, d time.Duration)
+	TaskFailed(t *Task, d time.Duration, err error)
+	TaskReverted(t *Task, err error)
+}
+
+// WithMetricsCollector returns a RunnerConfigFunc that records metrics about every task executed by the Runner with c.
+//
+// Example usage:
+//
+//	runner := task.NewRunner(task.WithMetricsCollector(metrics.NewCollector(prometheus.DefaultRegisterer)))
+func WithMetricsCollector(c MetricsCollector) RunnerConfigFunc {
+	return func(o *RunOptions) {
+		o.Metrics = c
+	}
+}
@@ -0,0 +1,100 @@
+// Package metrics provides a task.MetricsCollector exposing Prometheus counters and histograms keyed by task name.
+package metrics
+
+import (
+	"time"
+
+	"github.com/codecreationlabs/async/task"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// UnnamedTask is the label value used for tasks without a name. Task IDs are not used as labels
+// because they are unique per task and would create a new time series for every task.
+const UnnamedTask = "unnamed"
+
+// Collector is a task.MetricsCollector recording Prometheus metrics. All metrics carry a "task" label
+// holding the name of the task.
+//
+// Metrics:
+// - task_started_total: the number of tasks started
+// - task_succeeded_total: the number of tasks that succeeded
+// - task_failed_total: the number of tasks that failed after all retries
+// - task_reverted_total: the number of tasks whose Revert function was called, labeled with the outcome "success" or "failure"
+// - task_duration_seconds: the duration of tasks including retries, labeled with the outcome "success" or "failure"
+type Collector struct {
+	started   *prometheus.CounterVec
+	succeeded *prometheus.CounterVec
+	failed    *prometheus.CounterVec
+	reverted  *prometheus.CounterVec
+	duration  *prometheus.HistogramVec
+}
+
+// NewCollector creates a new Collector and registers its metrics with reg.
+// It panics if the metrics can't be registered, like prometheus.MustRegister.
+//
+// Example usage:
+//
+//	runner := task.NewRunner(task.WithMetricsCollector(metrics.NewCollector(prometheus.DefaultRegisterer)))
+func NewCollector(reg prometheus.Registerer) *Collector {
+	c := &Collector{
+		started: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "task_started_total",
+			Help: "Number of tasks started.",
+		}, []string{"task"}),
+		succeeded: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "task_succeeded_total",
+			Help: "Number of tasks that succeeded.",
+		}, []string{"task"}),
+		failed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "task_failed_total",
+			Help: "Number of tasks that failed after all retries.",
+		}, []string{"task"}),
+		reverted: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "task_reverted_total",
+			Help: "Number of tasks whose revert function was called.",
+		}, []string{"task", "outcome"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "task_duration_seconds",
+			Help:    "Duration of tasks including retries.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"task", "outcome"}),
+	}
+
+	reg.MustRegister(c.started, c.succeeded, c.failed, c.reverted, c.duration)
+
+	return c
+}
+
+// TaskStarted implements the task.MetricsCollector interface.
+func (c *Collector) TaskStarted(t *task.Task) {
+	c.started.WithLabelValues(name(t)).Inc()
+}
+
+// TaskSucceeded implements the task.MetricsCollector interface.
+func (c *Collector) TaskSucceeded(t *task.Task, d time.Duration) {
+	c.succeeded.WithLabelValues(name(t)).Inc()
+	c.duration.WithLabelValues(name(t), "success").Observe(d.Seconds())
+}
+
+// TaskFailed implements the task.MetricsCollector interface.
+func (c *Collector) TaskFailed(t *task.Task, d time.Duration, _ error) {
+	c.failed.WithLabelValues(name(t)).Inc()
+	c.duration.WithLabelValues(name(t), "failure").Observe(d.Seconds())
+}
+
+// TaskReverted implements the task.MetricsCollector interface.
+func (c *Collector) TaskReverted(t *task.Task, err error) {
+	outcome := "success"
+	if err != nil {
+		outcome = "failure"
+	}
+	c.reverted.WithLabelValues(name(t), outcome).Inc()
+}
+
+// name returns the label value for a task.
+func name(t *task.Task) string {
+	if t.Name == "" {
+		return UnnamedTask
+	}
+	return t.Name
+}
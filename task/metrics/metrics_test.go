@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/codecreationlabs/async/task"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCollector(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	c := NewCollector(reg)
+
+	ctx := context.Background()
+	create := task.New(ctx, task.WithName("create-user"), task.WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, nil
+	}), task.WithRevertFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, nil
+	}))
+	create.AddSubtasks(task.New(ctx, task.WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, errors.New("foobar")
+	})))
+
+	if _, err := task.NewRunner(task.WithMetricsCollector(c)).Run([]*task.Task{create}); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if v := testutil.ToFloat64(c.started.WithLabelValues("create-user")); v != 1 {
+		t.Errorf("expected 1 started task, got %v", v)
+	}
+	if v := testutil.ToFloat64(c.succeeded.WithLabelValues("create-user")); v != 1 {
+		t.Errorf("expected 1 succeeded task, got %v", v)
+	}
+	if v := testutil.ToFloat64(c.failed.WithLabelValues(UnnamedTask)); v != 1 {
+		t.Errorf("expected 1 failed unnamed task, got %v", v)
+	}
+	if v := testutil.ToFloat64(c.reverted.WithLabelValues("create-user", "success")); v != 1 {
+		t.Errorf("expected 1 reverted task, got %v", v)
+	}
+	if n := testutil.CollectAndCount(c.duration); n != 2 {
+		t.Errorf("expected 2 duration series, got %d", n)
+	}
+}
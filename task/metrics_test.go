@@ -0,0 +1,64 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingCollector struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (c *recordingCollector) record(event string, t *Task) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.events = append(c.events, event+" "+t.Name)
+}
+
+func (c *recordingCollector) TaskStarted(t *Task) {
+	c.record("started", t)
+}
+
+func (c *recordingCollector) TaskSucceeded(t *Task, d time.Duration) {
+	c.record("succeeded", t)
+}
+
+func (c *recordingCollector) TaskFailed(t *Task, d time.Duration, err error) {
+	c.record("failed", t)
+}
+
+func (c *recordingCollector) TaskReverted(t *Task, err error) {
+	c.record("reverted", t)
+}
+
+func TestMetricsCollector(t *testing.T) {
+	ctx := context.Background()
+
+	create := New(ctx, WithName("create"), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, nil
+	}), WithRevertFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, nil
+	}))
+	create.AddSubtasks(New(ctx, WithName("process"), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, errors.New("foobar")
+	})))
+
+	c := &recordingCollector{}
+	if _, err := NewRunner(WithMetricsCollector(c)).Run([]*Task{create}); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	expected := []string{"started create", "succeeded create", "started process", "failed process", "reverted create"}
+	if len(c.events) != len(expected) {
+		t.Fatalf("expected events %v, got %v", expected, c.events)
+	}
+	for i := range expected {
+		if c.events[i] != expected[i] {
+			t.Fatalf("expected events %v, got %v", expected, c.events)
+		}
+	}
+}
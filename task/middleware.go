@@ -0,0 +1,52 @@
+package task
+
+// Middleware represents a function that wraps a TaskFunc with cross-cutting logic like logging, metrics, auth or tracing.
+// It takes the next TaskFunc of the chain and returns a TaskFunc that usually calls it.
+//
+// Example usage:
+//
+//	logging := func(next task.TaskFunc) task.TaskFunc {
+//		return func(ctx context.Context, values ...interface{}) (interface{}, error) {
+//			tc := task.MustDecodeCtx(ctx)
+//			log.Printf("start %s", tc.Task.ID)
+//			defer log.Printf("finish %s", tc.Task.ID)
+//			return next(ctx, values...)
+//		}
+//	}
+//
+//	runner := task.NewRunner(task.WithRunnerMiddleware(logging))
+type Middleware func(next TaskFunc) TaskFunc
+
+// WithMiddleware returns a TaskConfigFunc that wraps the Run function of the task in the given middleware.
+// The first middleware is the outermost one. Middleware runs for every attempt of the task, but not for its Revert function.
+func WithMiddleware(mw ...Middleware) TaskConfigFunc {
+	return func(t *Task) {
+		t.Middleware = append(t.Middleware, mw...)
+	}
+}
+
+// WithRunnerMiddleware returns a RunnerConfigFunc that wraps the Run function of every task executed by the Runner in the given middleware.
+// Runner middleware wraps the middleware of the task, the first middleware is the outermost one.
+func WithRunnerMiddleware(mw ...Middleware) RunnerConfigFunc {
+	return func(o *RunOptions) {
+		o.Middleware = append(o.Middleware, mw...)
+	}
+}
+
+// Use adds middleware wrapping every task executed by the Runner, like WithRunnerMiddleware.
+// It must not be called while the Runner is executing a run.
+func (r *Runner) Use(mw ...Middleware) {
+	r.Options.Middleware = append(r.Options.Middleware, mw...)
+}
+
+// chain returns the Run function of the task wrapped in the middleware of the task and the Runner.
+func (r *Runner) chain(task *Task) TaskFunc {
+	f := task.Run
+	for i := len(task.Middleware) - 1; i >= 0; i-- {
+		f = task.Middleware[i](f)
+	}
+	for i := len(r.Options.Middleware) - 1; i >= 0; i-- {
+		f = r.Options.Middleware[i](f)
+	}
+	return f
+}
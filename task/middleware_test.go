@@ -0,0 +1,65 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestMiddlewareOrder(t *testing.T) {
+	var calls []string
+	trace := func(name string) Middleware {
+		return func(next TaskFunc) TaskFunc {
+			return func(ctx context.Context, values ...interface{}) (interface{}, error) {
+				calls = append(calls, name+" before")
+				val, err := next(ctx, values...)
+				calls = append(calls, name+" after")
+				return val, err
+			}
+		}
+	}
+
+	task := New(context.Background(), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		calls = append(calls, "task")
+		return nil, nil
+	}), WithMiddleware(trace("task-outer"), trace("task-inner")))
+
+	r := NewRunner(WithRunnerMiddleware(trace("runner")))
+	r.Use(trace("use"))
+
+	if _, err := r.Run([]*Task{task}); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+
+	expected := []string{
+		"runner before", "use before", "task-outer before", "task-inner before",
+		"task",
+		"task-inner after", "task-outer after", "use after", "runner after",
+	}
+	if len(calls) != len(expected) {
+		t.Fatalf("expected calls %v, got %v", expected, calls)
+	}
+	for i := range expected {
+		if calls[i] != expected[i] {
+			t.Fatalf("expected calls %v, got %v", expected, calls)
+		}
+	}
+}
+
+func TestMiddlewareShortCircuit(t *testing.T) {
+	denied := errors.New("denied")
+	auth := func(next TaskFunc) TaskFunc {
+		return func(ctx context.Context, values ...interface{}) (interface{}, error) {
+			return nil, denied
+		}
+	}
+
+	task := New(context.Background(), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		t.Error("task should not run")
+		return nil, nil
+	}), WithMiddleware(auth))
+
+	if _, err := Run([]*Task{task}); !errors.Is(err, denied) {
+		t.Errorf("expected the middleware error, got %v", err)
+	}
+}
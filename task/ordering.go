@@ -0,0 +1,64 @@
+package task
+
+import "fmt"
+
+// Ordering names an ordering guarantee the runner keeps across releases. Each guarantee is covered by a test,
+// so code relying on it keeps working when the scheduler changes. Anything not listed by Guarantees, e.g. the order
+// in which independent tasks start with a concurrency greater than one, is not guaranteed.
+type Ordering int
+
+const (
+	// DependencyOrder means a task starts only after its parent task and all of its dependencies succeeded.
+	DependencyOrder Ordering = iota + 1
+	// SequentialOrder means a Runner without concurrency executes tasks breadth first: the given tasks in order,
+	// then the subtasks of each completed task in the order they were added, with dependents following
+	// as soon as their last dependency completed.
+	SequentialOrder
+	// DependencyValueOrder means a task with dependencies receives their outputs as values in the order the dependencies were declared.
+	DependencyValueOrder
+	// ResultOrder means Results.Values and the values passed to tasks without dependencies hold the outputs in completion order.
+	ResultOrder
+	// SinkOrder means sinks receive the results in completion order, one at a time, before any task depending on the result starts.
+	SinkOrder
+	// RevertOrder means the tasks that succeeded in a failed run are reverted in reverse completion order, one at a time,
+	// after every running task returned.
+	RevertOrder
+	// SerialKeyOrder means tasks sharing a serial key never overlap, and runs holding such tasks get their turn in the order
+	// they were reserved with a SerialQueue, which is submission order for the pool subpackage.
+	SerialKeyOrder
+)
+
+// String returns a human readable representation of the Ordering.
+func (o Ordering) String() string {
+	switch o {
+	case DependencyOrder:
+		return "dependency order"
+	case SequentialOrder:
+		return "sequential order"
+	case DependencyValueOrder:
+		return "dependency value order"
+	case ResultOrder:
+		return "result order"
+	case SinkOrder:
+		return "sink order"
+	case RevertOrder:
+		return "revert order"
+	case SerialKeyOrder:
+		return "serial key order"
+	default:
+		return fmt.Sprintf("Ordering(%d)", int(o))
+	}
+}
+
+// Guarantees returns every ordering guarantee kept by the runner.
+func Guarantees() []Ordering {
+	return []Ordering{
+		DependencyOrder,
+		SequentialOrder,
+		DependencyValueOrder,
+		ResultOrder,
+		SinkOrder,
+		RevertOrder,
+		SerialKeyOrder,
+	}
+}
@@ -0,0 +1,298 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// orderRecorder records events from concurrently running tasks.
+type orderRecorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *orderRecorder) add(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *orderRecorder) index(event string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, e := range r.events {
+		if e == event {
+			return i
+		}
+	}
+	return -1
+}
+
+// recordingTask creates a task named name recording its start and end and returning its name.
+func recordingTask(r *orderRecorder, name string, cfgs ...TaskConfigFunc) *Task {
+	return New(context.Background(), append([]TaskConfigFunc{WithName(name), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		r.add("start " + name)
+		time.Sleep(time.Millisecond)
+		r.add("end " + name)
+		return name, nil
+	}), WithRevertFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		r.add("revert " + name)
+		return nil, nil
+	})}, cfgs...)...)
+}
+
+func TestOrderingGuarantees(t *testing.T) {
+	tests := map[Ordering]func(t *testing.T){
+		DependencyOrder:      testDependencyOrder,
+		SequentialOrder:      testSequentialOrder,
+		DependencyValueOrder: testDependencyValueOrder,
+		ResultOrder:          testResultOrder,
+		SinkOrder:            testSinkOrder,
+		RevertOrder:          testRevertOrder,
+		SerialKeyOrder:       testSerialKeyOrder,
+	}
+
+	for _, o := range Guarantees() {
+		test, ok := tests[o]
+		if !ok {
+			t.Errorf("expected a test for the guarantee %q", o)
+			continue
+		}
+		t.Run(o.String(), test)
+	}
+	if len(tests) != len(Guarantees()) {
+		t.Errorf("expected every tested ordering to be listed by Guarantees")
+	}
+}
+
+func testDependencyOrder(t *testing.T) {
+	for _, concurrency := range []int{1, 4} {
+		r := &orderRecorder{}
+		root := recordingTask(r, "root")
+		a := recordingTask(r, "a")
+		b := recordingTask(r, "b")
+		c := recordingTask(r, "c")
+		root.AddSubtasks(a, b)
+		c.DependsOn(a, b)
+
+		if _, err := NewRunner(WithConcurrency(concurrency)).Run([]*Task{root}); err != nil {
+			t.Fatalf("didnt expect error, got %v", err)
+		}
+
+		for _, edge := range [][2]string{{"root", "a"}, {"root", "b"}, {"a", "c"}, {"b", "c"}} {
+			if r.index("end "+edge[0]) > r.index("start "+edge[1]) {
+				t.Errorf("concurrency %d: expected %s to end before %s starts, got %v", concurrency, edge[0], edge[1], r.events)
+			}
+		}
+	}
+}
+
+func testSequentialOrder(t *testing.T) {
+	r := &orderRecorder{}
+	foo := recordingTask(r, "foo")
+	bar := recordingTask(r, "bar")
+	quz := recordingTask(r, "quz")
+	baz := recordingTask(r, "baz")
+	last := recordingTask(r, "last")
+	foo.AddSubtasks(quz, baz)
+	last.DependsOn(bar)
+
+	if _, err := Run([]*Task{foo, bar}); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+
+	var starts []string
+	for _, e := range r.events {
+		if len(e) > 6 && e[:6] == "start " {
+			starts = append(starts, e[6:])
+		}
+	}
+	expected := []string{"foo", "bar", "quz", "baz", "last"}
+	if !reflect.DeepEqual(starts, expected) {
+		t.Errorf("expected tasks to start in order %v, got %v", expected, starts)
+	}
+}
+
+func testDependencyValueOrder(t *testing.T) {
+	for _, concurrency := range []int{1, 4} {
+		r := &orderRecorder{}
+		a := recordingTask(r, "a")
+		b := recordingTask(r, "b")
+		c := recordingTask(r, "c")
+
+		var got []interface{}
+		sum := New(context.Background(), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+			got = values
+			return nil, nil
+		}))
+		sum.DependsOn(c, a, b)
+
+		if _, err := NewRunner(WithConcurrency(concurrency)).Run([]*Task{a, b, c}); err != nil {
+			t.Fatalf("didnt expect error, got %v", err)
+		}
+
+		expected := []interface{}{"c", "a", "b"}
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("concurrency %d: expected values %v, got %v", concurrency, expected, got)
+		}
+	}
+}
+
+func testResultOrder(t *testing.T) {
+	// the sink is called by the runner as it records every completion, so it observes the completion order
+	var completed []interface{}
+	sink := SinkFunc(func(ctx context.Context, res Result) error {
+		completed = append(completed, res.Value)
+		return nil
+	})
+	var seen []interface{}
+
+	tasks := make([]*Task, 0, 5)
+	for i := 0; i < 5; i++ {
+		i := i
+		tasks = append(tasks, New(context.Background(), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+			// later tasks tend to complete first
+			time.Sleep(time.Duration(5-i) * time.Millisecond)
+			return i, nil
+		})))
+	}
+	last := New(context.Background(), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		seen = values
+		return nil, nil
+	}))
+	if err := last.DependsOn(tasks...); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+
+	results, err := NewRunner(WithConcurrency(5), WithSinks(sink)).Run(tasks)
+	if err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+
+	if !reflect.DeepEqual(results.Values(), completed) {
+		t.Errorf("expected results %v in completion order, got %v", completed, results.Values())
+	}
+	if expected := []interface{}{0, 1, 2, 3, 4}; !reflect.DeepEqual(seen, expected) {
+		t.Errorf("expected values %v in dependency order, got %v", expected, seen)
+	}
+}
+
+func testSinkOrder(t *testing.T) {
+	r := &orderRecorder{}
+	var delivered []string
+	sink := SinkFunc(func(ctx context.Context, res Result) error {
+		r.add("sink " + res.Value.(string))
+		delivered = append(delivered, res.Value.(string))
+		return nil
+	})
+
+	root := recordingTask(r, "root")
+	a := recordingTask(r, "a")
+	b := recordingTask(r, "b")
+	root.AddSubtasks(a, b)
+
+	results, err := NewRunner(WithSinks(sink), WithConcurrency(4)).Run([]*Task{root})
+	if err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+
+	var expected []string
+	for _, v := range results.Values() {
+		expected = append(expected, v.(string))
+	}
+	if !reflect.DeepEqual(delivered, expected) {
+		t.Errorf("expected results %v to be delivered in completion order, got %v", expected, delivered)
+	}
+	if r.index("sink root") > r.index("start a") || r.index("sink root") > r.index("start b") {
+		t.Errorf("expected the result of root to be delivered before its subtasks start, got %v", r.events)
+	}
+}
+
+func testRevertOrder(t *testing.T) {
+	for _, concurrency := range []int{1, 4} {
+		r := &orderRecorder{}
+		root := recordingTask(r, "root")
+		a := recordingTask(r, "a")
+		b := recordingTask(r, "b")
+		slow := recordingTask(r, "slow", WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+			time.Sleep(5 * time.Millisecond)
+			r.add("end slow")
+			return "slow", nil
+		}))
+		failing := New(context.Background(), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+			return nil, errors.New("foobar")
+		}))
+		root.AddSubtasks(a, b)
+		a.AddSubtasks(slow)
+		b.AddSubtasks(failing)
+
+		if _, err := NewRunner(WithConcurrency(concurrency)).Run([]*Task{root}); err == nil {
+			t.Fatal("expected an error")
+		}
+
+		var completed, reverted []string
+		for _, e := range r.events {
+			var name string
+			if _, err := fmt.Sscanf(e, "end %s", &name); err == nil {
+				completed = append(completed, name)
+			}
+			if _, err := fmt.Sscanf(e, "revert %s", &name); err == nil {
+				reverted = append(reverted, name)
+			}
+		}
+		for i, j := 0, len(completed)-1; i < j; i, j = i+1, j-1 {
+			completed[i], completed[j] = completed[j], completed[i]
+		}
+		if !reflect.DeepEqual(reverted, completed) {
+			t.Errorf("concurrency %d: expected reverts %v in reverse completion order, got %v", concurrency, completed, reverted)
+		}
+		for _, name := range completed {
+			if r.index("end "+name) > r.index("revert "+completed[0]) {
+				t.Errorf("concurrency %d: expected %s to return before the revert starts, got %v", concurrency, name, r.events)
+			}
+		}
+	}
+}
+
+func testSerialKeyOrder(t *testing.T) {
+	q := NewSerialQueue()
+	runner := NewRunner(WithSerialQueue(q), WithConcurrency(4))
+
+	r := &orderRecorder{}
+	graphs := make([][]*Task, 3)
+	ctxs := make([]context.Context, 3)
+	for i := range graphs {
+		graphs[i] = []*Task{
+			recordingTask(r, fmt.Sprintf("%d-a", i), WithSerialKey("account-1")),
+			recordingTask(r, fmt.Sprintf("%d-b", i), WithSerialKey("account-1")),
+		}
+		ctxs[i] = q.Reserve(context.Background(), graphs[i])
+	}
+
+	var wg sync.WaitGroup
+	for i := len(graphs) - 1; i >= 0; i-- {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, err := runner.RunCtx(ctxs[i], graphs[i]); err != nil {
+				t.Errorf("didnt expect error, got %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 1; i < len(r.events); i += 2 {
+		start, end := r.events[i-1], r.events[i]
+		if start[:6] != "start " || end != "end "+start[6:] {
+			t.Fatalf("expected tasks sharing a key to never overlap, got %v", r.events)
+		}
+		if run := int(start[6] - '0'); run != (i-1)/4 {
+			t.Fatalf("expected runs to get their turn in reservation order, got %v", r.events)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// PanicError is returned when the Run or Revert function of a task panics. The panic is recovered by the runner
+// and handled like any other failure, so a failed type assertion in one task reverts the run instead of crashing the program.
+//
+// Members:
+// - TaskID: the ID of the task that panicked, empty if the function passed to Future.Then panicked
+// - Value: the value passed to panic
+// - Stack: the stack trace of the goroutine at the time of the panic
+// - Goroutines: the stack traces of all goroutines at the time of the panic, only captured with WithGoroutineDump
+// - Revert: whether the Revert function of the task panicked rather than its Run function
+type PanicError struct {
+	TaskID     string
+	Value      interface{}
+	Stack      []byte
+	Goroutines []byte
+	Revert     bool
+}
+
+// Error implements the error interface.
+func (e *PanicError) Error() string {
+	if e.Revert {
+		return fmt.Sprintf("revert of task %s panicked: %v", e.TaskID, e.Value)
+	}
+	if e.TaskID == "" {
+		return fmt.Sprintf("continuation panicked: %v", e.Value)
+	}
+	return fmt.Sprintf("task %s panicked: %v", e.TaskID, e.Value)
+}
+
+// Unwrap returns the panic value if it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// PanicHandler is called with every *PanicError a run fails with, so panics can be turned into incidents, e.g. by paging
+// whoever owns the task. Panics of Revert functions deserve the most attention, as they leave side effects behind that were not compensated.
+type PanicHandler func(ctx context.Context, task *Task, err *PanicError)
+
+// WithPanicHandler returns a RunnerConfigFunc that calls h when a task fails because its Run function panicked, after its retries
+// are exhausted, and when the Revert function of a task panicked in its last attempt. h is called on the goroutine of the task,
+// so it should return quickly.
+//
+// Example usage:
+//
+//	runner := task.NewRunner(task.WithGoroutineDump(), task.WithPanicHandler(func(ctx context.Context, t *task.Task, err *task.PanicError) {
+//		severity := "high"
+//		if err.Revert {
+//			severity = "critical"
+//		}
+//		incidents.Open(ctx, severity, err.Error(), err.Goroutines)
+//	}))
+func WithPanicHandler(h PanicHandler) RunnerConfigFunc {
+	return func(o *RunOptions) {
+		o.PanicHandler = h
+	}
+}
+
+// WithGoroutineDump returns a RunnerConfigFunc that captures the stack traces of all goroutines in PanicError.Goroutines
+// when a task panics, which helps to find deadlocks or the state of other tasks at the time of the panic.
+// Capturing the dump stops the world for a moment, so it is disabled by default.
+func WithGoroutineDump() RunnerConfigFunc {
+	return func(o *RunOptions) {
+		o.GoroutineDump = true
+	}
+}
+
+// invoke calls the TaskFunc f of a task and converts a panic into a *PanicError. If dump is set, the *PanicError carries the stack traces of all goroutines.
+func invoke(ctx context.Context, task *Task, f TaskFunc, values []interface{}, dump bool) (val interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			perr := &PanicError{
+				TaskID: task.ID,
+				Value:  r,
+				Stack:  debug.Stack(),
+			}
+			if dump {
+				perr.Goroutines = goroutines()
+			}
+			val = nil
+			err = perr
+		}
+	}()
+
+	return f(ctx, values...)
+}
+
+// invokeRevert calls the Revert function of a task like invoke, marking a *PanicError as raised by the Revert function.
+func invokeRevert(ctx context.Context, task *Task, values []interface{}, dump bool) error {
+	_, err := invoke(ctx, task, task.Revert, values, dump)
+	if perr, ok := err.(*PanicError); ok {
+		perr.Revert = true
+	}
+	return err
+}
+
+// goroutines returns the stack traces of all goroutines, growing the buffer until they fit.
+func goroutines() []byte {
+	buf := make([]byte, 64*1024)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
+
+// panicked calls the PanicHandler of the Runner if err is caused by a panic.
+func (s *run) panicked(ctx context.Context, task *Task, err error) {
+	h := s.runner.Options.PanicHandler
+	if h == nil || err == nil {
+		return
+	}
+
+	var perr *PanicError
+	if errors.As(err, &perr) {
+		h(ctx, task, perr)
+	}
+}
@@ -0,0 +1,134 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPanicRecovery(t *testing.T) {
+	reverted := false
+	root := New(context.Background(), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return 1, nil
+	}), WithRevertFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		reverted = true
+		return nil, nil
+	}))
+
+	bad := New(context.Background(), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return values[0].(string), nil
+	}))
+	root.AddSubtasks(bad)
+
+	_, err := Run([]*Task{root})
+
+	var perr *PanicError
+	if !errors.As(err, &perr) {
+		t.Fatalf("expected a PanicError, got %v", err)
+	}
+	if perr.TaskID != bad.ID {
+		t.Errorf("expected task id %s, got %s", bad.ID, perr.TaskID)
+	}
+	if !strings.Contains(string(perr.Stack), "panic_test.go") {
+		t.Error("expected the stack trace to point to the panicking task")
+	}
+	if !reverted {
+		t.Error("expected successful tasks to be reverted")
+	}
+}
+
+func TestPanicRecoveryWithTimeout(t *testing.T) {
+	task := New(context.Background(), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		panic("boom")
+	}), WithTimeout(time.Second))
+
+	_, err := Run([]*Task{task})
+
+	var perr *PanicError
+	if !errors.As(err, &perr) || perr.Value != "boom" {
+		t.Fatalf("expected a PanicError, got %v", err)
+	}
+}
+
+func TestPanicInRevert(t *testing.T) {
+	cause := errors.New("compensation crashed")
+	root := New(context.Background(), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, nil
+	}), WithRevertFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		panic(cause)
+	}))
+	root.AddSubtasks(New(context.Background(), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, errors.New("foobar")
+	})))
+
+	_, err := Run([]*Task{root})
+
+	var rerr *RevertError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("expected a RevertError, got %v", err)
+	}
+	if !errors.Is(err, cause) {
+		t.Error("expected the panic value to be wrapped")
+	}
+}
+
+func TestPanicHandler(t *testing.T) {
+	var mu sync.Mutex
+	var handled []*PanicError
+	runner := NewRunner(WithGoroutineDump(), WithPanicHandler(func(ctx context.Context, task *Task, err *PanicError) {
+		mu.Lock()
+		defer mu.Unlock()
+		handled = append(handled, err)
+	}))
+
+	root := New(context.Background(), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, nil
+	}), WithRevertFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		panic("compensation crashed")
+	}))
+	bad := New(context.Background(), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		panic("boom")
+	}), WithRetry(2, nil))
+	root.AddSubtasks(bad)
+
+	if _, err := runner.Run([]*Task{root}); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if len(handled) != 2 {
+		t.Fatalf("expected the panic of the task and of the revert to be handled once each, got %d", len(handled))
+	}
+	if handled[0].TaskID != bad.ID || handled[0].Revert {
+		t.Errorf("expected the panic of task %s first, got %v", bad.ID, handled[0])
+	}
+	if handled[1].TaskID != root.ID || !handled[1].Revert {
+		t.Errorf("expected the panic of the revert of task %s second, got %v", root.ID, handled[1])
+	}
+	if !strings.Contains(handled[1].Error(), "revert of task") {
+		t.Errorf("expected the error to mention the revert, got %q", handled[1].Error())
+	}
+	for _, perr := range handled {
+		if !strings.Contains(string(perr.Goroutines), "goroutine ") {
+			t.Error("expected a goroutine dump")
+		}
+	}
+}
+
+func TestPanicWithoutGoroutineDump(t *testing.T) {
+	task := New(context.Background(), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		panic("boom")
+	}))
+
+	_, err := Run([]*Task{task})
+
+	var perr *PanicError
+	if !errors.As(err, &perr) {
+		t.Fatalf("expected a PanicError, got %v", err)
+	}
+	if perr.Goroutines != nil {
+		t.Error("expected no goroutine dump by default")
+	}
+}
@@ -0,0 +1,241 @@
+package task
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// ErrUnknownParameterType is wrapped into the error returned by EncodeParameters and DecodeParameters for a parameter
+// whose type was not registered with RegisterParameter.
+var ErrUnknownParameterType = errors.New("unknown parameter type")
+
+// ErrUnknownCodec is wrapped into the error returned by DecodeParameters for an envelope whose encoding was not registered with RegisterCodec.
+var ErrUnknownCodec = errors.New("unknown codec")
+
+// Codec encodes and decodes parameter values for ParameterEnvelopes. Implementations must be safe for concurrent use.
+type Codec interface {
+	// Name returns the encoding tag stored in the envelopes created with the Codec.
+	Name() string
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// JSONCodec encodes parameters with encoding/json.
+var JSONCodec Codec = jsonCodec{}
+
+// GobCodec encodes parameters with encoding/gob.
+var GobCodec Codec = gobCodec{}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string { return "json" }
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) { return json.Marshal(v) }
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+
+type gobCodec struct{}
+
+func (gobCodec) Name() string { return "gob" }
+
+func (gobCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// ParameterEnvelope holds a single encoded task parameter together with the information needed to decode it into its original type,
+// so parameters survive serialization as real structs instead of map[string]interface{}.
+//
+// Members:
+// - Name: the name the type of the parameter was registered under with RegisterParameter, empty for a nil parameter
+// - Encoding: the name of the Codec that encoded the payload
+// - Payload: the encoded parameter
+// - Pointer: whether the parameter was a pointer, so decoding yields a pointer again
+type ParameterEnvelope struct {
+	Name     string `json:"name"`
+	Encoding string `json:"encoding"`
+	Payload  []byte `json:"payload"`
+	Pointer  bool   `json:"pointer,omitempty"`
+}
+
+// parameterRegistry maps registered names to parameter types and back.
+type parameterRegistry struct {
+	mu     sync.RWMutex
+	types  map[string]reflect.Type
+	names  map[reflect.Type]string
+	codecs map[string]Codec
+}
+
+var parameters = &parameterRegistry{
+	types:  make(map[string]reflect.Type),
+	names:  make(map[reflect.Type]string),
+	codecs: make(map[string]Codec),
+}
+
+func init() {
+	RegisterCodec(JSONCodec)
+	RegisterCodec(GobCodec)
+
+	RegisterParameter("string", "")
+	RegisterParameter("bool", false)
+	RegisterParameter("int", 0)
+	RegisterParameter("int64", int64(0))
+	RegisterParameter("float64", float64(0))
+	RegisterParameter("bytes", []byte(nil))
+}
+
+// RegisterParameter registers the type of value under name, so parameters of that type can be encoded with EncodeParameters
+// and decoded back into the same type with DecodeParameters. Producers and workers have to register the same types under the same names,
+// usually in an init function. Registering a pointer type registers the type it points to. Like gob.Register, RegisterParameter
+// panics if name or the type is already registered for something else.
+//
+// Example usage:
+//
+//	func init() {
+//		task.RegisterParameter("billing.Invoice", Invoice{})
+//	}
+func RegisterParameter(name string, value interface{}) {
+	t := reflect.TypeOf(value)
+	if t == nil {
+		panic("task: cannot register nil parameter type")
+	}
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	parameters.mu.Lock()
+	defer parameters.mu.Unlock()
+
+	if existing, ok := parameters.types[name]; ok && existing != t {
+		panic(fmt.Sprintf("task: parameter name %q registered for %s and %s", name, existing, t))
+	}
+	if existing, ok := parameters.names[t]; ok && existing != name {
+		panic(fmt.Sprintf("task: parameter type %s registered as %q and %q", t, existing, name))
+	}
+	parameters.types[name] = t
+	parameters.names[t] = name
+}
+
+// RegisterCodec registers c, so DecodeParameters can decode envelopes encoded with it. JSONCodec and GobCodec are registered by default.
+func RegisterCodec(c Codec) {
+	parameters.mu.Lock()
+	defer parameters.mu.Unlock()
+	parameters.codecs[c.Name()] = c
+}
+
+// EncodeParameters encodes every parameter with codec into a ParameterEnvelope. Every parameter has to be nil, a value or a pointer
+// to a value of a type registered with RegisterParameter. Pointers are encoded as the value they point to and marked as pointers,
+// so decoding yields a pointer to the decoded value. A nil pointer to a registered type decodes as a nil pointer of the same type.
+//
+// Example usage:
+//
+//	envs, err := task.EncodeParameters(task.JSONCodec, t.Parameters...)
+//	if err != nil {
+//		return err
+//	}
+//	body, err := json.Marshal(envs)
+func EncodeParameters(codec Codec, params ...interface{}) ([]ParameterEnvelope, error) {
+	envs := make([]ParameterEnvelope, 0, len(params))
+	for i, p := range params {
+		if p == nil {
+			envs = append(envs, ParameterEnvelope{Encoding: codec.Name()})
+			continue
+		}
+
+		v := reflect.ValueOf(p)
+		pointer := v.Kind() == reflect.Pointer
+		t := v.Type()
+		if pointer {
+			t = t.Elem()
+		}
+
+		parameters.mu.RLock()
+		name, ok := parameters.names[t]
+		parameters.mu.RUnlock()
+		if pointer && v.IsNil() {
+			envs = append(envs, ParameterEnvelope{Name: name, Encoding: codec.Name(), Pointer: ok})
+			continue
+		}
+		if !ok {
+			return nil, fmt.Errorf("parameter %d: %w %s", i, ErrUnknownParameterType, t)
+		}
+		if pointer {
+			v = v.Elem()
+		}
+
+		payload, err := codec.Marshal(v.Interface())
+		if err != nil {
+			return nil, fmt.Errorf("parameter %d: %w", i, err)
+		}
+
+		envs = append(envs, ParameterEnvelope{
+			Name:     name,
+			Encoding: codec.Name(),
+			Payload:  payload,
+			Pointer:  pointer,
+		})
+	}
+	return envs, nil
+}
+
+// DecodeParameters decodes envelopes created by EncodeParameters into values of their registered types, in the same order.
+//
+// Example usage:
+//
+//	var envs []task.ParameterEnvelope
+//	if err := json.Unmarshal(body, &envs); err != nil {
+//		return err
+//	}
+//	params, err := task.DecodeParameters(envs...)
+//	if err != nil {
+//		return err
+//	}
+//	t := task.New(ctx, task.WithFunc(createInvoice), task.WithParameters(params...))
+func DecodeParameters(envs ...ParameterEnvelope) ([]interface{}, error) {
+	params := make([]interface{}, 0, len(envs))
+	for i, env := range envs {
+		if env.Name == "" {
+			params = append(params, nil)
+			continue
+		}
+
+		parameters.mu.RLock()
+		t, ok := parameters.types[env.Name]
+		codec, known := parameters.codecs[env.Encoding]
+		parameters.mu.RUnlock()
+		if !ok {
+			return nil, fmt.Errorf("parameter %d: %w %q", i, ErrUnknownParameterType, env.Name)
+		}
+		if !known {
+			return nil, fmt.Errorf("parameter %d: %w %q", i, ErrUnknownCodec, env.Encoding)
+		}
+
+		if env.Pointer && env.Payload == nil {
+			params = append(params, reflect.Zero(reflect.PointerTo(t)).Interface())
+			continue
+		}
+
+		v := reflect.New(t)
+		if err := codec.Unmarshal(env.Payload, v.Interface()); err != nil {
+			return nil, fmt.Errorf("parameter %d: %w", i, err)
+		}
+		if env.Pointer {
+			params = append(params, v.Interface())
+		} else {
+			params = append(params, v.Elem().Interface())
+		}
+	}
+	return params, nil
+}
@@ -0,0 +1,71 @@
+package task
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type invoice struct {
+	ID     string
+	Amount int
+	Lines  []string
+}
+
+func init() {
+	RegisterParameter("task.invoice", invoice{})
+}
+
+func TestParametersRoundTrip(t *testing.T) {
+	params := []interface{}{invoice{ID: "inv_1", Amount: 42, Lines: []string{"a", "b"}}, &invoice{ID: "inv_2"}, (*invoice)(nil), "foo", 7, nil}
+	expected := []interface{}{invoice{ID: "inv_1", Amount: 42, Lines: []string{"a", "b"}}, &invoice{ID: "inv_2"}, (*invoice)(nil), "foo", 7, nil}
+
+	for _, codec := range []Codec{JSONCodec, GobCodec} {
+		envs, err := EncodeParameters(codec, params...)
+		if err != nil {
+			t.Fatalf("%s: didnt expect error, got %v", codec.Name(), err)
+		}
+
+		// serialize the envelopes themselves like a transport would
+		body, err := json.Marshal(envs)
+		if err != nil {
+			t.Fatalf("%s: didnt expect error, got %v", codec.Name(), err)
+		}
+		var decoded []ParameterEnvelope
+		if err := json.Unmarshal(body, &decoded); err != nil {
+			t.Fatalf("%s: didnt expect error, got %v", codec.Name(), err)
+		}
+
+		got, err := DecodeParameters(decoded...)
+		if err != nil {
+			t.Fatalf("%s: didnt expect error, got %v", codec.Name(), err)
+		}
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("%s: expected parameters %#v, got %#v", codec.Name(), expected, got)
+		}
+	}
+}
+
+func TestParametersUnknownType(t *testing.T) {
+	type unregistered struct{}
+
+	if _, err := EncodeParameters(JSONCodec, unregistered{}); !errors.Is(err, ErrUnknownParameterType) {
+		t.Errorf("expected an unknown parameter type error, got %v", err)
+	}
+	if _, err := DecodeParameters(ParameterEnvelope{Name: "missing", Encoding: "json"}); !errors.Is(err, ErrUnknownParameterType) {
+		t.Errorf("expected an unknown parameter type error, got %v", err)
+	}
+	if _, err := DecodeParameters(ParameterEnvelope{Name: "string", Encoding: "xml"}); !errors.Is(err, ErrUnknownCodec) {
+		t.Errorf("expected an unknown codec error, got %v", err)
+	}
+}
+
+func TestRegisterParameterConflict(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected registering a name twice to panic")
+		}
+	}()
+	RegisterParameter("task.invoice", "")
+}
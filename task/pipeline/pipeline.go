@@ -0,0 +1,81 @@
+// Package pipeline provides a fluent builder for linear task chains, where every stage consumes the output of the stage before it.
+package pipeline
+
+import (
+	"context"
+	"errors"
+
+	"github.com/codecreationlabs/async/task"
+)
+
+var (
+	// ErrEmpty is returned by Build for a pipeline without stages.
+	ErrEmpty = errors.New("pipeline has no stages")
+	// ErrNoStage is returned by Build if OnError was called before the first stage was added.
+	ErrNoStage = errors.New("revert function without stage")
+)
+
+// Builder builds a linear chain of tasks. The methods of a Builder return it, so calls can be chained. A Builder is not safe for concurrent use.
+type Builder struct {
+	ctx    context.Context
+	stages []*task.Task
+	err    error
+}
+
+// New creates a new Builder for a pipeline whose tasks are created with ctx.
+//
+// Example usage:
+//
+//	order, err := pipeline.New(ctx).
+//		Then(reserveStock).OnError(releaseStock).
+//		Then(chargeCard).OnError(refundCard).
+//		Then(shipOrder).
+//		Build()
+//	if err != nil {
+//		return err
+//	}
+//	results, err := task.Run(order, cart)
+func New(ctx context.Context) *Builder {
+	return &Builder{ctx: ctx}
+}
+
+// Then adds a stage running f after the previous stage succeeded. The first stage receives the values passed to the run,
+// every further stage receives the output of the previous stage as its only value. The cfgs configure the task of the stage, e.g. its name or retry policy.
+func (b *Builder) Then(f task.TaskFunc, cfgs ...task.TaskConfigFunc) *Builder {
+	t := task.New(b.ctx, append([]task.TaskConfigFunc{task.WithFunc(f)}, cfgs...)...)
+	if len(b.stages) > 0 {
+		if err := t.DependsOn(b.stages[len(b.stages)-1]); err != nil && b.err == nil {
+			b.err = err
+		}
+	}
+	b.stages = append(b.stages, t)
+	return b
+}
+
+// OnError sets f as the Revert function of the last stage added, so the stage is compensated if a later stage fails.
+func (b *Builder) OnError(f task.TaskFunc) *Builder {
+	if len(b.stages) == 0 {
+		b.err = ErrNoStage
+		return b
+	}
+	b.stages[len(b.stages)-1].Revert = f
+	return b
+}
+
+// Build returns the tasks of the pipeline to pass to task.Run or a task.Runner. As stages depend on each other, running the returned
+// tasks runs the whole chain. It fails with ErrEmpty for a pipeline without stages, with ErrNoStage if OnError was called first,
+// and with the error of chaining a stage to the previous one, e.g. because the tasks were sealed after an earlier Build.
+func (b *Builder) Build() ([]*task.Task, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if len(b.stages) == 0 {
+		return nil, ErrEmpty
+	}
+	return []*task.Task{b.stages[0]}, nil
+}
+
+// Stages returns the tasks of all stages added so far in order, e.g. to attach further tasks to a stage.
+func (b *Builder) Stages() []*task.Task {
+	return b.stages
+}
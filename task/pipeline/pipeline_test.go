@@ -0,0 +1,89 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/codecreationlabs/async/task"
+)
+
+func TestPipeline(t *testing.T) {
+	ctx := context.Background()
+	add := func(n int) task.TaskFunc {
+		return func(ctx context.Context, values ...interface{}) (interface{}, error) {
+			if len(values) != 1 {
+				return nil, errors.New("expected a single value")
+			}
+			return values[0].(int) + n, nil
+		}
+	}
+
+	tasks, err := New(ctx).Then(add(1)).Then(add(10)).Then(add(100), task.WithName("last")).Build()
+	if err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+
+	results, err := task.Run(tasks, 1000)
+	if err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if v, _ := results.Get("last"); v != 1111 {
+		t.Errorf("expected %d, got %v", 1111, v)
+	}
+}
+
+func TestPipelineOnError(t *testing.T) {
+	ctx := context.Background()
+	var reverted []string
+	revert := func(name string) task.TaskFunc {
+		return func(ctx context.Context, values ...interface{}) (interface{}, error) {
+			reverted = append(reverted, name)
+			return nil, nil
+		}
+	}
+	ok := func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, nil
+	}
+
+	tasks, err := New(ctx).
+		Then(ok).OnError(revert("reserve")).
+		Then(ok).OnError(revert("charge")).
+		Then(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+			return nil, errors.New("foobar")
+		}).
+		Build()
+	if err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+
+	if _, err := task.Run(tasks); err == nil {
+		t.Fatal("expected an error")
+	}
+	if len(reverted) != 2 || reverted[0] != "charge" || reverted[1] != "reserve" {
+		t.Errorf("expected the stages to be reverted in reverse order, got %v", reverted)
+	}
+}
+
+func TestPipelineErrors(t *testing.T) {
+	if _, err := New(context.Background()).Build(); !errors.Is(err, ErrEmpty) {
+		t.Errorf("expected error %v, got %v", ErrEmpty, err)
+	}
+
+	noop := func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, nil
+	}
+	if _, err := New(context.Background()).OnError(noop).Then(noop).Build(); !errors.Is(err, ErrNoStage) {
+		t.Errorf("expected error %v, got %v", ErrNoStage, err)
+	}
+
+	b := New(context.Background()).Then(noop)
+	tasks, err := b.Build()
+	if err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	tasks[0].Seal()
+	if _, err := b.Then(noop).Build(); !errors.Is(err, task.ErrSealed) {
+		t.Errorf("expected error %v, got %v", task.ErrSealed, err)
+	}
+}
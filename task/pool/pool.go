@@ -0,0 +1,154 @@
+// Package pool provides a long-lived set of worker goroutines executing task graphs, so services running thousands of
+// small task graphs per second don't start and stop goroutines for every run.
+package pool
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/codecreationlabs/async/task"
+)
+
+// ErrClosed is returned by the task.Future of a task graph submitted to a Pool that was closed.
+var ErrClosed = errors.New("pool closed")
+
+// ConfigFunc represents a function that can be used to configure a Pool. It takes a pointer to a Pool as its parameter and sets various fields of it.
+type ConfigFunc func(p *Pool)
+
+// WithRunner returns a ConfigFunc that makes the Pool execute task graphs with r instead of a Runner with default options.
+func WithRunner(r *task.Runner) ConfigFunc {
+	return func(p *Pool) {
+		p.runner = r
+	}
+}
+
+// WithQueueSize returns a ConfigFunc that lets up to n task graphs wait for a free worker before Submit blocks.
+// The default queue size is the number of workers.
+func WithQueueSize(n int) ConfigFunc {
+	return func(p *Pool) {
+		p.queueSize = n
+	}
+}
+
+// job is a task graph waiting for a worker.
+type job struct {
+	ctx     context.Context
+	tasks   []*task.Task
+	values  []interface{}
+	resolve task.ResolveFunc
+}
+
+// Pool executes submitted task graphs on a fixed number of worker goroutines. Every worker executes one task graph at a time,
+// so at most size graphs run at the same time. Tasks sharing a serial key, see task.WithSerialKey, run one at a time
+// across all graphs of the Pool in submission order. A Pool is safe for concurrent use.
+type Pool struct {
+	runner    *task.Runner
+	queueSize int
+	serial    *task.SerialQueue
+
+	jobs   chan job
+	wg     sync.WaitGroup
+	submit sync.Mutex // keeps the order of serial key reservations and queued jobs in sync
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// New creates a new Pool with size workers, at least one, and applies the given configuration functions.
+// The workers run until Close is called.
+//
+// Example usage:
+//
+//	p := pool.New(runtime.NumCPU(), pool.WithRunner(task.NewRunner(task.WithRetryBudget(10))))
+//	defer p.Close()
+//
+//	f := p.Submit(createUser, sendWelcomeMail)
+//	results, err := f.Await(ctx)
+func New(size int, cfgs ...ConfigFunc) *Pool {
+	size = max(size, 1)
+
+	p := &Pool{
+		queueSize: size,
+	}
+
+	for _, cfg := range cfgs {
+		cfg(p)
+	}
+
+	if p.runner == nil {
+		p.runner = task.NewRunner()
+	}
+	if p.runner.Options.SerialQueue == nil {
+		// copy the runner, so a runner shared with others doesn't start using the queue of the pool
+		r := *p.runner
+		r.Options.SerialQueue = task.NewSerialQueue()
+		p.runner = &r
+	}
+	p.serial = p.runner.Options.SerialQueue
+	p.jobs = make(chan job, max(p.queueSize, 0))
+
+	p.wg.Add(size)
+	for i := 0; i < size; i++ {
+		go p.work()
+	}
+
+	return p
+}
+
+// work executes task graphs until the Pool is closed.
+func (p *Pool) work() {
+	defer p.wg.Done()
+
+	for j := range p.jobs {
+		j.resolve(p.runner.RunCtx(j.ctx, j.tasks, j.values...))
+	}
+}
+
+// Submit queues the task graph made of tasks and their subtasks for execution and returns a task.Future for its results.
+// It blocks while the queue is full. See SubmitCtx for passing initial values and a context.
+func (p *Pool) Submit(tasks ...*task.Task) *task.Future {
+	return p.SubmitCtx(context.Background(), tasks)
+}
+
+// SubmitCtx queues the task graph like Submit, but runs it with ctx like task.Runner.RunCtx and passes values to it.
+// If ctx is done while SubmitCtx is blocked on a full queue, the task.Future fails with a *task.CancelledError.
+func (p *Pool) SubmitCtx(ctx context.Context, tasks []*task.Task, values ...interface{}) *task.Future {
+	f, resolve := task.NewFuture()
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		resolve(nil, ErrClosed)
+		return f
+	}
+
+	p.submit.Lock()
+	defer p.submit.Unlock()
+
+	ctx = p.serial.Reserve(ctx, tasks)
+
+	select {
+	case p.jobs <- job{ctx: ctx, tasks: tasks, values: values, resolve: resolve}:
+	case <-ctx.Done():
+		p.serial.Release(ctx)
+		resolve(nil, task.CheckCancelled(ctx))
+	}
+	return f
+}
+
+// Close stops accepting new task graphs and waits until the queued and running graphs completed.
+// Task graphs submitted after Close fail with ErrClosed.
+func (p *Pool) Close() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+	close(p.jobs)
+	p.mu.Unlock()
+
+	p.wg.Wait()
+}
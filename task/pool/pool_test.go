@@ -0,0 +1,164 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/codecreationlabs/async/task"
+)
+
+func TestPool(t *testing.T) {
+	p := New(2)
+	defer p.Close()
+
+	var running, peak atomic.Int64
+	futures := make([]*task.Future, 0, 10)
+	for i := 0; i < 10; i++ {
+		i := i
+		futures = append(futures, p.Submit(task.New(context.Background(), task.WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				old := peak.Load()
+				if n <= old || peak.CompareAndSwap(old, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			return i, nil
+		}))))
+	}
+
+	for i, f := range futures {
+		results, err := f.Await(context.Background())
+		if err != nil {
+			t.Fatalf("didnt expect error, got %v", err)
+		}
+		if results.Values()[0] != i {
+			t.Errorf("expected output %d, got %v", i, results.Values()[0])
+		}
+	}
+	if peak.Load() > 2 {
+		t.Errorf("expected at most %d graphs to run at the same time, got %d", 2, peak.Load())
+	}
+}
+
+func TestPoolSubmitCtx(t *testing.T) {
+	p := New(1)
+	defer p.Close()
+
+	f := p.SubmitCtx(context.Background(), []*task.Task{task.New(context.Background(), task.WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return values[0], nil
+	}))}, "foo")
+
+	<-f.Done()
+	results, err := f.Await(context.Background())
+	if err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if results.Values()[0] != "foo" {
+		t.Errorf("expected output %q, got %v", "foo", results.Values()[0])
+	}
+}
+
+func TestPoolError(t *testing.T) {
+	p := New(1)
+	defer p.Close()
+
+	f := p.Submit(task.New(context.Background(), task.WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, errors.New("foobar")
+	})))
+	if _, err := f.Await(context.Background()); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestPoolAwaitCancelled(t *testing.T) {
+	p := New(1)
+	defer p.Close()
+
+	release := make(chan struct{})
+	f := p.Submit(task.New(context.Background(), task.WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		<-release
+		return nil, nil
+	})))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := f.Await(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected await to be cancelled, got %v", err)
+	}
+
+	close(release)
+	if _, err := f.Await(context.Background()); err != nil {
+		t.Errorf("didnt expect error, got %v", err)
+	}
+}
+
+func TestPoolClose(t *testing.T) {
+	p := New(1)
+
+	var ran atomic.Bool
+	f := p.Submit(task.New(context.Background(), task.WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		time.Sleep(time.Millisecond)
+		ran.Store(true)
+		return nil, nil
+	})))
+	p.Close()
+
+	if !ran.Load() {
+		t.Error("expected close to wait for queued graphs")
+	}
+	if _, err := f.Await(context.Background()); err != nil {
+		t.Errorf("didnt expect error, got %v", err)
+	}
+
+	f = p.Submit(task.New(context.Background()))
+	if _, err := f.Await(context.Background()); !errors.Is(err, ErrClosed) {
+		t.Errorf("expected a closed pool error, got %v", err)
+	}
+}
+
+func TestPoolSerialKey(t *testing.T) {
+	p := New(4)
+	defer p.Close()
+
+	var mu sync.Mutex
+	var order []int
+	var running, overlaps atomic.Int64
+
+	futures := make([]*task.Future, 0, 20)
+	for i := 0; i < 20; i++ {
+		i := i
+		futures = append(futures, p.Submit(task.New(context.Background(), task.WithSerialKey("account-1"), task.WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+			if running.Add(1) > 1 {
+				overlaps.Add(1)
+			}
+			defer running.Add(-1)
+
+			mu.Lock()
+			order = append(order, i)
+			mu.Unlock()
+			return nil, nil
+		}))))
+	}
+
+	for _, f := range futures {
+		if _, err := f.Await(context.Background()); err != nil {
+			t.Fatalf("didnt expect error, got %v", err)
+		}
+	}
+
+	if overlaps.Load() > 0 {
+		t.Errorf("expected tasks sharing a key to run one at a time, got %d overlaps", overlaps.Load())
+	}
+	for i, v := range order {
+		if v != i {
+			t.Fatalf("expected tasks in submission order, got %v", order)
+		}
+	}
+}
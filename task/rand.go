@@ -0,0 +1,82 @@
+package task
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math/rand"
+	"sync"
+)
+
+// WithSeed returns a RunnerConfigFunc that seeds the random sources returned by TaskContext.Rand with seed in every run,
+// so randomized behavior inside tasks, e.g. jitter or sampling, can be reproduced while replaying or debugging a run.
+// Without a seed, or with a seed of zero, every run picks a random seed. The seed of a run is available with Results.Seed
+// and on the "task started" event of the logger.
+//
+// Example usage:
+//
+//	results, err := task.NewRunner().Run(tasks)
+//	// ... later, replay the run with the same random numbers
+//	results, err = task.NewRunner(task.WithSeed(results.Seed())).Run(tasks)
+func WithSeed(seed int64) RunnerConfigFunc {
+	return func(o *RunOptions) {
+		o.Seed = seed
+	}
+}
+
+// newSeed returns the seed of a run, the seed of the Runner if it has one, a random one otherwise.
+func newSeed(seed int64) int64 {
+	for seed == 0 {
+		seed = rand.Int63()
+	}
+	return seed
+}
+
+// taskRand lazily creates the random source of a single execution of a task.
+type taskRand struct {
+	once sync.Once
+	seed int64
+	rand *rand.Rand
+}
+
+// Rand returns the random source of the current execution of the task. It is seeded with the seed of the run and the name of the task,
+// or its ID if it has no name, so a task draws the same numbers whenever it runs with the same seed, regardless of the order
+// in which the tasks of the run are scheduled. Retries of the task share the source. Outside of a run, Rand returns a randomly seeded source.
+//
+// The returned source is not safe for concurrent use, goroutines started by the task need sources of their own.
+//
+// Example usage:
+//
+//	func fetch(ctx context.Context, values ...interface{}) (interface{}, error) {
+//		jitter := time.Duration(task.MustDecodeCtx(ctx).Rand().Int63n(int64(time.Second)))
+//		// ...
+//	}
+func (tc *TaskContext) Rand() *rand.Rand {
+	if tc.rand == nil {
+		return rand.New(rand.NewSource(rand.Int63()))
+	}
+
+	tc.rand.once.Do(func() {
+		tc.rand.rand = rand.New(rand.NewSource(tc.rand.seed))
+	})
+	return tc.rand.rand
+}
+
+// taskContext returns the TaskContext of a single execution of the task, carrying the random source of the task in the run.
+func (s *run) taskContext(task *Task) *TaskContext {
+	tc := &TaskContext{
+		Task: task,
+		rand: &taskRand{seed: taskSeed(s.seed, task)},
+	}
+	if orig, err := DecodeCtx(task.Context); err == nil {
+		tc.Parent = orig.Parent
+	}
+	return tc
+}
+
+// taskSeed derives the seed of the random source of a task from the seed of the run.
+func taskSeed(seed int64, task *Task) int64 {
+	h := fnv.New64a()
+	_ = binary.Write(h, binary.LittleEndian, seed)
+	h.Write([]byte(checkpointKey(task)))
+	return int64(h.Sum64())
+}
@@ -0,0 +1,110 @@
+package task
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// drawingTasks creates named tasks drawing three random numbers each and recording them in draws.
+func drawingTasks(mu *sync.Mutex, draws map[string][]int64, names ...string) []*Task {
+	tasks := make([]*Task, 0, len(names))
+	for _, name := range names {
+		name := name
+		tasks = append(tasks, New(context.Background(), WithName(name), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+			r := MustDecodeCtx(ctx).Rand()
+			got := []int64{r.Int63(), r.Int63(), r.Int63()}
+
+			mu.Lock()
+			defer mu.Unlock()
+			draws[name] = got
+			return nil, nil
+		})))
+	}
+	return tasks
+}
+
+func TestRandReproducible(t *testing.T) {
+	var mu sync.Mutex
+	first := map[string][]int64{}
+	results, err := NewRunner(WithConcurrency(4)).Run(drawingTasks(&mu, first, "a", "b", "c"))
+	if err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if results.Seed() == 0 {
+		t.Fatal("expected the run to record its seed")
+	}
+
+	replay := map[string][]int64{}
+	if _, err := NewRunner(WithSeed(results.Seed())).Run(drawingTasks(&mu, replay, "c", "b", "a")); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if !reflect.DeepEqual(first, replay) {
+		t.Errorf("expected the replay to draw %v, got %v", first, replay)
+	}
+	if reflect.DeepEqual(first["a"], first["b"]) {
+		t.Errorf("expected tasks to draw from sources of their own, got %v", first)
+	}
+}
+
+func TestRandSeedPerRun(t *testing.T) {
+	var mu sync.Mutex
+	runner := NewRunner()
+
+	first, second := map[string][]int64{}, map[string][]int64{}
+	r1, err := runner.Run(drawingTasks(&mu, first, "a"))
+	if err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	r2, err := runner.Run(drawingTasks(&mu, second, "a"))
+	if err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+
+	if r1.Seed() == r2.Seed() {
+		t.Errorf("expected every run to pick a seed of its own, got %d twice", r1.Seed())
+	}
+	if reflect.DeepEqual(first, second) {
+		t.Errorf("expected runs with different seeds to draw different numbers, got %v", first)
+	}
+}
+
+func TestRandWithSeed(t *testing.T) {
+	var mu sync.Mutex
+	results, err := NewRunner(WithSeed(42)).Run(drawingTasks(&mu, map[string][]int64{}, "a"))
+	if err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if results.Seed() != 42 {
+		t.Errorf("expected seed 42, got %d", results.Seed())
+	}
+}
+
+func TestRandSubtaskKeepsParent(t *testing.T) {
+	var parent *Task
+	root := New(context.Background(), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, nil
+	}))
+	sub := New(context.Background(), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		tc := MustDecodeCtx(ctx)
+		parent = tc.Parent
+		tc.Rand().Int63()
+		return nil, nil
+	}))
+	root.AddSubtasks(sub)
+
+	if _, err := Run([]*Task{root}); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if parent != root {
+		t.Errorf("expected the parent of the subtask to be %s, got %v", root.ID, parent)
+	}
+}
+
+func TestRandOutsideRun(t *testing.T) {
+	tc := MustDecodeCtx(New(context.Background()).Context)
+	if tc.Rand() == nil {
+		t.Error("expected a random source outside of a run")
+	}
+}
@@ -0,0 +1,48 @@
+package task
+
+import (
+	"context"
+	"reflect"
+)
+
+// ReduceFunc folds a single value into the accumulated value of a Reduce task.
+type ReduceFunc[Acc, In any] func(ctx context.Context, acc Acc, value In) (Acc, error)
+
+// Reduce returns a task that folds the values it receives into a single value, starting with initial, and outputs the result,
+// making the fan-in after a fan-out explicit. Declared with DependsOn, the task receives the outputs of its dependencies in the order
+// they were declared. Values that are nil, e.g. the outputs of skipped dependencies, are left out. Any other value that is not an In
+// fails the task with a *TypeError. The cfgs configure the returned task.
+//
+// Example usage:
+//
+//	total := task.Reduce(ctx, func(ctx context.Context, sum int, invoice Invoice) (int, error) {
+//		return sum + invoice.Amount, nil
+//	}, 0, task.WithName("total"))
+//	if err := total.DependsOn(invoiceEU, invoiceUS, invoiceAPAC); err != nil {
+//		return err
+//	}
+func Reduce[Acc, In any](ctx context.Context, f ReduceFunc[Acc, In], initial Acc, cfgs ...TaskConfigFunc) *Task {
+	run := func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		acc := initial
+		for _, v := range values {
+			if v == nil {
+				continue
+			}
+			in, ok := v.(In)
+			if !ok {
+				terr := &TypeError{Expected: reflect.TypeOf((*In)(nil)).Elem()}
+				if tc, err := DecodeCtx(ctx); err == nil && tc.Task != nil {
+					terr.TaskID = tc.Task.ID
+				}
+				return nil, terr
+			}
+
+			var err error
+			if acc, err = f(ctx, acc, in); err != nil {
+				return nil, err
+			}
+		}
+		return acc, nil
+	}
+	return New(ctx, append([]TaskConfigFunc{WithFunc(run)}, cfgs...)...)
+}
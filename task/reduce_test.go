@@ -0,0 +1,50 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestReduce(t *testing.T) {
+	ctx := context.Background()
+	value := func(v interface{}, cfgs ...TaskConfigFunc) *Task {
+		return New(ctx, append(cfgs, WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+			return v, nil
+		}))...)
+	}
+	skipped := value(100, WithCondition(func(ctx context.Context, values ...interface{}) (bool, error) {
+		return false, nil
+	}))
+
+	total := Reduce(ctx, func(ctx context.Context, sum int, n int) (int, error) {
+		return sum + n, nil
+	}, 10, WithName("total"))
+	first, second := value(1), value(2)
+	total.DependsOn(first, skipped, second)
+
+	results, err := Run([]*Task{first, skipped, second})
+	if err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if v, _ := results.Get("total"); v != 13 {
+		t.Errorf("expected %d, got %v", 13, v)
+	}
+}
+
+func TestReduceTypeError(t *testing.T) {
+	ctx := context.Background()
+	text := New(ctx, WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return "foobar", nil
+	}))
+	total := Reduce(ctx, func(ctx context.Context, sum int, n int) (int, error) {
+		return sum + n, nil
+	}, 0)
+	total.DependsOn(text)
+
+	_, err := Run([]*Task{text})
+	var terr *TypeError
+	if !errors.As(err, &terr) || terr.TaskID != total.ID {
+		t.Errorf("expected a *TypeError of task %s, got %v", total.ID, err)
+	}
+}
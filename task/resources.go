@@ -0,0 +1,143 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ResourcePool accounts for named, scarce resources like GPUs or licenses, e.g. {"gpu": 2}. Tasks declare the slots they need with WithResources
+// and only run once all of them are free. It is safe for concurrent use and can be shared by multiple Runners.
+type ResourcePool struct {
+	mu       sync.Mutex
+	capacity map[string]int
+	free     map[string]int
+	changed  chan struct{} // closed and replaced whenever slots are released
+}
+
+// NewResourcePool creates a new ResourcePool with the given number of slots per resource.
+//
+// Example usage:
+//
+//	resources := task.NewResourcePool(map[string]int{"gpu": 2})
+//	runner := task.NewRunner(task.WithResourcePool(resources), task.WithConcurrency(8))
+//
+//	train := task.New(ctx, task.WithFunc(trainModel), task.WithResources(map[string]int{"gpu": 1}))
+func NewResourcePool(capacity map[string]int) *ResourcePool {
+	p := &ResourcePool{
+		capacity: make(map[string]int, len(capacity)),
+		free:     make(map[string]int, len(capacity)),
+		changed:  make(chan struct{}),
+	}
+	for name, n := range capacity {
+		p.capacity[name] = n
+		p.free[name] = n
+	}
+	return p
+}
+
+// Free returns the number of free slots of the resource.
+func (p *ResourcePool) Free(name string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.free[name]
+}
+
+// Acquire blocks until all slots in needs are free and takes them at once, or until ctx is done. In the latter case it returns the result of CheckCancelled.
+// Slots are taken all or nothing, so tasks needing several resources never hold some of them while waiting for the others.
+// Acquire fails right away if needs exceeds the capacity of the pool. Every successful call to Acquire must be followed by a call to Release.
+func (p *ResourcePool) Acquire(ctx context.Context, needs map[string]int) error {
+	for {
+		p.mu.Lock()
+		if err := p.check(needs); err != nil {
+			p.mu.Unlock()
+			return err
+		}
+
+		available := true
+		for name, n := range needs {
+			if p.free[name] < n {
+				available = false
+				break
+			}
+		}
+		if available {
+			for name, n := range needs {
+				p.free[name] -= n
+			}
+			p.mu.Unlock()
+			return nil
+		}
+
+		changed := p.changed
+		p.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return CheckCancelled(ctx)
+		}
+	}
+}
+
+// Release frees the slots acquired by Acquire.
+func (p *ResourcePool) Release(needs map[string]int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for name, n := range needs {
+		p.free[name] += n
+	}
+
+	close(p.changed)
+	p.changed = make(chan struct{})
+}
+
+// check returns an error if needs can never be satisfied by the pool.
+func (p *ResourcePool) check(needs map[string]int) error {
+	names := make([]string, 0, len(needs))
+	for name := range needs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if n := needs[name]; n > p.capacity[name] {
+			return fmt.Errorf("task needs %d slots of resource %q, pool has %d", n, name, p.capacity[name])
+		}
+	}
+	return nil
+}
+
+// WithResources returns a TaskConfigFunc that declares the slots of named resources the task needs, e.g. {"gpu": 1}.
+// When the Runner has a ResourcePool, the task only runs once all slots are free. The slots are held while the task runs,
+// including its retries, and while its Revert function runs. Without a ResourcePool, the declaration has no effect.
+func WithResources(needs map[string]int) TaskConfigFunc {
+	return func(t *Task) {
+		t.Resources = needs
+	}
+}
+
+// WithResourcePool returns a RunnerConfigFunc that runs tasks declaring resources with WithResources only when p has free slots for them.
+func WithResourcePool(p *ResourcePool) RunnerConfigFunc {
+	return func(o *RunOptions) {
+		o.Resources = p
+	}
+}
+
+// acquireResources takes the resource slots needed by the task, if the Runner has a ResourcePool.
+func (s *run) acquireResources(ctx context.Context, task *Task) error {
+	if s.runner.Options.Resources == nil || len(task.Resources) == 0 {
+		return nil
+	}
+	return s.runner.Options.Resources.Acquire(ctx, task.Resources)
+}
+
+// releaseResources frees the resource slots taken by acquireResources.
+func (s *run) releaseResources(task *Task) {
+	if s.runner.Options.Resources == nil || len(task.Resources) == 0 {
+		return
+	}
+	s.runner.Options.Resources.Release(task.Resources)
+}
@@ -0,0 +1,75 @@
+package task
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithResources(t *testing.T) {
+	resources := NewResourcePool(map[string]int{"gpu": 2})
+
+	var running, peak atomic.Int64
+	fn := func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			old := peak.Load()
+			if n <= old || peak.CompareAndSwap(old, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		return nil, nil
+	}
+
+	tasks := make([]*Task, 0, 8)
+	for i := 0; i < 8; i++ {
+		tasks = append(tasks, New(context.Background(), WithFunc(fn), WithResources(map[string]int{"gpu": 1})))
+	}
+
+	if _, err := NewRunner(WithResourcePool(resources), WithConcurrency(8)).Run(tasks); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if peak.Load() > 2 {
+		t.Errorf("expected at most %d tasks to hold a gpu at the same time, got %d", 2, peak.Load())
+	}
+	if resources.Free("gpu") != 2 {
+		t.Errorf("expected all slots to be released, got %d free", resources.Free("gpu"))
+	}
+}
+
+func TestResourcePoolAllOrNothing(t *testing.T) {
+	resources := NewResourcePool(map[string]int{"gpu": 2, "license": 1})
+
+	if err := resources.Acquire(context.Background(), map[string]int{"license": 1}); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := resources.Acquire(ctx, map[string]int{"gpu": 1, "license": 1}); err == nil {
+		t.Fatal("expected acquire to wait for the license")
+	}
+	if resources.Free("gpu") != 2 {
+		t.Error("didnt expect a gpu to be held while waiting for the license")
+	}
+
+	resources.Release(map[string]int{"license": 1})
+	if err := resources.Acquire(context.Background(), map[string]int{"gpu": 1, "license": 1}); err != nil {
+		t.Errorf("didnt expect error, got %v", err)
+	}
+}
+
+func TestResourcePoolExceedsCapacity(t *testing.T) {
+	resources := NewResourcePool(map[string]int{"gpu": 2})
+
+	task := New(context.Background(), WithResources(map[string]int{"gpu": 3}), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		t.Error("task should not run")
+		return nil, nil
+	}))
+	if _, err := NewRunner(WithResourcePool(resources)).Run([]*Task{task}); err == nil {
+		t.Error("expected an error")
+	}
+}
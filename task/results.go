@@ -0,0 +1,181 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// ErrResultNotFound is returned by GetAs when no task with the requested name succeeded.
+var ErrResultNotFound = errors.New("result not found")
+
+// Results holds the outputs of the tasks of a run in completion order and gives access to the outputs of named tasks,
+// so callers and tasks don't depend on positional indexing. Results is safe for concurrent use, tasks can read
+// the results of the run they are part of with ResultsFromCtx while it is running.
+type Results struct {
+	mu     sync.RWMutex
+	values []interface{}
+	names  []string
+	named  map[string]interface{}
+	seed   int64
+	runID  string
+}
+
+// newResults creates an empty Results with room for n outputs.
+func newResults(n int) *Results {
+	return &Results{
+		values: make([]interface{}, 0, n),
+		names:  make([]string, 0, n),
+		named:  map[string]interface{}{},
+	}
+}
+
+// add records the output of a task that succeeded.
+func (r *Results) add(t *Task, val interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.values = append(r.values, val)
+	r.names = append(r.names, t.Name)
+	if t.Name != "" {
+		r.named[t.Name] = val
+	}
+}
+
+// Seed returns the seed of the run the results belong to, see WithSeed.
+func (r *Results) Seed() int64 {
+	if r == nil {
+		return 0
+	}
+	return r.seed
+}
+
+// RunID returns the ID of the run the results belong to. Runs are numbered by their Runner, "run_0", "run_1" and so on,
+// so the ID is unique within the Runner only. It is empty for runs of a Runner not created with NewRunner.
+func (r *Results) RunID() string {
+	if r == nil {
+		return ""
+	}
+	return r.runID
+}
+
+// Values returns the outputs of all tasks that succeeded, in completion order. Outputs dropped by the retention policy of the run are missing,
+// see WithResultRetention.
+func (r *Results) Values() []interface{} {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]interface{}(nil), r.values...)
+}
+
+// Names returns the names of all named tasks that succeeded, in completion order.
+func (r *Results) Names() []string {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.named))
+	for _, name := range r.names {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Len returns the number of outputs returned by Values.
+func (r *Results) Len() int {
+	if r == nil {
+		return 0
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.values)
+}
+
+// Get returns the output of the task with the given name. The second return value is false if no task with that name succeeded.
+func (r *Results) Get(name string) (interface{}, bool) {
+	if r == nil {
+		return nil, false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	val, ok := r.named[name]
+	return val, ok
+}
+
+// GetAs returns the output of the task with the given name as a T. It returns an error wrapping ErrResultNotFound if
+// no task with that name succeeded, and a *ResultTypeError if the output is not a T.
+//
+// Example usage:
+//
+//	results, err := task.Run([]*task.Task{createUser})
+//	if err != nil {
+//		return err
+//	}
+//	user, err := task.GetAs[User](results, "create-user")
+func GetAs[T any](r *Results, name string) (T, error) {
+	var zero T
+
+	val, ok := r.Get(name)
+	if !ok {
+		return zero, fmt.Errorf("%w: %q", ErrResultNotFound, name)
+	}
+
+	t, ok := val.(T)
+	if !ok {
+		return zero, &ResultTypeError{
+			Name:     name,
+			Actual:   reflect.TypeOf(val),
+			Expected: reflect.TypeOf((*T)(nil)).Elem(),
+		}
+	}
+	return t, nil
+}
+
+// ResultTypeError is returned by GetAs when the output of the task with the requested name is not of the requested type.
+// Actual is nil if the task output nil.
+type ResultTypeError struct {
+	Name     string
+	Actual   reflect.Type
+	Expected reflect.Type
+}
+
+// Error implements the error interface.
+func (e *ResultTypeError) Error() string {
+	return fmt.Sprintf("result %q has type %v, not %s", e.Name, e.Actual, e.Expected)
+}
+
+// ResultsFromCtx returns the Results of the run the task owning ctx is part of, or nil if ctx doesn't belong to a running task.
+//
+// Example usage:
+//
+//	func(ctx context.Context, values ...interface{}) (interface{}, error) {
+//		user, err := task.GetAs[User](task.ResultsFromCtx(ctx), "create-user")
+//		if err != nil {
+//			return nil, err
+//		}
+//		...
+//	}
+func ResultsFromCtx(ctx context.Context) *Results {
+	r, _ := ctx.Value(CtxKey("results")).(*Results)
+	return r
+}
+
+// WithName returns a TaskConfigFunc that sets the name of the task. The output of a named task can be fetched by name from Results.
+// Names have to be unique within a run.
+func WithName(name string) TaskConfigFunc {
+	return func(t *Task) {
+		t.Name = name
+	}
+}
@@ -0,0 +1,98 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type namedUser struct {
+	ID string
+}
+
+func TestNamedResults(t *testing.T) {
+	ctx := context.Background()
+
+	create := New(ctx, WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return namedUser{ID: "foobar"}, nil
+	}), WithName("create-user"))
+
+	process := New(ctx, WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		user, err := GetAs[namedUser](ResultsFromCtx(ctx), "create-user")
+		if err != nil {
+			return nil, err
+		}
+		return user.ID + "-processed", nil
+	}), WithName("process-user"))
+
+	create.AddSubtasks(process)
+
+	results, err := Run([]*Task{create})
+	if err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+
+	processed, err := GetAs[string](results, "process-user")
+	if err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if processed != "foobar-processed" {
+		t.Errorf("expected %q, got %q", "foobar-processed", processed)
+	}
+
+	if _, err := GetAs[string](results, "missing"); !errors.Is(err, ErrResultNotFound) {
+		t.Errorf("expected ErrResultNotFound, got %v", err)
+	}
+
+	var terr *ResultTypeError
+	if _, err := GetAs[int](results, "create-user"); !errors.As(err, &terr) {
+		t.Fatalf("expected a ResultTypeError, got %v", err)
+	}
+	if expected := `result "create-user" has type task.namedUser, not int`; terr.Error() != expected {
+		t.Errorf("expected %q, got %q", expected, terr.Error())
+	}
+}
+
+func TestDuplicateNames(t *testing.T) {
+	ctx := context.Background()
+	noop := WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, nil
+	})
+
+	a := New(ctx, noop, WithName("step"))
+	a.AddSubtasks(New(ctx, noop, WithName("step")))
+
+	if _, err := Run([]*Task{a}); err == nil {
+		t.Error("expected an error for duplicate task names")
+	}
+}
+
+func TestNilResults(t *testing.T) {
+	var results *Results
+	if results.Len() != 0 || results.Values() != nil {
+		t.Error("expected nil results to be empty")
+	}
+	if _, ok := results.Get("foo"); ok {
+		t.Error("didnt expect a result")
+	}
+}
+
+func TestResultNames(t *testing.T) {
+	ctx := context.Background()
+	noop := WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, nil
+	})
+
+	a := New(ctx, noop, WithName("a"))
+	a.AddSubtasks(New(ctx, noop), New(ctx, noop, WithName("c")))
+
+	results, err := Run([]*Task{a})
+	if err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+
+	names := results.Names()
+	if len(names) != 2 || names[0] != "a" || names[1] != "c" {
+		t.Errorf("expected names [a c], got %v", names)
+	}
+}
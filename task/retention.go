@@ -0,0 +1,77 @@
+package task
+
+import "fmt"
+
+// RetentionPolicy decides which outputs a run keeps after the task producing them completed, see WithResultRetention.
+type RetentionPolicy int
+
+const (
+	// KeepAll keeps every output: tasks without dependencies receive the outputs of all tasks that completed before them
+	// and the Results of the run hold every output. It is the default.
+	KeepAll RetentionPolicy = iota
+	// KeepNamed keeps the outputs of named tasks in the Results of the run only. Tasks without dependencies receive the initial
+	// values of the run and pull the outputs they need with ResultsFromCtx or declare them with DependsOn.
+	KeepNamed
+	// KeepNone keeps no outputs in the Results of the run. Tasks receive the initial values of the run or the outputs of the tasks
+	// they declared with DependsOn.
+	KeepNone
+)
+
+// String returns a human readable representation of the RetentionPolicy.
+func (p RetentionPolicy) String() string {
+	switch p {
+	case KeepAll:
+		return "keep all"
+	case KeepNamed:
+		return "keep named"
+	case KeepNone:
+		return "keep none"
+	default:
+		return fmt.Sprintf("RetentionPolicy(%d)", int(p))
+	}
+}
+
+// WithResultRetention returns a RunnerConfigFunc that sets the policy deciding which outputs a run keeps, so graphs with thousands
+// of tasks don't hold on to every intermediate output until the run returns. Regardless of the policy, the output of a task is passed
+// to the tasks depending on it and dropped once the last of them started. Revert functions only receive the retained outputs.
+//
+// Example usage:
+//
+//	runner := task.NewRunner(task.WithResultRetention(task.KeepNamed))
+//
+//	report := task.New(ctx, task.WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+//		total, err := task.GetAs[int](task.ResultsFromCtx(ctx), "total")
+//		...
+//	}))
+func WithResultRetention(policy RetentionPolicy) RunnerConfigFunc {
+	return func(o *RunOptions) {
+		o.Retention = policy
+	}
+}
+
+// consumers counts the tasks among nodes depending on each task, so outputs can be dropped once every consumer started.
+func consumers(nodes []*Task) map[*Task]int {
+	counts := make(map[*Task]int)
+	for _, t := range nodes {
+		for _, dep := range t.Dependencies {
+			counts[dep]++
+		}
+	}
+	return counts
+}
+
+// retain records the output of a task that succeeded according to policy.
+func (r *Results) retain(t *Task, val interface{}, policy RetentionPolicy) {
+	switch policy {
+	case KeepAll:
+		r.add(t, val)
+	case KeepNamed:
+		if t.Name == "" {
+			return
+		}
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.named[t.Name] = val
+		r.names = append(r.names, t.Name)
+	}
+}
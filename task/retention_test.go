@@ -0,0 +1,81 @@
+package task
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWithResultRetention(t *testing.T) {
+	tests := []struct {
+		policy   RetentionPolicy
+		values   int
+		named    bool
+		received int
+	}{
+		{policy: KeepAll, values: 3, named: true, received: 3},
+		{policy: KeepNamed, values: 0, named: true, received: 1},
+		{policy: KeepNone, values: 0, named: false, received: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy.String(), func(t *testing.T) {
+			ctx := context.Background()
+			value := func(v interface{}) TaskFunc {
+				return func(ctx context.Context, values ...interface{}) (interface{}, error) {
+					return v, nil
+				}
+			}
+
+			var received int
+			first := New(ctx, WithName("first"), WithFunc(value(1)))
+			second := New(ctx, WithFunc(value(2)))
+			last := New(ctx, WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+				received = len(values)
+				return nil, nil
+			}))
+			if err := first.AddSubtasks(second); err != nil {
+				t.Fatalf("didnt expect error, got %v", err)
+			}
+			if err := second.AddSubtasks(last); err != nil {
+				t.Fatalf("didnt expect error, got %v", err)
+			}
+
+			results, err := NewRunner(WithResultRetention(tt.policy)).Run([]*Task{first}, "input")
+			if err != nil {
+				t.Fatalf("didnt expect error, got %v", err)
+			}
+			if results.Len() != tt.values {
+				t.Errorf("expected %d values, got %d", tt.values, results.Len())
+			}
+			if _, ok := results.Get("first"); ok != tt.named {
+				t.Errorf("expected named output retained to be %v, got %v", tt.named, ok)
+			}
+			if received != tt.received {
+				t.Errorf("expected %d values passed to the last task, got %d", tt.received, received)
+			}
+		})
+	}
+}
+
+func TestWithResultRetentionDependencies(t *testing.T) {
+	ctx := context.Background()
+	source := New(ctx, WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return 42, nil
+	}))
+
+	var got []interface{}
+	sink := New(ctx, WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		got = values
+		return nil, nil
+	}))
+	if err := sink.DependsOn(source); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+
+	if _, err := NewRunner(WithResultRetention(KeepNone)).Run([]*Task{source, sink}); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if len(got) != 1 || got[0] != 42 {
+		t.Errorf("expected [42], got %v", got)
+	}
+}
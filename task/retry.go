@@ -0,0 +1,201 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrRetryBudgetExhausted is wrapped into the error of a task that was not retried because the retry budget of the run was used up.
+var ErrRetryBudgetExhausted = errors.New("retry budget exhausted")
+
+// BackoffStrategy computes the delay before the next attempt of a failed task. Backoff is called with the number
+// of the attempt that failed, starting at 1.
+type BackoffStrategy interface {
+	Backoff(attempt int) time.Duration
+}
+
+// BackoffFunc is an adapter to allow the use of ordinary functions as a BackoffStrategy.
+type BackoffFunc func(attempt int) time.Duration
+
+// Backoff calls f(attempt).
+func (f BackoffFunc) Backoff(attempt int) time.Duration {
+	return f(attempt)
+}
+
+// ConstantBackoff returns a BackoffStrategy that waits d before every retry.
+func ConstantBackoff(d time.Duration) BackoffStrategy {
+	return BackoffFunc(func(int) time.Duration {
+		return d
+	})
+}
+
+// ExponentialBackoff returns a BackoffStrategy that waits base before the first retry and doubles the delay for every
+// further retry, never waiting longer than maxDelay. A maxDelay of zero disables the upper bound, delays then saturate
+// at the longest representable duration instead of overflowing.
+func ExponentialBackoff(base, maxDelay time.Duration) BackoffStrategy {
+	return BackoffFunc(func(attempt int) time.Duration {
+		d := base
+		for i := 1; i < attempt && d > 0; i++ {
+			if d > math.MaxInt64/2 {
+				if maxDelay > 0 {
+					return maxDelay
+				}
+				return math.MaxInt64
+			}
+			d *= 2
+			if maxDelay > 0 && d >= maxDelay {
+				return maxDelay
+			}
+		}
+		if maxDelay > 0 && d > maxDelay {
+			return maxDelay
+		}
+		return d
+	})
+}
+
+// JitterBackoff returns a BackoffStrategy that waits a random duration between zero and the delay of s ("full jitter"),
+// which spreads retries of many tasks failing at the same time.
+func JitterBackoff(s BackoffStrategy) BackoffStrategy {
+	return BackoffFunc(func(attempt int) time.Duration {
+		d := s.Backoff(attempt)
+		if d <= 0 {
+			return 0
+		}
+		if d == math.MaxInt64 {
+			// a saturated delay, adding one would overflow
+			return time.Duration(rand.Int63n(int64(d)))
+		}
+		return time.Duration(rand.Int63n(int64(d) + 1))
+	})
+}
+
+// RetryPolicy describes how often a failing task is retried before the run is reverted.
+//
+// Members:
+// - Attempts: the maximum number of attempts including the first one, values below 2 disable retries
+// - Backoff: the strategy computing the delay between attempts, no delay if nil
+type RetryPolicy struct {
+	Attempts int
+	Backoff  BackoffStrategy
+}
+
+// WithRetry returns a TaskConfigFunc that retries the task up to attempts times in total before the failure triggers the revert of the run.
+// The delay between attempts is computed by backoff, unless the task returned an error created by RetryIn.
+// Cancelled tasks are never retried and retries count against the RetryBudget of the run.
+//
+// Example usage:
+//
+//	t := task.New(ctx, task.WithFunc(callPaymentProvider),
+//		task.WithRetry(5, task.JitterBackoff(task.ExponentialBackoff(100*time.Millisecond, 10*time.Second))))
+func WithRetry(attempts int, backoff BackoffStrategy) TaskConfigFunc {
+	return func(t *Task) {
+		t.Retry = RetryPolicy{
+			Attempts: attempts,
+			Backoff:  backoff,
+		}
+	}
+}
+
+// RetryAfterError is returned by a TaskFunc that knows when it is worth trying again, e.g. because a server answered
+// with a Retry-After header. When the task is retried, the next attempt is scheduled after Delay instead of
+// the delay computed by the backoff strategy of the task.
+type RetryAfterError struct {
+	Delay time.Duration
+	Err   error
+}
+
+// Error implements the error interface.
+func (e *RetryAfterError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("retry in %s", e.Delay)
+	}
+	return fmt.Sprintf("retry in %s: %v", e.Delay, e.Err)
+}
+
+// Unwrap returns the error that caused the task to fail.
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
+
+// RetryIn wraps err in a RetryAfterError that asks for the next attempt to be made after d.
+//
+// Example usage:
+//
+//	if resp.StatusCode == http.StatusTooManyRequests {
+//		return nil, task.RetryIn(30*time.Second, errors.New("rate limited"))
+//	}
+func RetryIn(d time.Duration, err error) error {
+	return &RetryAfterError{
+		Delay: d,
+		Err:   err,
+	}
+}
+
+// RetryAfter returns the delay requested by the first RetryAfterError in the chain of err.
+// The second return value is false if err does not contain a RetryAfterError.
+func RetryAfter(err error) (time.Duration, bool) {
+	var rerr *RetryAfterError
+	if !errors.As(err, &rerr) {
+		return 0, false
+	}
+	return rerr.Delay, true
+}
+
+// ParseRetryAfter parses the value of a Retry-After HTTP header, which is either a number of seconds or an HTTP date.
+// Dates are converted to a delay relative to now; dates in the past result in a delay of zero. Delays too long to be represented
+// saturate at the longest representable duration.
+// The second return value is false if the value could not be parsed.
+func ParseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		if seconds > int64(math.MaxInt64/time.Second) {
+			return math.MaxInt64, true
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	d := date.Sub(now)
+	if d < 0 {
+		d = 0
+	}
+	return d, true
+}
+
+// RetryAfterResponse wraps err in a RetryAfterError if resp carries a valid Retry-After header and returns err unchanged otherwise.
+// It is a convenience for TaskFuncs that call HTTP APIs answering with 429 or 503 status codes.
+//
+// Example usage:
+//
+//	if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+//		return nil, task.RetryAfterResponse(resp, fmt.Errorf("unexpected status %d", resp.StatusCode))
+//	}
+func RetryAfterResponse(resp *http.Response, err error) error {
+	if resp == nil {
+		return err
+	}
+
+	d, ok := ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	if !ok {
+		return err
+	}
+	return RetryIn(d, err)
+}
@@ -0,0 +1,209 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRetryIn(t *testing.T) {
+	cause := errors.New("rate limited")
+	err := RetryIn(time.Second, cause)
+
+	d, ok := RetryAfter(err)
+	if !ok {
+		t.Fatal("expected a retry after error")
+	}
+	if d != time.Second {
+		t.Errorf("expected delay of %s, got %s", time.Second, d)
+	}
+	if !errors.Is(err, cause) {
+		t.Error("expected error to wrap the cause")
+	}
+
+	if _, ok := RetryAfter(cause); ok {
+		t.Error("didnt expect a retry after error")
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value string
+		delay time.Duration
+		ok    bool
+	}{
+		{value: "120", delay: 2 * time.Minute, ok: true},
+		{value: " 0 ", delay: 0, ok: true},
+		{value: "Mon, 01 Jan 2024 12:00:30 GMT", delay: 30 * time.Second, ok: true},
+		{value: "Mon, 01 Jan 2024 11:00:00 GMT", delay: 0, ok: true},
+		{value: "9223372037", delay: math.MaxInt64, ok: true},
+		{value: "9223372036854775807", delay: math.MaxInt64, ok: true},
+		{value: "-1", ok: false},
+		{value: "soon", ok: false},
+		{value: "", ok: false},
+	}
+
+	for _, tt := range tests {
+		d, ok := ParseRetryAfter(tt.value, now)
+		if ok != tt.ok {
+			t.Errorf("%q: expected ok to be %v", tt.value, tt.ok)
+		}
+		if d != tt.delay {
+			t.Errorf("%q: expected delay of %s, got %s", tt.value, tt.delay, d)
+		}
+	}
+}
+
+func TestRetryAfterResponse(t *testing.T) {
+	cause := errors.New("service unavailable")
+
+	resp := &http.Response{Header: http.Header{}}
+	if err := RetryAfterResponse(resp, cause); err != cause {
+		t.Error("expected error to be returned unchanged without header")
+	}
+
+	resp.Header.Set("Retry-After", "5")
+	d, ok := RetryAfter(RetryAfterResponse(resp, cause))
+	if !ok || d != 5*time.Second {
+		t.Errorf("expected delay of %s, got %s", 5*time.Second, d)
+	}
+}
+
+func TestBackoffStrategies(t *testing.T) {
+	constant := ConstantBackoff(time.Second)
+	if constant.Backoff(1) != time.Second || constant.Backoff(10) != time.Second {
+		t.Error("expected constant delay")
+	}
+
+	exponential := ExponentialBackoff(100*time.Millisecond, time.Second)
+	expected := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second}
+	for i, d := range expected {
+		if got := exponential.Backoff(i + 1); got != d {
+			t.Errorf("attempt %d: expected delay of %s, got %s", i+1, d, got)
+		}
+	}
+
+	uncapped := ExponentialBackoff(time.Second, 0)
+	for _, attempt := range []int{64, 100, 1000} {
+		if got := uncapped.Backoff(attempt); got != math.MaxInt64 {
+			t.Errorf("attempt %d: expected delay to saturate at %s, got %s", attempt, time.Duration(math.MaxInt64), got)
+		}
+	}
+	if got := ExponentialBackoff(time.Second, time.Hour).Backoff(1000); got != time.Hour {
+		t.Errorf("expected delay to be capped at %s, got %s", time.Hour, got)
+	}
+
+	jitter := JitterBackoff(constant)
+	for i := 0; i < 100; i++ {
+		if d := jitter.Backoff(1); d < 0 || d > time.Second {
+			t.Fatalf("expected jittered delay within bounds, got %s", d)
+		}
+	}
+	if d := JitterBackoff(ExponentialBackoff(time.Second, 0)).Backoff(40); d < 0 {
+		t.Errorf("expected jittered saturated delay to be positive, got %s", d)
+	}
+}
+
+func TestWithRetry(t *testing.T) {
+	calls := 0
+	task := New(context.Background(), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		calls++
+		if calls < 3 {
+			return nil, errors.New("flaky")
+		}
+		return calls, nil
+	}), WithRetry(3, ConstantBackoff(time.Millisecond)))
+
+	result, err := Run([]*Task{task})
+	if err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if result.Values()[0] != 3 {
+		t.Errorf("expected task to succeed on attempt %d, got %v", 3, result.Values()[0])
+	}
+}
+
+func TestWithRetryExhausted(t *testing.T) {
+	reverted := false
+	root := New(context.Background(), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, nil
+	}), WithRevertFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		reverted = true
+		return nil, nil
+	}))
+
+	calls := 0
+	root.AddSubtasks(New(context.Background(), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		calls++
+		return nil, errors.New("down")
+	}), WithRetry(2, nil)))
+
+	if _, err := Run([]*Task{root}); err == nil {
+		t.Fatal("expected an error")
+	}
+	if calls != 2 {
+		t.Errorf("expected %d attempts, got %d", 2, calls)
+	}
+	if !reverted {
+		t.Error("expected run to be reverted after the last attempt")
+	}
+}
+
+func TestWithRetryHonorsRetryIn(t *testing.T) {
+	calls := 0
+	task := New(context.Background(), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		calls++
+		if calls == 1 {
+			return nil, RetryIn(time.Millisecond, errors.New("rate limited"))
+		}
+		return nil, nil
+	}), WithRetry(2, ConstantBackoff(time.Hour)))
+
+	start := time.Now()
+	if _, err := Run([]*Task{task}); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if time.Since(start) > time.Minute {
+		t.Error("expected retry to use the requested delay instead of the backoff")
+	}
+}
+
+func TestRetryBudgetExhausted(t *testing.T) {
+	calls := 0
+	task := New(context.Background(), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		calls++
+		return nil, errors.New("down")
+	}), WithRetry(10, nil))
+
+	_, err := NewRunner(WithRetryBudget(2)).Run([]*Task{task})
+	if !errors.Is(err, ErrRetryBudgetExhausted) {
+		t.Fatalf("expected the retry budget to be exhausted, got %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("expected %d attempts, got %d", 3, calls)
+	}
+}
+
+func TestRetryStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	calls := 0
+	task := New(ctx, WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		calls++
+		cancel()
+		return nil, errors.New("down")
+	}), WithRetry(5, ConstantBackoff(time.Hour)))
+
+	_, err := Run([]*Task{task})
+	if !errors.Is(err, ErrCancelledByCaller) {
+		t.Fatalf("expected the task to be cancelled, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected %d attempt, got %d", 1, calls)
+	}
+}
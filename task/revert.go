@@ -0,0 +1,218 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RevertFailure holds a task whose Revert function failed together with the error returned by the last attempt.
+// Attempts is the number of times the Revert function was called, see WithRevertRetry.
+type RevertFailure struct {
+	Task     *Task
+	Err      error
+	Attempts int
+}
+
+// RevertError aggregates the failures of Revert functions, so callers know which compensations failed and can retry them manually.
+// When returned by Run, Cause holds the error of the task that triggered the revert. The error matches Cause as well as every
+// revert failure with errors.Is and errors.As.
+//
+// Example usage:
+//
+//	_, err := task.Run(tasks)
+//
+//	var rerr *task.RevertError
+//	if errors.As(err, &rerr) {
+//		for _, failure := range rerr.Failures {
+//			log.Printf("compensation of %s failed: %v", failure.Task.ID, failure.Err)
+//		}
+//	}
+type RevertError struct {
+	Cause    error
+	Failures []RevertFailure
+}
+
+// Error implements the error interface.
+func (e *RevertError) Error() string {
+	var sb strings.Builder
+	if e.Cause != nil {
+		sb.WriteString(e.Cause.Error())
+		sb.WriteString("; ")
+	}
+
+	fmt.Fprintf(&sb, "revert failed for %d task(s)", len(e.Failures))
+	for i, failure := range e.Failures {
+		if i == 0 {
+			sb.WriteString(": ")
+		} else {
+			sb.WriteString(", ")
+		}
+		fmt.Fprintf(&sb, "%s: %v", failure.Task.ID, failure.Err)
+	}
+	return sb.String()
+}
+
+// Unwrap returns the cause followed by the errors of all revert failures.
+func (e *RevertError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Failures)+1)
+	if e.Cause != nil {
+		errs = append(errs, e.Cause)
+	}
+	for _, failure := range e.Failures {
+		errs = append(errs, failure.Err)
+	}
+	return errs
+}
+
+// Tasks returns the tasks whose Revert function failed.
+func (e *RevertError) Tasks() []*Task {
+	tasks := make([]*Task, 0, len(e.Failures))
+	for _, failure := range e.Failures {
+		tasks = append(tasks, failure.Task)
+	}
+	return tasks
+}
+
+// revertTask calls the Revert function of a single task, if it has one, retrying it according to the revert retry policy of the task.
+// It returns the number of attempts made and the error of the last attempt. See invoke for dump.
+func revertTask(ctx context.Context, task *Task, dump bool, values ...interface{}) (int, error) {
+	if task.Revert == nil {
+		return 0, nil
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := revertAttempt(ctx, task, values, dump)
+		if err == nil || attempt >= task.RevertRetry.Attempts || ctx.Err() != nil {
+			return attempt, err
+		}
+
+		delay, ok := RetryAfter(err)
+		if !ok && task.RevertRetry.Backoff != nil {
+			delay = task.RevertRetry.Backoff.Backoff(attempt)
+		}
+		if Sleep(ctx, delay) != nil {
+			return attempt, err
+		}
+	}
+}
+
+// revertAttempt calls the Revert function of a task once. If the task has a revert timeout, the function runs on its own goroutine
+// and is abandoned when the timeout expires, like the Run function of a task with a timeout.
+func revertAttempt(ctx context.Context, task *Task, values []interface{}, dump bool) error {
+	if task.RevertTimeout <= 0 {
+		return invokeRevert(ctx, task, values, dump)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, task.RevertTimeout)
+	defer cancel()
+
+	// buffered, so an abandoned function can still deliver its result and exit
+	done := make(chan error, 1)
+	go func() {
+		done <- invokeRevert(ctx, task, values, dump)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return newCancelledError(task, ctx)
+	}
+}
+
+// revertTask calls the Revert function of a single task of the run while holding its resource slots, within a span of its own if the Runner has a tracer,
+// and logs the outcome if the Runner has a logger.
+func (s *run) revertTask(task *Task, values ...interface{}) (int, error) {
+	if task.Revert == nil {
+		return 0, nil
+	}
+	s.emit(RevertStarted, task, nil)
+
+	ctx := s.baseContext(task)
+	var span trace.Span
+	if s.runner.Options.Tracer != nil {
+		ctx, span = s.startRevertSpan(ctx, task)
+	}
+
+	start := time.Now()
+	attempts := 0
+	err := s.acquireResources(ctx, task)
+	if err == nil {
+		attempts, err = revertTask(ctx, task, s.runner.Options.GoroutineDump, values...)
+		s.releaseResources(task)
+	}
+	if err == nil {
+		if ferr := s.forget(ctx, task); ferr != nil {
+			err = idempotencyError(task, ferr)
+		}
+	}
+
+	if span != nil {
+		endSpan(span, err)
+	}
+	s.logReverted(ctx, task, time.Since(start), err)
+	s.panicked(ctx, task, err)
+	return attempts, err
+}
+
+// revert reverts the tasks that succeeded in a failed run in the given order, followed by their subtasks that succeeded, like Revert.
+// Every task is reverted once; tasks that did not succeed and tasks kept for resuming the run are left alone.
+// It returns cause unchanged if every revert succeeded and a *RevertError wrapping cause otherwise.
+func (s *run) revert(cause error, tasks []*Task, values ...interface{}) error {
+	var failures []RevertFailure
+	seen := make(map[*Task]bool, len(tasks))
+	for len(tasks) > 0 {
+		task := tasks[0]
+		tasks = tasks[1:]
+		if seen[task] || s.kept[task] || task.Status() != Succeeded {
+			continue
+		}
+		seen[task] = true
+		tasks = append(tasks, task.Subtasks...)
+
+		attempts, err := s.revertTask(task, values...)
+		if err != nil {
+			failures = append(failures, RevertFailure{Task: task, Err: err, Attempts: attempts})
+		} else if task.Revert != nil {
+			task.setStatus(Reverted)
+		}
+		if m := s.runner.Options.Metrics; m != nil && task.Revert != nil {
+			m.TaskReverted(task, err)
+		}
+	}
+
+	if len(failures) > 0 {
+		return &RevertError{Cause: cause, Failures: failures}
+	}
+	return cause
+}
+
+// WithRevertRetry returns a TaskConfigFunc that retries the Revert function of the task up to attempts times in total,
+// waiting for the delay computed by backoff between attempts, unless the function returned an error created by RetryIn.
+// If the last attempt fails, the failure is reported in the *RevertError of the run together with the number of attempts.
+//
+// Example usage:
+//
+//	t := task.New(ctx, task.WithFunc(chargeCard), task.WithRevertFunc(refundCard),
+//		task.WithRevertRetry(5, task.ExponentialBackoff(time.Second, time.Minute)), task.WithRevertTimeout(10*time.Second))
+func WithRevertRetry(attempts int, backoff BackoffStrategy) TaskConfigFunc {
+	return func(t *Task) {
+		t.RevertRetry = RetryPolicy{
+			Attempts: attempts,
+			Backoff:  backoff,
+		}
+	}
+}
+
+// WithRevertTimeout returns a TaskConfigFunc that limits every attempt of the Revert function of the task to the duration d.
+// When an attempt does not return in time, its context is cancelled and the attempt fails with a *CancelledError
+// whose Reason is TimedOut. The attempt is retried if the task has a revert retry policy.
+func WithRevertTimeout(d time.Duration) TaskConfigFunc {
+	return func(t *Task) {
+		t.RevertTimeout = d
+	}
+}
@@ -0,0 +1,191 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRevertError(t *testing.T) {
+	ctx := context.Background()
+	compensationErr := errors.New("compensation failed")
+
+	var reverted []string
+	failingRevert := New(ctx, WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, nil
+	}), WithRevertFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, compensationErr
+	}))
+
+	okRevert := New(ctx, WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, nil
+	}), WithRevertFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		reverted = append(reverted, "ok")
+		return nil, nil
+	}))
+
+	taskErr := errors.New("task failed")
+	failing := New(ctx, WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, taskErr
+	}), WithRevertFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		t.Error("failed task should not be reverted")
+		return nil, nil
+	}))
+
+	failingRevert.AddSubtasks(okRevert)
+	okRevert.AddSubtasks(failing)
+
+	_, err := Run([]*Task{failingRevert})
+
+	var rerr *RevertError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("expected a RevertError, got %v", err)
+	}
+	if !errors.Is(err, taskErr) {
+		t.Error("expected error to wrap the task error")
+	}
+	if !errors.Is(err, compensationErr) {
+		t.Error("expected error to wrap the revert error")
+	}
+	if len(rerr.Failures) != 1 || rerr.Tasks()[0] != failingRevert {
+		t.Errorf("expected exactly the failing compensation to be reported, got %v", rerr.Failures)
+	}
+	if len(reverted) != 1 {
+		t.Error("expected remaining tasks to be reverted after a failed compensation")
+	}
+}
+
+func TestRevertWithoutFailures(t *testing.T) {
+	task := New(context.Background(), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, nil
+	}), WithRevertFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, nil
+	}))
+
+	if err := Revert([]*Task{task}); err != nil {
+		t.Errorf("didnt expect error, got %v", err)
+	}
+}
+
+func TestWithRevertRetry(t *testing.T) {
+	ctx := context.Background()
+
+	calls := 0
+	root := New(ctx, WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, nil
+	}), WithRevertFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		calls++
+		if calls < 3 {
+			return nil, errors.New("refund failed")
+		}
+		return nil, nil
+	}), WithRevertRetry(3, ConstantBackoff(time.Millisecond)))
+	root.AddSubtasks(New(ctx, WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, errors.New("foobar")
+	})))
+
+	_, err := Run([]*Task{root})
+	var rerr *RevertError
+	if errors.As(err, &rerr) {
+		t.Fatalf("didnt expect a revert failure, got %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("expected %d revert attempts, got %d", 3, calls)
+	}
+}
+
+func TestWithRevertRetryExhausted(t *testing.T) {
+	ctx := context.Background()
+
+	calls := 0
+	root := New(ctx, WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, nil
+	}), WithRevertFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		calls++
+		return nil, errors.New("refund failed")
+	}), WithRevertRetry(2, nil))
+	root.AddSubtasks(New(ctx, WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, errors.New("foobar")
+	})))
+
+	_, err := Run([]*Task{root})
+	var rerr *RevertError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("expected a RevertError, got %v", err)
+	}
+	if len(rerr.Failures) != 1 || rerr.Failures[0].Attempts != 2 {
+		t.Errorf("expected one failure after %d attempts, got %+v", 2, rerr.Failures)
+	}
+	if calls != 2 {
+		t.Errorf("expected %d revert attempts, got %d", 2, calls)
+	}
+}
+
+func TestWithRevertTimeout(t *testing.T) {
+	ctx := context.Background()
+
+	var calls atomic.Int64
+	root := New(ctx, WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, nil
+	}), WithRevertFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		if calls.Add(1) == 1 {
+			// hangs and ignores its context
+			time.Sleep(time.Second)
+		}
+		return nil, nil
+	}), WithRevertTimeout(10*time.Millisecond), WithRevertRetry(2, nil))
+	root.AddSubtasks(New(ctx, WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, errors.New("foobar")
+	})))
+
+	start := time.Now()
+	_, err := Run([]*Task{root})
+	var rerr *RevertError
+	if errors.As(err, &rerr) {
+		t.Fatalf("didnt expect a revert failure, got %v", err)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Error("expected the hanging revert attempt to be abandoned")
+	}
+	if calls.Load() != 2 {
+		t.Errorf("expected %d revert attempts, got %d", 2, calls.Load())
+	}
+}
+
+func TestRunRevertSubtasks(t *testing.T) {
+	ctx := context.Background()
+
+	var reverted []string
+	revert := func(name string) TaskConfigFunc {
+		return WithRevertFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+			reverted = append(reverted, name)
+			return nil, nil
+		})
+	}
+	root := New(ctx, WithFunc(noop), revert("root"))
+	child := New(ctx, WithFunc(noop), revert("child"))
+	failed := New(ctx, WithFunc(noop), revert("failed"))
+	grandchild := New(ctx, WithFunc(noop), revert("grandchild"))
+	if err := root.AddSubtasks(child, failed); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if err := child.AddSubtasks(grandchild); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	for _, task := range []*Task{root, child, grandchild} {
+		task.setStatus(Succeeded)
+	}
+	failed.setStatus(Failed)
+
+	s := NewRunner().newRun(ctx, "", nil)
+	defer s.cancel(nil)
+	if err := s.revert(nil, []*Task{grandchild, root}); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if expected := []string{"grandchild", "root", "child"}; !reflect.DeepEqual(reverted, expected) {
+		t.Errorf("expected reverts %v, got %v", expected, reverted)
+	}
+}
@@ -0,0 +1,636 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RunOptions holds the settings a Runner applies to every run it executes.
+//
+// Members:
+// - RetryBudget: the total number of retries allowed across all tasks of a single run, zero means unlimited
+// - Limiters: the adaptive concurrency limiters applied to tasks, keyed by task tag
+// - Sinks: the sinks receiving the result of every task as soon as it completes
+// - Concurrency: the maximum number of tasks running at the same time, values below 2 run tasks sequentially
+// - Middleware: the middleware wrapping the Run function of every task
+// - Metrics: the collector recording metrics about every task, nil disables metrics
+// - Tracer: the tracer creating a span for every task, nil disables tracing
+// - Logger: the logger receiving structured events about every task, nil disables logging
+// - SerialQueue: the queue serializing tasks sharing a serial key across runs, nil serializes them within a run only
+// - CheckpointStore: the store recording the outputs of completed tasks of runs started with Resume
+// - IdempotencyStore: the store recording the outputs of tasks with an idempotency key
+// - Resources: the pool of named resources tasks declare with WithResources, nil ignores the declarations
+// - Seed: the seed of the random sources returned by TaskContext.Rand, zero picks a random seed for every run
+// - PanicHandler: the handler called with every panic a task fails with, nil disables it
+// - GoroutineDump: whether panics capture the stack traces of all goroutines
+// - Durability: the policy applied when the CheckpointStore or IdempotencyStore is unavailable
+// - CircuitBreaker: the circuits guarding named tasks against repeated failures, nil disables them
+// - RequireRevert: whether graphs with mutating tasks that are neither revertible nor idempotent are refused, see WithRequireRevert
+// - FailureHandler: the handler called with the error of every run started with SubmitDetached that failed, nil disables it
+// - Isolation: whether tasks run on goroutines of their own even when they run sequentially, see WithIsolation
+// - Retention: the policy deciding which outputs of completed tasks a run keeps, see WithResultRetention
+// - FailurePolicy: the policy deciding how much of a run fails when a task fails, see WithFailurePolicy
+type RunOptions struct {
+	RetryBudget      int
+	Concurrency      int
+	Limiters         map[string]*AIMDLimiter
+	Sinks            []Sink
+	Middleware       []Middleware
+	Metrics          MetricsCollector
+	Tracer           trace.Tracer
+	Logger           *slog.Logger
+	SerialQueue      *SerialQueue
+	CheckpointStore  CheckpointStore
+	IdempotencyStore IdempotencyStore
+	Resources        *ResourcePool
+	Seed             int64
+	PanicHandler     PanicHandler
+	GoroutineDump    bool
+	Durability       DurabilityPolicy
+	CircuitBreaker   *CircuitBreaker
+	RequireRevert    bool
+	FailureHandler   FailureHandler
+	Isolation        bool
+	Retention        RetentionPolicy
+	FailurePolicy    FailurePolicy
+}
+
+// RunnerConfigFunc represents a function that can be used to configure a Runner. It takes a pointer to the RunOptions of the Runner as its parameter and sets various fields of it.
+type RunnerConfigFunc func(o *RunOptions)
+
+// Runner executes task graphs with a fixed set of RunOptions. A Runner can be used for many runs and from multiple goroutines,
+// each call to Run keeps its own state.
+type Runner struct {
+	Options RunOptions
+
+	active *activeRuns
+	events *eventBus
+	runIDs *Sequence
+}
+
+// NewRunner creates a new Runner and applies the given configuration functions to its RunOptions.
+func NewRunner(cfgs ...RunnerConfigFunc) *Runner {
+	r := &Runner{
+		active: &activeRuns{},
+		events: &eventBus{},
+		runIDs: NewSequence("run"),
+	}
+
+	for _, cfg := range cfgs {
+		cfg(&r.Options)
+	}
+
+	return r
+}
+
+// WithRetryBudget returns a RunnerConfigFunc that limits the total number of retries across all tasks of a single run to n,
+// so a systemic outage doesn't multiply into thousands of retry attempts. A value of zero disables the limit.
+func WithRetryBudget(n int) RunnerConfigFunc {
+	return func(o *RunOptions) {
+		o.RetryBudget = n
+	}
+}
+
+// WithConcurrency returns a RunnerConfigFunc that runs up to n independent tasks at the same time on separate goroutines.
+// A task still only starts after its parent succeeded. A value of n smaller than 2 runs all tasks sequentially on the calling goroutine.
+func WithConcurrency(n int) RunnerConfigFunc {
+	return func(o *RunOptions) {
+		o.Concurrency = n
+	}
+}
+
+// nextRunID returns the ID of a new run of the Runner, empty if the Runner was not created with NewRunner.
+func (r *Runner) nextRunID() string {
+	if r.runIDs == nil {
+		return ""
+	}
+	return r.runIDs.Next()
+}
+
+// completion is the outcome of executing a single task.
+type completion struct {
+	task     *Task
+	val      interface{}
+	err      error
+	restored bool // whether the output was restored from a checkpoint instead of running the task
+	skipped  bool // whether the task was skipped, see WithCondition
+}
+
+// Run executes the tasks and their subtasks with the options of the Runner. See the package level Run function for details.
+//
+// With a concurrency greater than one, every task receives the initial values followed by the outputs of all tasks that completed
+// before it was started, in completion order, and the returned results are in completion order as well. When a task fails,
+// no further tasks are started and the tasks still running are cancelled with ErrSiblingFailed. Once they returned, every task
+// that succeeded is reverted.
+func (r *Runner) Run(tasks []*Task, values ...interface{}) (*Results, error) {
+	return r.RunCtx(context.Background(), tasks, values...)
+}
+
+// RunCtx executes the tasks like Run, but stops when ctx is done: no further tasks are started, the running tasks are cancelled
+// and every task that succeeded is reverted. The returned error is a *CancelledError wrapping the cause of ctx,
+// so errors.Is(err, context.Canceled) and errors.Is(err, context.DeadlineExceeded) work as expected.
+func (r *Runner) RunCtx(ctx context.Context, tasks []*Task, values ...interface{}) (*Results, error) {
+	return r.runCtx(ctx, "", nil, tasks, values...)
+}
+
+// runCtx executes the tasks, checkpointing completed tasks under graphID unless it is empty and using the restored outputs
+// of tasks completed by an earlier run, keyed by checkpointKey.
+func (r *Runner) runCtx(ctx context.Context, graphID string, restored map[string]interface{}, tasks []*Task, values ...interface{}) (*Results, error) {
+	return r.newRun(ctx, graphID, restored).start(tasks, values...)
+}
+
+// newRun creates the state of a run that is cancelled together with ctx.
+func (r *Runner) newRun(ctx context.Context, graphID string, restored map[string]interface{}) *run {
+	runCtx, cancel := context.WithCancelCause(ctx)
+	return &run{
+		id:       r.nextRunID(),
+		runner:   r,
+		ctx:      runCtx,
+		cancel:   cancel,
+		linked:   ctx.Done() != nil || r.Options.Concurrency > 1,
+		budget:   newRetryBudget(r.Options.RetryBudget),
+		graphID:  graphID,
+		restored: restored,
+		seed:     newSeed(r.Options.Seed),
+		started:  time.Now(),
+		sinks:    append(r.Options.Sinks[:len(r.Options.Sinks):len(r.Options.Sinks)], contextSinks(ctx)...),
+	}
+}
+
+// start executes the tasks and releases the context of the run once they completed.
+func (s *run) start(tasks []*Task, values ...interface{}) (*Results, error) {
+	defer s.cancel(nil)
+
+	results, err := s.run(tasks, values...)
+	s.runner.events.publish(Event{Type: GraphCompleted, RunID: s.id, GraphID: s.graphID, Err: err})
+	return results, err
+}
+
+// run holds the state of a single invocation of Runner.Run.
+type run struct {
+	id        string
+	runner    *Runner
+	ctx       context.Context
+	cancel    context.CancelCauseFunc
+	linked    bool // whether task contexts have to be cancelled together with the run
+	detached  bool // whether the contexts of the tasks are detached from their cancellation, see SubmitDetached
+	budget    *retryBudget
+	results   *Results
+	serial    *serialLocks
+	graphID   string
+	restored  map[string]interface{}
+	seed      int64
+	buffered  []bufferedWrite // outputs waiting to be recorded, see BufferOnStoreError
+	started   time.Time
+	branches  *branches      // the branches of the run under FailBranch, nil otherwise
+	resumable bool           // whether a run started with Resume failed because of a task error, so its checkpoints are kept
+	kept      map[*Task]bool // the checkpointed tasks of a resumable run, which are not reverted
+	sinks     []Sink         // the sinks of the Runner followed by the sinks carried by the context of the run
+
+	mu    sync.Mutex
+	nodes []*Task // guarded by mu while it is set, read-only afterwards
+	spans map[*Task]trace.Span
+}
+
+// run schedules the tasks, their subtasks and dependencies, reverting the successful tasks if any task fails.
+func (s *run) run(tasks []*Task, values ...interface{}) (*Results, error) {
+	g, err := newGraph(tasks)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.runner.checkRevertible(g.nodes); err != nil {
+		return nil, err
+	}
+	tasks = g.roots()
+	if s.runner.Options.FailurePolicy == FailBranch {
+		s.branches = newBranches(g, tasks)
+	}
+
+	s.mu.Lock()
+	s.nodes = g.nodes
+	s.mu.Unlock()
+	for _, task := range s.nodes {
+		task.setStatus(Pending)
+	}
+	s.runner.active.add(s)
+	defer s.runner.active.remove(s)
+
+	s.serial = s.newSerialLocks(s.ctx, g.nodes)
+	defer s.serial.close()
+
+	limit := max(s.runner.Options.Concurrency, 1)
+
+	s.results = newResults(len(g.nodes))
+	s.results.seed = s.seed
+	s.results.runID = s.id
+	successfulTasks := make([]*Task, 0, len(g.nodes))
+	outputs := make(map[*Task]interface{}, len(g.nodes))
+	pending := consumers(g.nodes) // dependents that did not start yet
+	done := make(chan completion, limit)
+	inflight := 0
+	skip := make(map[*Task]bool) // subtasks of skipped tasks
+	checkpointed := make(map[*Task]bool)
+
+	var failure error
+	fail := func(err error) {
+		if failure == nil {
+			failure = err
+			s.cancel(ErrSiblingFailed)
+		}
+	}
+
+	for len(tasks) > 0 || inflight > 0 {
+		for failure == nil && inflight < limit && len(tasks) > 0 {
+			if s.ctx.Err() != nil {
+				fail(newCancelledError(tasks[0], s.ctx))
+				break
+			}
+
+			task := tasks[0]
+			tasks[0] = nil // Clear the pointer for garbage collection
+			tasks = tasks[1:]
+
+			// cap the slice so appends by the runner or the task never touch values seen by others
+			in := values[:len(values):len(values)]
+			if len(task.Dependencies) > 0 {
+				in = make([]interface{}, 0, len(task.Dependencies))
+				for _, dep := range task.Dependencies {
+					in = append(in, outputs[dep])
+					if pending[dep]--; pending[dep] == 0 {
+						delete(outputs, dep)
+					}
+				}
+			}
+
+			inflight++
+			if skip[task] || s.branches.cancelled(task) {
+				done <- completion{task: task, skipped: true}
+				continue
+			}
+			if val, ok := s.restored[checkpointKey(task)]; ok {
+				done <- completion{task: task, val: val, restored: true}
+				continue
+			}
+			if limit == 1 && !s.runner.Options.Isolation {
+				done <- s.execute(task, in)
+				continue
+			}
+			s.spawn(task, in, done)
+		}
+
+		if inflight == 0 {
+			break
+		}
+
+		c := <-done
+		inflight--
+		if c.skipped {
+			c.task.setStatus(Skipped)
+			s.emit(TaskSkipped, c.task, nil)
+			if failure == nil {
+				for _, st := range c.task.Subtasks {
+					skip[st] = true
+				}
+				tasks = append(tasks, g.complete(c.task)...)
+			}
+			continue
+		}
+		c.task.setStatus(completedStatus(c.err))
+
+		if c.err != nil {
+			s.emit(TaskFailed, c.task, c.err)
+			if failure == nil {
+				_ = s.deliver(c.task, nil, c.err)
+			}
+			if failure == nil && s.ctx.Err() == nil {
+				if br := s.branches.of(c.task); br != nil {
+					s.branches.fail(br, c.err)
+					continue
+				}
+				if s.branches.cancelled(c.task) {
+					continue // a task shared with a failed branch was cancelled together with it
+				}
+			}
+			if failure == nil && s.graphID != "" && completedStatus(c.err) == Failed {
+				s.resumable = true
+			}
+			fail(c.err)
+			continue
+		}
+
+		// prepend task to successfulTasks with minimal reallocation
+		successfulTasks = append(successfulTasks[:1], successfulTasks...)
+		successfulTasks[0] = c.task
+		s.emit(TaskSucceeded, c.task, nil)
+
+		if failure != nil {
+			continue
+		}
+
+		if s.runner.Options.Retention == KeepAll {
+			values = append(values, c.val)
+		}
+		s.results.retain(c.task, c.val, s.runner.Options.Retention)
+		if pending[c.task] > 0 {
+			outputs[c.task] = c.val
+		}
+
+		if c.restored {
+			checkpointed[c.task] = true
+		} else {
+			if err := s.deliver(c.task, c.val, nil); err != nil {
+				fail(err)
+				continue
+			}
+			if err := s.checkpoint(c.task, c.val); err != nil {
+				fail(err)
+				continue
+			}
+			checkpointed[c.task] = s.graphID != ""
+			if err := s.record(c.task, c.val); err != nil {
+				fail(idempotencyError(c.task, err))
+				continue
+			}
+		}
+
+		// append subtasks and dependents that are ready now to tasks
+		tasks = append(tasks, g.complete(c.task)...)
+	}
+
+	if err := s.flushAll(); err != nil {
+		fail(err)
+		s.resumable = false
+	}
+	if failure == nil {
+		if err := s.flushGraph(s.ctx); err != nil {
+			fail(err)
+		}
+	} else if s.resumable {
+		if err := s.flushGraph(context.WithoutCancel(s.ctx)); err != nil {
+			failure = errors.Join(failure, err)
+			s.resumable = false
+		}
+	}
+	if failure != nil {
+		for _, task := range s.nodes {
+			if task.Status() == Pending {
+				task.setStatus(Skipped)
+			}
+		}
+		if s.resumable {
+			// keep the checkpointed tasks, so resuming the graph continues with the failed task
+			s.kept = checkpointed
+		}
+		return nil, s.revert(failure, successfulTasks, values...)
+	}
+	if err := s.branches.err(); err != nil {
+		return s.results, s.revertBranches(err, successfulTasks, values...)
+	}
+
+	return s.results, nil
+}
+
+// execute runs a single task until it succeeds or its retry policy gives up, unless its condition skips it.
+// The task context is cancelled together with the run, so in-flight tasks stop when a sibling fails or the caller cancels the run.
+func (s *run) execute(task *Task, values []interface{}) (c completion) {
+	if ok, err := s.condition(task, values); err != nil {
+		return completion{task: task, err: err}
+	} else if !ok {
+		return completion{task: task, skipped: true}
+	}
+
+	task.setStatus(Running)
+	s.emit(TaskStarted, task, nil)
+	attempts := 0
+	if s.runner.Options.Logger != nil {
+		s.log(task.Context, slog.LevelInfo, "task started", task, slog.Int64("seed", s.seed))
+
+		start := time.Now()
+		defer func() {
+			s.logFinished(task.Context, task, attempts, time.Since(start), c.err)
+		}()
+	}
+	if m := s.runner.Options.Metrics; m != nil {
+		m.TaskStarted(task)
+
+		start := time.Now()
+		defer func() {
+			if c.err != nil {
+				m.TaskFailed(task, time.Since(start), c.err)
+			} else {
+				m.TaskSucceeded(task, time.Since(start))
+			}
+		}()
+	}
+	if s.runner.Options.PanicHandler != nil {
+		defer func() {
+			s.panicked(task.Context, task, c.err)
+		}()
+	}
+
+	ctx := context.WithValue(s.baseContext(task), CtxKey("results"), s.results)
+	ctx = context.WithValue(ctx, CtxKey("ctx"), s.taskContext(task))
+	ctx = context.WithValue(ctx, CtxKey("runner"), s.runner)
+	if s.runner.Options.Tracer != nil {
+		var span trace.Span
+		ctx, span = s.startSpan(ctx, task)
+		defer func() {
+			endSpan(span, c.err)
+		}()
+	}
+	if !task.Deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, task.Deadline)
+		defer cancel()
+	}
+	if s.linked {
+		var cancel context.CancelCauseFunc
+		ctx, cancel = context.WithCancelCause(ctx)
+		defer cancel(nil)
+
+		stop := context.AfterFunc(s.ctx, func() {
+			cancel(context.Cause(s.ctx))
+		})
+		defer stop()
+		defer s.branches.track(task, cancel)()
+	}
+
+	held := &heldSlots{}
+	ctx = context.WithValue(ctx, CtxKey("slots"), held)
+
+	if err := s.serial.acquire(ctx, task); err != nil {
+		return completion{task: task, err: newCancelledError(task, ctx)}
+	}
+	defer held.hold(func(bool) {
+		s.serial.release(task)
+	})()
+
+	if val, ok, err := s.recorded(ctx, task); err != nil {
+		return completion{task: task, err: idempotencyError(task, err)}
+	} else if ok {
+		return completion{task: task, val: val}
+	}
+
+	if err := s.acquireResources(ctx, task); err != nil {
+		return completion{task: task, err: err}
+	}
+	defer held.hold(func(bool) {
+		s.releaseResources(task)
+	})()
+
+	for attempt := 1; ; attempt++ {
+		if ctx.Err() != nil {
+			return completion{task: task, err: newCancelledError(task, ctx)}
+		}
+
+		attempts = attempt
+		start := time.Now()
+		val, err := s.attempt(ctx, task, values)
+		elapsed := time.Since(start)
+		if err == nil {
+			return completion{task: task, val: val}
+		}
+
+		retry, err := s.retry(ctx, task, attempt, err)
+		if !retry {
+			return completion{task: task, err: err}
+		}
+		s.log(ctx, slog.LevelWarn, "task attempt failed", task, slog.Int("attempt", attempt), slog.Duration("duration", elapsed), slog.Any("error", err))
+		trace.SpanFromContext(ctx).AddEvent("retry", trace.WithAttributes(
+			attribute.Int("task.attempt", attempt+1),
+			attribute.String("task.error", err.Error()),
+		))
+	}
+}
+
+// attempt runs a task once, holding a slot of every adaptive limiter matching the tags of the task while it runs.
+// The timeout of the task applies to every attempt on its own. If the circuit of the task is open, the attempt fails without running the task.
+func (s *run) attempt(ctx context.Context, task *Task, values []interface{}) (interface{}, error) {
+	if task.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, task.Timeout)
+		defer cancel()
+	}
+
+	b := s.runner.Options.CircuitBreaker
+	trial := false
+	if b != nil {
+		var err error
+		if trial, err = b.allow(task); err != nil {
+			return nil, err
+		}
+	}
+
+	limiters := s.runner.limiters(task)
+	for i, l := range limiters {
+		if err := l.Acquire(ctx); err != nil {
+			for _, acquired := range limiters[:i] {
+				acquired.Release(0, s.health(task, err))
+			}
+			if b != nil {
+				b.report(task, trial, s.health(task, err))
+			}
+			return nil, err
+		}
+	}
+
+	var elapsed time.Duration
+	var health error
+	release := func(handedOver bool) {
+		if handedOver {
+			// the slots are given up while the task runs, so its outcome can't be reported
+			for _, l := range limiters {
+				l.Release(0, &CancelledError{TaskID: task.ID, Reason: CancelledByCaller, Cause: errHandedOver})
+			}
+			return
+		}
+		for _, l := range limiters {
+			l.Release(elapsed, health)
+		}
+	}
+	done := func() {
+		release(false)
+	}
+	if held, ok := ctx.Value(CtxKey("slots")).(*heldSlots); ok {
+		done = held.hold(release)
+	}
+
+	start := time.Now()
+	val, err := call(ctx, task, s.runner.chain(task), values, s.runner.Options.GoroutineDump)
+	elapsed = time.Since(start)
+
+	if err != nil && ctx.Err() != nil && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) {
+		err = newCancelledError(task, ctx)
+	}
+
+	health = s.health(task, err)
+	done()
+	if b != nil {
+		b.report(task, trial, health)
+	}
+
+	return val, err
+}
+
+// health returns the error an attempt of a task reports to its limiters and circuit: err, unless the run itself is done, in which case
+// the attempt was cut short by the caller, even if by a deadline of the run, and says nothing about the health of the downstream.
+func (s *run) health(task *Task, err error) error {
+	if err != nil && s.ctx.Err() != nil {
+		return &CancelledError{TaskID: task.ID, Reason: CancelledByCaller, Cause: context.Cause(s.ctx)}
+	}
+	return err
+}
+
+// retry decides whether a failed attempt of a task is retried and waits for the backoff delay if so.
+// A delay requested with RetryIn takes precedence over the backoff strategy of the task. The returned error is the
+// error the task fails with if it is not retried.
+func (s *run) retry(ctx context.Context, task *Task, attempt int, err error) (bool, error) {
+	if attempt >= task.Retry.Attempts {
+		return false, err
+	}
+	// a timed out attempt is retried, a cancelled task is not
+	if ctx.Err() != nil {
+		return false, newCancelledError(task, ctx)
+	}
+	if !s.budget.take() {
+		return false, fmt.Errorf("%w: %w", ErrRetryBudgetExhausted, err)
+	}
+
+	delay, ok := RetryAfter(err)
+	if !ok && task.Retry.Backoff != nil {
+		delay = task.Retry.Backoff.Backoff(attempt)
+	}
+
+	// a cancellation while waiting is reported by the next attempt
+	_ = Sleep(ctx, delay)
+	return true, err
+}
+
+// retryBudget keeps track of the retries left in a single run. It is safe for concurrent use.
+type retryBudget struct {
+	limited bool
+	left    atomic.Int64
+}
+
+// newRetryBudget creates a retryBudget allowing n retries. A value of zero or less means unlimited retries.
+func newRetryBudget(n int) *retryBudget {
+	b := &retryBudget{limited: n > 0}
+	b.left.Store(int64(n))
+	return b
+}
+
+// take consumes one retry from the budget and reports whether the retry is allowed.
+func (b *retryBudget) take() bool {
+	if !b.limited {
+		return true
+	}
+	return b.left.Add(-1) >= 0
+}
@@ -0,0 +1,160 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunnerRun(t *testing.T) {
+	r := NewRunner(WithRetryBudget(3))
+	if r.Options.RetryBudget != 3 {
+		t.Errorf("expected retry budget of %d, got %d", 3, r.Options.RetryBudget)
+	}
+
+	task := New(context.Background(), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return 1, nil
+	}))
+
+	result, err := r.Run([]*Task{task})
+	if err != nil {
+		t.Fatal("didnt expect error")
+	}
+	if result.Len() != 1 {
+		t.Error("expected 1 result")
+	}
+}
+
+func TestRetryBudget(t *testing.T) {
+	b := newRetryBudget(2)
+	for i := 0; i < 2; i++ {
+		if !b.take() {
+			t.Fatalf("expected retry %d to be allowed", i)
+		}
+	}
+	if b.take() {
+		t.Error("expected budget to be exhausted")
+	}
+
+	unlimited := newRetryBudget(0)
+	for i := 0; i < 100; i++ {
+		if !unlimited.take() {
+			t.Fatal("expected unlimited budget")
+		}
+	}
+}
+
+func TestRunnerConcurrency(t *testing.T) {
+	ctx := context.Background()
+	root := New(ctx, WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return "root", nil
+	}))
+
+	// both siblings wait for each other, so they only finish when running at the same time
+	barrier := make(chan struct{})
+	sibling := func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		if len(values) == 0 || values[0] != "root" {
+			t.Error("expected parent output before subtask runs")
+		}
+		select {
+		case barrier <- struct{}{}:
+		case <-barrier:
+		case <-time.After(time.Second):
+			return nil, errors.New("sibling did not run concurrently")
+		}
+		return "sibling", nil
+	}
+	root.AddSubtasks(New(ctx, WithFunc(sibling)), New(ctx, WithFunc(sibling)))
+
+	result, err := NewRunner(WithConcurrency(2)).Run([]*Task{root})
+	if err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if result.Len() != 3 {
+		t.Errorf("expected 3 results, got %d", result.Len())
+	}
+}
+
+func TestRunnerConcurrencySiblingFailed(t *testing.T) {
+	ctx := context.Background()
+
+	var reverted atomic.Int64
+	revert := WithRevertFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		reverted.Add(1)
+		return nil, nil
+	})
+
+	root := New(ctx, WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, nil
+	}), revert)
+
+	started := make(chan struct{})
+	cancelled := make(chan error, 1)
+	slow := New(ctx, WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		close(started)
+		<-ctx.Done()
+		cancelled <- CheckCancelled(ctx)
+		return nil, ctx.Err()
+	}))
+	failing := New(ctx, WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		<-started
+		return nil, errors.New("foobar")
+	}))
+	root.AddSubtasks(slow, failing)
+
+	_, err := NewRunner(WithConcurrency(4)).Run([]*Task{root})
+	if err == nil || err.Error() != "foobar" {
+		t.Fatalf("expected the error of the failing task, got %v", err)
+	}
+	if cerr := <-cancelled; !errors.Is(cerr, ErrSiblingFailed) {
+		t.Errorf("expected in-flight sibling to be cancelled because sibling failed, got %v", cerr)
+	}
+	if reverted.Load() == 0 {
+		t.Error("expected successful tasks to be reverted")
+	}
+}
+
+func TestRunCtxCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	reverted := false
+	root := New(context.Background(), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		cancel()
+		return nil, nil
+	}), WithRevertFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		reverted = true
+		return nil, nil
+	}))
+	root.AddSubtasks(New(context.Background(), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		t.Error("subtask should not run after the run was cancelled")
+		return nil, nil
+	})))
+
+	_, err := RunCtx(ctx, []*Task{root})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the run to be cancelled, got %v", err)
+	}
+	if !errors.Is(err, ErrCancelledByCaller) {
+		t.Error("expected the run to be cancelled by the caller")
+	}
+	if !reverted {
+		t.Error("expected completed work to be reverted")
+	}
+}
+
+func TestRunCtxCancelsRunningTask(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	task := New(context.Background(), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}))
+
+	_, err := RunCtx(ctx, []*Task{task})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the run to time out, got %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+// Package saga provides a builder for sagas: sequences of steps that each pair an action with the compensation undoing it,
+// executed with the task runner so a failing step compensates every step that completed before it.
+package saga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/codecreationlabs/async/task"
+	"github.com/codecreationlabs/async/task/pipeline"
+)
+
+// ErrNoCompensation is returned by Run if a step has a nil compensation.
+var ErrNoCompensation = errors.New("step without compensation")
+
+// ConfigFunc represents a function that can be used to configure a Saga. It takes a pointer to a Saga as its parameter and sets various fields of it.
+type ConfigFunc func(s *Saga)
+
+// WithRunner returns a ConfigFunc that makes the Saga run with r instead of a Runner with default options.
+func WithRunner(r *task.Runner) ConfigFunc {
+	return func(s *Saga) {
+		s.runner = r
+	}
+}
+
+// step is a single action of a Saga together with its compensation.
+type step struct {
+	do   task.TaskFunc
+	undo task.TaskFunc
+	cfgs []task.TaskConfigFunc
+}
+
+// Saga is a sequence of steps that each pair an action with its compensation. The methods of a Saga return it, so calls can be chained.
+// A Saga can be run many times, each run creates tasks of its own.
+type Saga struct {
+	runner *task.Runner
+	steps  []step
+}
+
+// New creates a new Saga without steps.
+//
+// Example usage:
+//
+//	results, err := saga.New().
+//		Step(reserveStock, releaseStock).
+//		Step(chargeCard, refundCard).
+//		Step(shipOrder, cancelShipment).
+//		Run(ctx, order)
+func New(cfgs ...ConfigFunc) *Saga {
+	s := &Saga{}
+	for _, cfg := range cfgs {
+		cfg(s)
+	}
+	if s.runner == nil {
+		s.runner = task.NewRunner()
+	}
+	return s
+}
+
+// Step adds a step running do after the previous step succeeded. If do or a later step fails, undo is called to compensate do.
+// The first step receives the values passed to Run, every further step the output of the previous step. undo receives the values
+// of the run like the Revert function of any task. The cfgs configure the task of the step, e.g. its name or retry policy.
+func (s *Saga) Step(do, undo task.TaskFunc, cfgs ...task.TaskConfigFunc) *Saga {
+	s.steps = append(s.steps, step{do: do, undo: undo, cfgs: cfgs})
+	return s
+}
+
+// Run executes the steps in order with the values as the input of the first step. If a step fails, the steps that completed before it
+// are compensated in reverse order and the error is returned like by task.Runner.RunCtx. Run fails without executing any step if
+// a step has a nil compensation.
+func (s *Saga) Run(ctx context.Context, values ...interface{}) (*task.Results, error) {
+	b := pipeline.New(ctx)
+	for i, st := range s.steps {
+		if st.undo == nil {
+			return nil, fmt.Errorf("step %d: %w", i, ErrNoCompensation)
+		}
+		b.Then(st.do, st.cfgs...).OnError(st.undo)
+	}
+
+	tasks, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+	return s.runner.RunCtx(ctx, tasks, values...)
+}
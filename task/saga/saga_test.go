@@ -0,0 +1,67 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/codecreationlabs/async/task"
+)
+
+func TestSaga(t *testing.T) {
+	var log []string
+	step := func(name string, err error) (task.TaskFunc, task.TaskFunc) {
+		return func(ctx context.Context, values ...interface{}) (interface{}, error) {
+				log = append(log, "do "+name)
+				return name, err
+			}, func(ctx context.Context, values ...interface{}) (interface{}, error) {
+				log = append(log, "undo "+name)
+				return nil, nil
+			}
+	}
+
+	reserve, release := step("reserve", nil)
+	charge, refund := step("charge", nil)
+	ship, cancel := step("ship", nil)
+	results, err := New().Step(reserve, release).Step(charge, refund).Step(ship, cancel, task.WithName("ship")).Run(context.Background())
+	if err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if v, _ := results.Get("ship"); v != "ship" {
+		t.Errorf("expected %q, got %v", "ship", v)
+	}
+
+	log = nil
+	failing, undo := step("ship", errors.New("foobar"))
+	_, err = New(WithRunner(task.NewRunner())).Step(reserve, release).Step(charge, refund).Step(failing, undo).Run(context.Background())
+	if err == nil || err.Error() != "foobar" {
+		t.Fatalf("expected error %q, got %v", "foobar", err)
+	}
+
+	expected := []string{"do reserve", "do charge", "do ship", "undo charge", "undo reserve"}
+	if len(log) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, log)
+	}
+	for i := range expected {
+		if log[i] != expected[i] {
+			t.Errorf("expected %v, got %v", expected, log)
+			break
+		}
+	}
+}
+
+func TestSagaNoCompensation(t *testing.T) {
+	ran := false
+	do := func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		ran = true
+		return nil, nil
+	}
+
+	_, err := New().Step(do, do).Step(do, nil).Run(context.Background())
+	if !errors.Is(err, ErrNoCompensation) {
+		t.Errorf("expected error %v, got %v", ErrNoCompensation, err)
+	}
+	if ran {
+		t.Error("expected no step to run")
+	}
+}
@@ -0,0 +1,52 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	from := time.Date(2024, time.January, 31, 10, 7, 30, 0, time.UTC) // a Wednesday
+
+	tests := map[string]time.Time{
+		"* * * * *":        time.Date(2024, time.January, 31, 10, 8, 0, 0, time.UTC),
+		"*/15 * * * *":     time.Date(2024, time.January, 31, 10, 15, 0, 0, time.UTC),
+		"0 9 * * mon-fri":  time.Date(2024, time.February, 1, 9, 0, 0, 0, time.UTC),
+		"30 2 1 * *":       time.Date(2024, time.February, 1, 2, 30, 0, 0, time.UTC),
+		"0 0 29 feb *":     time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC),
+		"0 12 * * 7":       time.Date(2024, time.February, 4, 12, 0, 0, 0, time.UTC),
+		"0 0 15 * fri":     time.Date(2024, time.February, 2, 0, 0, 0, 0, time.UTC),
+		"5,10 10-11 * * *": time.Date(2024, time.January, 31, 10, 10, 0, 0, time.UTC),
+		"5/20 * * * *":     time.Date(2024, time.January, 31, 10, 25, 0, 0, time.UTC),
+		"@daily":           time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC),
+		"@hourly":          time.Date(2024, time.January, 31, 11, 0, 0, 0, time.UTC),
+	}
+
+	for expr, expected := range tests {
+		schedule, err := Cron(expr)
+		if err != nil {
+			t.Fatalf("%s: didnt expect error, got %v", expr, err)
+		}
+		if next := schedule.Next(from); !next.Equal(expected) {
+			t.Errorf("%s: expected %v, got %v", expr, expected, next)
+		}
+	}
+}
+
+func TestCronNeverMatches(t *testing.T) {
+	schedule, err := Cron("0 0 30 feb *")
+	if err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if next := schedule.Next(time.Now()); !next.IsZero() {
+		t.Errorf("expected no next run, got %v", next)
+	}
+}
+
+func TestCronInvalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "* * * foo *"} {
+		if _, err := Cron(expr); err == nil {
+			t.Errorf("%q: expected an error", expr)
+		}
+	}
+}
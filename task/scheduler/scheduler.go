@@ -0,0 +1,384 @@
+// Package scheduler runs task graphs at points in time given by cron expressions, after a delay or at a fixed time,
+// feeding them into a task.Runner.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/codecreationlabs/async/task"
+)
+
+var (
+	// ErrStarted is returned by Start if the Scheduler was started before.
+	ErrStarted = errors.New("scheduler already started")
+	// ErrStopped is returned by Register and Start if the Scheduler was stopped. It is also the cause of the cancellation of runs
+	// that didn't return before the context passed to Stop was done.
+	ErrStopped = errors.New("scheduler stopped")
+	// ErrDuplicateJob is returned by Register if a job with the same name is registered already.
+	ErrDuplicateJob = errors.New("duplicate job")
+	// ErrSkipped is passed to the Handler of a run that was skipped because the previous run of the job was still running, see SkipOverlap.
+	ErrSkipped = errors.New("run skipped, previous run still running")
+	// ErrReplaced is the cause of the cancellation of a run replaced by the next run of the job, see ReplaceOverlap.
+	ErrReplaced = errors.New("run replaced by the next run")
+)
+
+// Graph builds the task graph of a single run of a job. It is called for every run, so runs never share tasks.
+type Graph func(ctx context.Context) []*task.Task
+
+// Handler is called with the outcome of every run of a job.
+type Handler func(job string, results *task.Results, err error)
+
+// OverlapPolicy describes what happens when a job is due while its previous run is still running.
+type OverlapPolicy int
+
+const (
+	// SkipOverlap skips the run, the Handler is called with ErrSkipped. It is the default.
+	SkipOverlap OverlapPolicy = iota
+	// AllowOverlap starts the run alongside the previous one.
+	AllowOverlap
+	// QueueOverlap starts the run as soon as the previous one returned. Runs due while another run is queued are dropped,
+	// so a slow job never builds up a backlog.
+	QueueOverlap
+	// ReplaceOverlap cancels the previous run with ErrReplaced and starts the run right away.
+	ReplaceOverlap
+)
+
+// ConfigFunc represents a function that can be used to configure a Scheduler. It takes a pointer to a Scheduler as its parameter and sets various fields of it.
+type ConfigFunc func(s *Scheduler)
+
+// WithRunner returns a ConfigFunc that makes the Scheduler execute task graphs with r instead of a Runner with default options.
+func WithRunner(r *task.Runner) ConfigFunc {
+	return func(s *Scheduler) {
+		s.runner = r
+	}
+}
+
+// Clock is the source of time of a Scheduler. The default uses package time; tests can inject a fake clock, see WithClock,
+// to fire jobs deterministically.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// NewTimer returns a channel receiving the current time once d elapsed, right away if d is not positive,
+	// together with a function stopping the timer.
+	NewTimer(d time.Duration) (<-chan time.Time, func() bool)
+}
+
+// realClock is the Clock backed by package time.
+type realClock struct{}
+
+// Now implements the Clock interface.
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// NewTimer implements the Clock interface.
+func (realClock) NewTimer(d time.Duration) (<-chan time.Time, func() bool) {
+	t := time.NewTimer(d)
+	return t.C, t.Stop
+}
+
+// WithClock returns a ConfigFunc that makes the Scheduler read the time from c and wait for jobs with its timers instead of package time.
+func WithClock(c Clock) ConfigFunc {
+	return func(s *Scheduler) {
+		s.clock = c
+	}
+}
+
+// WithHandler returns a ConfigFunc that calls h with the outcome of every run, e.g. to log failures or record metrics.
+func WithHandler(h Handler) ConfigFunc {
+	return func(s *Scheduler) {
+		s.handler = h
+	}
+}
+
+// JobConfigFunc represents a function that can be used to configure a Job when it is registered. It takes a pointer to the Job as its parameter and sets various fields of it.
+type JobConfigFunc func(j *Job)
+
+// WithOverlapPolicy returns a JobConfigFunc that handles runs of the job being due while its previous run is still running with p.
+func WithOverlapPolicy(p OverlapPolicy) JobConfigFunc {
+	return func(j *Job) {
+		j.Overlap = p
+	}
+}
+
+// Job is a task graph registered with a Schedule.
+//
+// Members:
+// - Name: the name the job was registered under, passed to the Handler
+// - Schedule: the schedule giving the points in time the job runs at
+// - Graph: the function building the task graph of every run
+// - Overlap: the policy applied when the job is due while its previous run is still running
+type Job struct {
+	Name     string
+	Schedule Schedule
+	Graph    Graph
+	Overlap  OverlapPolicy
+
+	mu      sync.Mutex
+	running int
+	queued  bool
+	cancel  context.CancelCauseFunc // cancels the latest run
+}
+
+// Scheduler runs registered task graphs according to their Schedule. Jobs can be registered before and after Start.
+// A Scheduler is safe for concurrent use.
+type Scheduler struct {
+	runner  *task.Runner
+	handler Handler
+	clock   Clock
+
+	mu      sync.Mutex
+	jobs    map[string]*Job
+	ctx     context.Context // context of all runs, cancelled when Stop gives up waiting
+	cancel  context.CancelCauseFunc
+	stop    chan struct{} // closed by Stop to end the job loops
+	started bool
+	stopped bool
+
+	loops sync.WaitGroup
+	runs  sync.WaitGroup
+}
+
+// New creates a new Scheduler and applies the given configuration functions. Jobs don't run before Start is called.
+//
+// Example usage:
+//
+//	s := scheduler.New(scheduler.WithRunner(runner), scheduler.WithHandler(func(job string, _ *task.Results, err error) {
+//		if err != nil {
+//			log.Printf("job %s failed: %v", job, err)
+//		}
+//	}))
+//	if err := s.RegisterCron("invoices", "0 2 * * *", buildInvoiceGraph); err != nil {
+//		return err
+//	}
+//	if err := s.Start(); err != nil {
+//		return err
+//	}
+//	defer s.Stop(ctx)
+func New(cfgs ...ConfigFunc) *Scheduler {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	s := &Scheduler{
+		jobs:   make(map[string]*Job),
+		ctx:    ctx,
+		cancel: cancel,
+		stop:   make(chan struct{}),
+		clock:  realClock{},
+	}
+
+	for _, cfg := range cfgs {
+		cfg(s)
+	}
+
+	if s.runner == nil {
+		s.runner = task.NewRunner()
+	}
+	return s
+}
+
+// Register registers the task graph built by g under name, running it at the points in time given by schedule.
+// The job ends once schedule returns the zero time.
+func (s *Scheduler) Register(name string, schedule Schedule, g Graph, cfgs ...JobConfigFunc) error {
+	j := &Job{
+		Name:     name,
+		Schedule: schedule,
+		Graph:    g,
+	}
+	for _, cfg := range cfgs {
+		cfg(j)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopped {
+		return ErrStopped
+	}
+	if _, ok := s.jobs[name]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicateJob, name)
+	}
+	s.jobs[name] = j
+	if s.started {
+		s.startLoop(j)
+	}
+	return nil
+}
+
+// RegisterCron registers the task graph built by g under name, running it at the points in time matching the cron expression expr, see Cron.
+func (s *Scheduler) RegisterCron(name, expr string, g Graph, cfgs ...JobConfigFunc) error {
+	schedule, err := Cron(expr)
+	if err != nil {
+		return err
+	}
+	return s.Register(name, schedule, g, cfgs...)
+}
+
+// RunAfter registers the task graph built by g under name, running it once after delay. The delay starts when RunAfter is called,
+// so a job registered before Start runs right away on Start if its delay has passed by then.
+func (s *Scheduler) RunAfter(name string, delay time.Duration, g Graph, cfgs ...JobConfigFunc) error {
+	return s.RunAt(name, s.clock.Now().Add(delay), g, cfgs...)
+}
+
+// RunAt registers the task graph built by g under name, running it once at t. If t has passed when the job starts, it runs right away.
+func (s *Scheduler) RunAt(name string, t time.Time, g Graph, cfgs ...JobConfigFunc) error {
+	return s.Register(name, &once{at: t}, g, cfgs...)
+}
+
+// Start starts running the registered jobs. It returns ErrStarted if the Scheduler was started before, and ErrStopped if it was stopped.
+func (s *Scheduler) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopped {
+		return ErrStopped
+	}
+	if s.started {
+		return ErrStarted
+	}
+	s.started = true
+
+	for _, j := range s.jobs {
+		s.startLoop(j)
+	}
+	return nil
+}
+
+// Stop stops starting new runs and waits until the running ones returned or ctx is done. In the latter case, the remaining runs are cancelled
+// with ErrStopped, so they revert, and Stop returns the result of task.CheckCancelled without waiting for them. Queued runs are dropped.
+// A stopped Scheduler can't be started again.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.stopped {
+		s.stopped = true
+		close(s.stop)
+	}
+	s.mu.Unlock()
+
+	s.loops.Wait()
+
+	done := make(chan struct{})
+	go func() {
+		s.runs.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		s.cancel(ErrStopped)
+		return nil
+	case <-ctx.Done():
+		s.cancel(ErrStopped)
+		return task.CheckCancelled(ctx)
+	}
+}
+
+// startLoop starts the goroutine running j according to its Schedule. The caller has to hold s.mu.
+func (s *Scheduler) startLoop(j *Job) {
+	s.loops.Add(1)
+	go func() {
+		defer s.loops.Done()
+
+		var last time.Time
+		for {
+			next := nextFire(j.Schedule, last, s.clock.Now())
+			if next.IsZero() {
+				return
+			}
+
+			fired, stop := s.clock.NewTimer(next.Sub(s.clock.Now()))
+			select {
+			case <-fired:
+			case <-s.stop:
+				stop()
+				return
+			}
+
+			last = next
+			s.due(j)
+		}
+	}()
+}
+
+// nextFire returns the point in time schedule runs a job at after it last fired at last, given the current time now.
+// It is computed from the later of both, so points in time missed while the process was suspended or stalled
+// are not replayed one after the other: the late fire stands in for all of them.
+func nextFire(schedule Schedule, last, now time.Time) time.Time {
+	if now.After(last) {
+		last = now
+	}
+	return schedule.Next(last)
+}
+
+// due starts a run of j according to its OverlapPolicy.
+func (s *Scheduler) due(j *Job) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.running > 0 {
+		switch j.Overlap {
+		case SkipOverlap:
+			s.handle(j, nil, ErrSkipped)
+			return
+		case QueueOverlap:
+			j.queued = true
+			return
+		case ReplaceOverlap:
+			j.cancel(ErrReplaced)
+		}
+	}
+	s.start(j)
+}
+
+// start starts a run of j. The caller has to hold j.mu.
+func (s *Scheduler) start(j *Job) {
+	ctx, cancel := context.WithCancelCause(s.ctx)
+	j.cancel = cancel
+	j.running++
+
+	s.runs.Add(1)
+	go func() {
+		defer s.runs.Done()
+
+		results, err := s.runner.RunCtx(ctx, j.Graph(ctx))
+		cancel(nil)
+		s.handle(j, results, err)
+
+		j.mu.Lock()
+		defer j.mu.Unlock()
+
+		j.running--
+		if j.queued && j.running == 0 {
+			j.queued = false
+			select {
+			case <-s.stop:
+			default:
+				s.start(j)
+			}
+		}
+	}()
+}
+
+// handle calls the Handler of the Scheduler, if it has one.
+func (s *Scheduler) handle(j *Job, results *task.Results, err error) {
+	if s.handler != nil {
+		s.handler(j.Name, results, err)
+	}
+}
+
+// once is a Schedule running a job once at a fixed point in time, or right away if it has passed.
+type once struct {
+	at    time.Time
+	fired bool
+}
+
+// Next implements the Schedule interface. Unlike other schedules, it returns its point in time on the first call even if it has passed.
+func (o *once) Next(time.Time) time.Time {
+	if o.fired {
+		return time.Time{}
+	}
+	o.fired = true
+	return o.at
+}
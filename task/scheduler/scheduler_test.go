@@ -0,0 +1,482 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/codecreationlabs/async/task"
+)
+
+// every is a Schedule running a job every d.
+type every time.Duration
+
+func (e every) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(e))
+}
+
+// fakeClock is a Clock whose time only moves on Advance, so tests fire jobs deterministically.
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+	armed  chan time.Time // receives the deadline of every timer that doesn't fire right away
+}
+
+type fakeTimer struct {
+	deadline time.Time
+	c        chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{
+		now:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		armed: make(chan time.Time, 100),
+	}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) NewTimer(d time.Duration) (<-chan time.Time, func() bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &fakeTimer{deadline: c.now.Add(d), c: make(chan time.Time, 1)}
+	if d <= 0 {
+		t.c <- c.now
+		return t.c, func() bool { return false }
+	}
+	c.timers = append(c.timers, t)
+	c.armed <- t.deadline
+	return t.c, func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		for i, other := range c.timers {
+			if other == t {
+				c.timers = append(c.timers[:i], c.timers[i+1:]...)
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Advance moves the time forward by d and fires the timers that are due.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	pending := c.timers[:0]
+	for _, t := range c.timers {
+		if t.deadline.After(c.now) {
+			pending = append(pending, t)
+			continue
+		}
+		t.c <- c.now
+	}
+	c.timers = pending
+}
+
+// waitArmed blocks until a job loop waits for its next point in time and returns it.
+func (c *fakeClock) waitArmed(t *testing.T) time.Time {
+	t.Helper()
+	select {
+	case deadline := <-c.armed:
+		return deadline
+	case <-time.After(time.Second):
+		t.Fatal("expected the job loop to wait for its next point in time")
+		return time.Time{}
+	}
+}
+
+// outcomes records the outcomes passed to the Handler.
+type outcomes struct {
+	mu   sync.Mutex
+	errs []error
+	vals []interface{}
+	done chan error
+}
+
+func newOutcomes() *outcomes {
+	return &outcomes{done: make(chan error, 100)}
+}
+
+func (o *outcomes) handle(job string, results *task.Results, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.errs = append(o.errs, err)
+	o.vals = append(o.vals, results.Values()...)
+	o.done <- err
+}
+
+// wait blocks until the Handler was called once more and returns the error it was called with.
+func (o *outcomes) wait(t *testing.T) error {
+	t.Helper()
+	select {
+	case err := <-o.done:
+		return err
+	case <-time.After(time.Second):
+		t.Fatal("expected the handler to be called")
+		return nil
+	}
+}
+
+func (o *outcomes) count(target error) int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	n := 0
+	for _, err := range o.errs {
+		if errors.Is(err, target) {
+			n++
+		}
+	}
+	return n
+}
+
+// gate builds graphs of a single task that blocks until it is released or cancelled, tracking the number of running graphs.
+type gate struct {
+	started chan struct{}
+	release chan struct{}
+	running atomic.Int64
+	peak    atomic.Int64
+}
+
+func newGate() *gate {
+	return &gate{
+		started: make(chan struct{}, 100),
+		release: make(chan struct{}),
+	}
+}
+
+func (g *gate) graph(ctx context.Context) []*task.Task {
+	return []*task.Task{task.New(ctx, task.WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		n := g.running.Add(1)
+		defer g.running.Add(-1)
+		for {
+			old := g.peak.Load()
+			if n <= old || g.peak.CompareAndSwap(old, n) {
+				break
+			}
+		}
+		g.started <- struct{}{}
+
+		select {
+		case <-g.release:
+			return "done", nil
+		case <-ctx.Done():
+			return nil, task.CheckCancelled(ctx)
+		}
+	}))}
+}
+
+// waitStarted blocks until a graph started running.
+func (g *gate) waitStarted(t *testing.T) {
+	t.Helper()
+	select {
+	case <-g.started:
+	case <-time.After(time.Second):
+		t.Fatal("expected a run to start")
+	}
+}
+
+// done is a Graph of a single task returning "done" right away.
+func done(ctx context.Context) []*task.Task {
+	return []*task.Task{task.New(ctx, task.WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return "done", nil
+	}))}
+}
+
+// stopped returns a context that is done already, so Stop cancels the running graphs right away.
+func stopped() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	return ctx
+}
+
+func TestRunAfter(t *testing.T) {
+	o := newOutcomes()
+	c := newFakeClock()
+	s := New(WithHandler(o.handle), WithClock(c))
+
+	if err := s.RunAfter("once", 5*time.Millisecond, done); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if err := s.Start(); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+
+	c.waitArmed(t)
+	c.Advance(5 * time.Millisecond)
+	if err := o.wait(t); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if err := s.Stop(context.Background()); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+
+	if len(o.errs) != 1 {
+		t.Fatalf("expected a single run, got %v", o.errs)
+	}
+	if o.vals[0] != "done" {
+		t.Errorf("expected the results of the run, got %v", o.vals)
+	}
+}
+
+func TestRunAtPassed(t *testing.T) {
+	o := newOutcomes()
+	c := newFakeClock()
+	s := New(WithHandler(o.handle), WithClock(c))
+
+	if err := s.RunAt("late", c.Now().Add(-time.Hour), done); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if err := s.Start(); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+
+	if err := o.wait(t); err != nil {
+		t.Errorf("expected a passed point in time to run right away, got %v", err)
+	}
+	if err := s.Stop(context.Background()); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+}
+
+func TestSkipOverlap(t *testing.T) {
+	o := newOutcomes()
+	c := newFakeClock()
+	s := New(WithHandler(o.handle), WithClock(c))
+
+	g := newGate()
+	if err := s.Register("slow", every(time.Minute), g.graph); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if err := s.Start(); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+
+	c.waitArmed(t)
+	c.Advance(time.Minute)
+	g.waitStarted(t)
+
+	c.waitArmed(t)
+	c.Advance(time.Minute)
+	if err := o.wait(t); !errors.Is(err, ErrSkipped) {
+		t.Errorf("expected the overlapping run to be skipped, got %v", err)
+	}
+
+	g.release <- struct{}{}
+	if err := o.wait(t); err != nil {
+		t.Errorf("didnt expect error, got %v", err)
+	}
+	if err := s.Stop(context.Background()); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if g.peak.Load() != 1 {
+		t.Errorf("expected runs to never overlap, got %d at the same time", g.peak.Load())
+	}
+}
+
+func TestAllowOverlap(t *testing.T) {
+	o := newOutcomes()
+	c := newFakeClock()
+	s := New(WithHandler(o.handle), WithClock(c))
+
+	g := newGate()
+	if err := s.Register("slow", every(time.Minute), g.graph, WithOverlapPolicy(AllowOverlap)); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if err := s.Start(); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		c.waitArmed(t)
+		c.Advance(time.Minute)
+		g.waitStarted(t)
+	}
+	if g.peak.Load() != 2 {
+		t.Errorf("expected runs to overlap, got %d at the same time", g.peak.Load())
+	}
+
+	for i := 0; i < 2; i++ {
+		g.release <- struct{}{}
+		if err := o.wait(t); err != nil {
+			t.Errorf("didnt expect error, got %v", err)
+		}
+	}
+	if err := s.Stop(context.Background()); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+}
+
+func TestQueueOverlap(t *testing.T) {
+	o := newOutcomes()
+	c := newFakeClock()
+	s := New(WithHandler(o.handle), WithClock(c))
+
+	g := newGate()
+	if err := s.Register("slow", every(time.Minute), g.graph, WithOverlapPolicy(QueueOverlap)); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if err := s.Start(); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+
+	c.waitArmed(t)
+	c.Advance(time.Minute)
+	g.waitStarted(t)
+
+	// the first overlapping run is queued, the second one is dropped
+	for i := 0; i < 2; i++ {
+		c.waitArmed(t)
+		c.Advance(time.Minute)
+	}
+	c.waitArmed(t)
+
+	g.release <- struct{}{}
+	if err := o.wait(t); err != nil {
+		t.Errorf("didnt expect error, got %v", err)
+	}
+	g.waitStarted(t)
+	g.release <- struct{}{}
+	if err := o.wait(t); err != nil {
+		t.Errorf("didnt expect error, got %v", err)
+	}
+	if err := s.Stop(context.Background()); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+
+	if g.peak.Load() != 1 {
+		t.Errorf("expected runs to never overlap, got %d at the same time", g.peak.Load())
+	}
+	if n := o.count(nil); n != 2 || n != len(o.errs) {
+		t.Errorf("expected the queued run to run after the first one, got %v", o.errs)
+	}
+}
+
+func TestReplaceOverlap(t *testing.T) {
+	o := newOutcomes()
+	c := newFakeClock()
+	s := New(WithHandler(o.handle), WithClock(c))
+
+	g := newGate()
+	if err := s.Register("slow", every(time.Minute), g.graph, WithOverlapPolicy(ReplaceOverlap)); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if err := s.Start(); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+
+	c.waitArmed(t)
+	c.Advance(time.Minute)
+	g.waitStarted(t)
+
+	c.waitArmed(t)
+	c.Advance(time.Minute)
+	if err := o.wait(t); !errors.Is(err, ErrReplaced) {
+		t.Errorf("expected the replaced run to be cancelled with ErrReplaced, got %v", err)
+	}
+	g.waitStarted(t)
+
+	if err := s.Stop(stopped()); err == nil {
+		t.Error("expected an error")
+	}
+	if err := o.wait(t); !errors.Is(err, ErrStopped) {
+		t.Errorf("expected the running graph to be cancelled with ErrStopped, got %v", err)
+	}
+}
+
+func TestStop(t *testing.T) {
+	o := newOutcomes()
+	s := New(WithHandler(o.handle), WithClock(newFakeClock()))
+
+	g := newGate()
+	if err := s.RunAfter("slow", 0, g.graph); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if err := s.Start(); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if err := s.Start(); !errors.Is(err, ErrStarted) {
+		t.Errorf("expected ErrStarted, got %v", err)
+	}
+	g.waitStarted(t)
+
+	var cerr *task.CancelledError
+	if err := s.Stop(stopped()); !errors.As(err, &cerr) {
+		t.Fatalf("expected a CancelledError, got %v", err)
+	}
+	if err := o.wait(t); !errors.Is(err, ErrStopped) {
+		t.Errorf("expected the running graph to be cancelled with ErrStopped, got %v", err)
+	}
+
+	if err := s.RunAfter("late", 0, done); !errors.Is(err, ErrStopped) {
+		t.Errorf("expected ErrStopped, got %v", err)
+	}
+}
+
+func TestMissedFiresCollapse(t *testing.T) {
+	o := newOutcomes()
+	c := newFakeClock()
+	s := New(WithHandler(o.handle), WithClock(c))
+
+	if err := s.Register("minutely", every(time.Minute), done); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if err := s.Start(); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+
+	// the process stalls for ten minutes, the late fire stands in for all missed ones
+	c.waitArmed(t)
+	c.Advance(10 * time.Minute)
+	if err := o.wait(t); err != nil {
+		t.Errorf("didnt expect error, got %v", err)
+	}
+	if next, expected := c.waitArmed(t), c.Now().Add(time.Minute); !next.Equal(expected) {
+		t.Errorf("expected the next fire at %s, got %s", expected, next)
+	}
+
+	if err := s.Stop(context.Background()); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if len(o.errs) != 1 {
+		t.Errorf("expected missed fires not to be replayed, got %d runs", len(o.errs))
+	}
+}
+
+func TestNextFire(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	schedule := every(time.Minute)
+
+	if got, expected := nextFire(schedule, start, start.Add(time.Second)), start.Add(time.Minute+time.Second); !got.Equal(expected) {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+	if got, expected := nextFire(schedule, start, start.Add(-time.Second)), start.Add(time.Minute); !got.Equal(expected) {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+}
+
+func TestRegisterDuplicate(t *testing.T) {
+	s := New()
+	defer s.Stop(context.Background())
+
+	if err := s.RegisterCron("nightly", "@daily", done); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if err := s.RegisterCron("nightly", "@daily", done); !errors.Is(err, ErrDuplicateJob) {
+		t.Errorf("expected ErrDuplicateJob, got %v", err)
+	}
+	if err := s.RegisterCron("broken", "* *", done); err == nil {
+		t.Error("expected an error")
+	}
+}
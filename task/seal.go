@@ -0,0 +1,55 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrSealed is wrapped into the error of a graph mutation involving a sealed task, see Seal.
+var ErrSealed = errors.New("task is sealed")
+
+// Seal freezes the task and all tasks reachable from it via subtasks and dependencies: AddSubtasks, DependsOn, RemoveSubtask,
+// ReplaceSubtask and InsertSubtask return an error wrapping ErrSealed instead of changing a sealed task, so a graph that is shared
+// with a running Runner can't be changed by accident while its tasks are read from other goroutines. Sealing can't be undone.
+//
+// Seal only guards the methods of Task. Assigning to the exported fields of a sealed task or applying a TaskConfigFunc to it
+// is not detected and must be avoided.
+//
+// Example usage:
+//
+//	root.Seal()
+//	go runner.Run([]*task.Task{root})
+//
+//	err := root.AddSubtasks(audit) // errors.Is(err, task.ErrSealed)
+func (t *Task) Seal() {
+	seen := map[*Task]bool{}
+	queue := []*Task{t}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		if seen[n] {
+			continue
+		}
+		seen[n] = true
+		n.sealed.Store(true)
+
+		queue = append(queue, n.Subtasks...)
+		queue = append(queue, n.Dependencies...)
+		queue = append(queue, n.dependents...)
+	}
+}
+
+// Sealed reports whether the task is sealed, see Seal.
+func (t *Task) Sealed() bool {
+	return t.sealed.Load()
+}
+
+// checkSealed returns an error wrapping ErrSealed if any of the tasks is sealed.
+func checkSealed(tasks ...*Task) error {
+	for _, t := range tasks {
+		if t != nil && t.Sealed() {
+			return fmt.Errorf("task %s: %w", t.ID, ErrSealed)
+		}
+	}
+	return nil
+}
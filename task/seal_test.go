@@ -0,0 +1,49 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestSeal(t *testing.T) {
+	ctx := context.Background()
+	root, child, dep, fresh := New(ctx), New(ctx), New(ctx), New(ctx)
+	if err := root.AddSubtasks(child); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if err := child.DependsOn(dep); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+
+	root.Seal()
+	for _, task := range []*Task{root, child, dep} {
+		if !task.Sealed() {
+			t.Errorf("expected task %s to be sealed", task.ID)
+		}
+	}
+	if fresh.Sealed() {
+		t.Errorf("didnt expect task %s to be sealed", fresh.ID)
+	}
+
+	mutations := map[string]func() error{
+		"AddSubtasks":        func() error { return root.AddSubtasks(fresh) },
+		"AddSubtasks sealed": func() error { return fresh.AddSubtasks(child) },
+		"DependsOn":          func() error { return fresh.DependsOn(dep) },
+		"RemoveSubtask":      func() error { _, err := root.RemoveSubtask(child.ID); return err },
+		"ReplaceSubtask":     func() error { return root.ReplaceSubtask(child, fresh) },
+		"InsertSubtask":      func() error { return root.InsertSubtask(0, fresh) },
+	}
+	for name, mutate := range mutations {
+		if err := mutate(); !errors.Is(err, ErrSealed) {
+			t.Errorf("%s: expected error %v, got %v", name, ErrSealed, err)
+		}
+	}
+
+	if len(root.Subtasks) != 1 || len(fresh.Subtasks) != 0 || len(fresh.Dependencies) != 0 || len(dep.dependents) != 1 {
+		t.Error("expected the sealed graph to be unchanged")
+	}
+	if MustDecodeCtx(child.Context).Parent != root {
+		t.Error("expected the context of the sealed subtask to be unchanged")
+	}
+}
@@ -0,0 +1,242 @@
+package task
+
+import (
+	"context"
+	"sync"
+)
+
+// WithSerialKey returns a TaskConfigFunc that makes the task run one at a time with every other task sharing key,
+// e.g. all operations for a single account. Within a run, tasks sharing a key never overlap, even with a concurrency greater than one.
+// Across runs, they are serialized by a SerialQueue shared by the runs, see WithSerialQueue.
+func WithSerialKey(key string) TaskConfigFunc {
+	return func(t *Task) {
+		t.SerialKey = key
+	}
+}
+
+// WithSerialQueue returns a RunnerConfigFunc that serializes tasks sharing a SerialKey across all runs of the Runner with q.
+// Runs holding tasks with the same key get their turn in the order they started, or in the order they were reserved with SerialQueue.Reserve.
+func WithSerialQueue(q *SerialQueue) RunnerConfigFunc {
+	return func(o *RunOptions) {
+		o.SerialQueue = q
+	}
+}
+
+// SerialQueue hands out turns for serial keys to runs in FIFO order. A run holds the turn of a key from the start of its first
+// task with the key until all of its tasks with the key completed or the run ended. A SerialQueue is safe for concurrent use.
+type SerialQueue struct {
+	mu     sync.Mutex
+	queues map[string][]*serialTicket
+}
+
+// serialTicket is the place of a run in the queue of a single key.
+type serialTicket struct {
+	ready    chan struct{}
+	signaled bool
+}
+
+// serialReservation holds the tickets of a single run, keyed by serial key.
+type serialReservation struct {
+	queue   *SerialQueue
+	tickets map[string]*serialTicket
+}
+
+// NewSerialQueue creates a new, empty SerialQueue.
+func NewSerialQueue() *SerialQueue {
+	return &SerialQueue{
+		queues: make(map[string][]*serialTicket),
+	}
+}
+
+// Reserve takes a place in the queue of every serial key used by tasks, their subtasks and dependencies right away
+// and returns a context carrying the reservation. A run started with the returned context by a Runner using q gets its turn in the order of the reservation
+// instead of the order in which the run started, which keeps submission order when runs are queued before they start.
+// Every reservation has to be passed to a run or to Release, otherwise later runs with the same keys wait forever.
+//
+// Example usage:
+//
+//	ctx = queue.Reserve(ctx, tasks)
+//	jobs <- job{ctx: ctx, tasks: tasks}
+func (q *SerialQueue) Reserve(ctx context.Context, tasks []*Task) context.Context {
+	return context.WithValue(ctx, CtxKey("serial"), q.reserve(tasks))
+}
+
+// Release gives up the turns reserved by Reserve in ctx that were not released by a run yet.
+func (q *SerialQueue) Release(ctx context.Context) {
+	if r, ok := ctx.Value(CtxKey("serial")).(*serialReservation); ok && r.queue == q {
+		r.release()
+	}
+}
+
+// reserve takes a place in the queue of every serial key used by the tasks.
+func (q *SerialQueue) reserve(tasks []*Task) *serialReservation {
+	r := &serialReservation{
+		queue:   q,
+		tickets: map[string]*serialTicket{},
+	}
+
+	g, err := newGraph(tasks)
+	if err != nil {
+		// the run fails on the same error before any task starts
+		return r
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, t := range g.nodes {
+		if t.SerialKey == "" || r.tickets[t.SerialKey] != nil {
+			continue
+		}
+
+		ticket := &serialTicket{ready: make(chan struct{})}
+		q.queues[t.SerialKey] = append(q.queues[t.SerialKey], ticket)
+		if len(q.queues[t.SerialKey]) == 1 {
+			ticket.signaled = true
+			close(ticket.ready)
+		}
+		r.tickets[t.SerialKey] = ticket
+	}
+	return r
+}
+
+// wait blocks until the run holding r has the turn for key or ctx is done.
+func (r *serialReservation) wait(ctx context.Context, key string) error {
+	r.queue.mu.Lock()
+	ticket := r.tickets[key]
+	r.queue.mu.Unlock()
+	if ticket == nil {
+		return nil
+	}
+
+	select {
+	case <-ticket.ready:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// releaseKey gives up the turn for key and hands it to the next run in the queue.
+func (r *serialReservation) releaseKey(key string) {
+	q := r.queue
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	ticket := r.tickets[key]
+	if ticket == nil {
+		return
+	}
+	delete(r.tickets, key)
+
+	queue := q.queues[key]
+	for i, t := range queue {
+		if t == ticket {
+			queue = append(queue[:i], queue[i+1:]...)
+			break
+		}
+	}
+	if len(queue) == 0 {
+		delete(q.queues, key)
+		return
+	}
+	q.queues[key] = queue
+
+	if next := queue[0]; !next.signaled {
+		next.signaled = true
+		close(next.ready)
+	}
+}
+
+// release gives up all turns still held by r.
+func (r *serialReservation) release() {
+	r.queue.mu.Lock()
+	keys := make([]string, 0, len(r.tickets))
+	for key := range r.tickets {
+		keys = append(keys, key)
+	}
+	r.queue.mu.Unlock()
+
+	for _, key := range keys {
+		r.releaseKey(key)
+	}
+}
+
+// serialLocks serializes the tasks of a single run sharing a serial key, and holds the turns of the run in a SerialQueue.
+type serialLocks struct {
+	reservation *serialReservation // nil without a SerialQueue
+
+	mu    sync.Mutex
+	locks map[string]chan struct{}
+	left  map[string]int // number of tasks with the key that did not complete yet
+}
+
+// newSerialLocks creates the serialLocks for the tasks of a run. If the Runner has a SerialQueue, the reservation in ctx is used or a new one is made.
+func (s *run) newSerialLocks(ctx context.Context, tasks []*Task) *serialLocks {
+	l := &serialLocks{
+		locks: map[string]chan struct{}{},
+		left:  map[string]int{},
+	}
+	for _, t := range tasks {
+		if t.SerialKey == "" {
+			continue
+		}
+		if l.left[t.SerialKey] == 0 {
+			l.locks[t.SerialKey] = make(chan struct{}, 1)
+		}
+		l.left[t.SerialKey]++
+	}
+
+	if q := s.runner.Options.SerialQueue; q != nil && len(l.left) > 0 {
+		if r, ok := ctx.Value(CtxKey("serial")).(*serialReservation); ok && r.queue == q {
+			l.reservation = r
+		} else {
+			l.reservation = q.reserve(tasks)
+		}
+	}
+	return l
+}
+
+// acquire blocks until the task may run with respect to its serial key or ctx is done.
+func (l *serialLocks) acquire(ctx context.Context, task *Task) error {
+	if task.SerialKey == "" {
+		return nil
+	}
+
+	if l.reservation != nil {
+		if err := l.reservation.wait(ctx, task.SerialKey); err != nil {
+			return err
+		}
+	}
+
+	select {
+	case l.locks[task.SerialKey] <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release lets the next task with the serial key of task run, and gives up the turn of the run once all tasks with the key completed.
+func (l *serialLocks) release(task *Task) {
+	if task.SerialKey == "" {
+		return
+	}
+	<-l.locks[task.SerialKey]
+
+	l.mu.Lock()
+	l.left[task.SerialKey]--
+	done := l.left[task.SerialKey] == 0
+	l.mu.Unlock()
+
+	if done && l.reservation != nil {
+		l.reservation.releaseKey(task.SerialKey)
+	}
+}
+
+// close gives up all turns still held by the run, e.g. for tasks that never ran because the run failed.
+func (l *serialLocks) close() {
+	if l.reservation != nil {
+		l.reservation.release()
+	}
+}
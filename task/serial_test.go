@@ -0,0 +1,100 @@
+package task
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSerialKeyWithinRun(t *testing.T) {
+	var running, overlaps atomic.Int64
+	fn := func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		if running.Add(1) > 1 {
+			overlaps.Add(1)
+		}
+		time.Sleep(time.Millisecond)
+		running.Add(-1)
+		return nil, nil
+	}
+
+	tasks := make([]*Task, 0, 4)
+	for i := 0; i < 4; i++ {
+		tasks = append(tasks, New(context.Background(), WithFunc(fn), WithSerialKey("account-1")))
+	}
+
+	if _, err := NewRunner(WithConcurrency(4)).Run(tasks); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if overlaps.Load() > 0 {
+		t.Errorf("expected tasks sharing a key to run one at a time, got %d overlaps", overlaps.Load())
+	}
+}
+
+func TestSerialQueueOrder(t *testing.T) {
+	q := NewSerialQueue()
+	runner := NewRunner(WithSerialQueue(q))
+
+	var mu sync.Mutex
+	var order []int
+	graph := func(i int) []*Task {
+		return []*Task{New(context.Background(), WithSerialKey("account-1"), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+			mu.Lock()
+			order = append(order, i)
+			mu.Unlock()
+			return nil, nil
+		}))}
+	}
+
+	// reserve in order, start in reverse order
+	ctxs := make([]context.Context, 5)
+	graphs := make([][]*Task, 5)
+	for i := range graphs {
+		graphs[i] = graph(i)
+		ctxs[i] = q.Reserve(context.Background(), graphs[i])
+	}
+
+	var wg sync.WaitGroup
+	for i := len(graphs) - 1; i >= 0; i-- {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, err := runner.RunCtx(ctxs[i], graphs[i]); err != nil {
+				t.Errorf("didnt expect error, got %v", err)
+			}
+		}(i)
+		time.Sleep(time.Millisecond)
+	}
+	wg.Wait()
+
+	for i, v := range order {
+		if v != i {
+			t.Fatalf("expected runs in reservation order, got %v", order)
+		}
+	}
+}
+
+func TestSerialQueueReleaseOnFailure(t *testing.T) {
+	q := NewSerialQueue()
+	runner := NewRunner(WithSerialQueue(q))
+
+	failing := New(context.Background(), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, context.Canceled
+	}))
+	failing.AddSubtasks(New(context.Background(), WithSerialKey("account-1")))
+
+	if _, err := runner.Run([]*Task{failing}); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	next := New(context.Background(), WithSerialKey("account-1"), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, nil
+	}))
+	if _, err := runner.RunCtx(ctx, []*Task{next}); err != nil {
+		t.Errorf("expected the turn of the failed run to be released, got %v", err)
+	}
+}
@@ -0,0 +1,173 @@
+package task
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// Result represents the outcome of a single task.
+//
+// Members:
+// - TaskID: the ID of the task
+// - Name: the name of the task, see WithName
+// - Value: the value returned by the task, nil if the task failed
+// - Err: the error returned by the task, nil if the task succeeded
+type Result struct {
+	TaskID string
+	Name   string
+	Value  interface{}
+	Err    error
+}
+
+// Sink receives the result of each task as soon as the task completes, so consumers don't have to wait for Run to return.
+// If Write returns an error for a successful task, the run fails with that error and is reverted like after a task failure,
+// as the result could not be handed over. Errors returned for failed tasks are ignored because the run is already failing.
+type Sink interface {
+	Write(ctx context.Context, r Result) error
+}
+
+// SinkFunc is an adapter to allow the use of ordinary functions as a Sink.
+type SinkFunc func(ctx context.Context, r Result) error
+
+// Write calls f(ctx, r).
+func (f SinkFunc) Write(ctx context.Context, r Result) error {
+	return f(ctx, r)
+}
+
+// WithSinks returns a RunnerConfigFunc that adds sinks receiving the result of every task of every run. See ContextWithSinks for sinks of a single run.
+func WithSinks(sinks ...Sink) RunnerConfigFunc {
+	return func(o *RunOptions) {
+		o.Sinks = append(o.Sinks, sinks...)
+	}
+}
+
+// ContextWithSinks returns a copy of ctx carrying sinks that receive the result of every task of a run started with it,
+// in addition to the sinks of the Runner and the sinks already carried by ctx.
+//
+// Example usage:
+//
+//	ctx = task.ContextWithSinks(ctx, task.NewCSVSink(file))
+//	results, err := runner.RunCtx(ctx, tasks)
+func ContextWithSinks(ctx context.Context, sinks ...Sink) context.Context {
+	return context.WithValue(ctx, CtxKey("sinks"), append(contextSinks(ctx), sinks...))
+}
+
+// contextSinks returns the sinks carried by ctx. The returned slice has no spare capacity, so appending to it copies it.
+func contextSinks(ctx context.Context) []Sink {
+	sinks, _ := ctx.Value(CtxKey("sinks")).([]Sink)
+	return sinks[:len(sinks):len(sinks)]
+}
+
+// deliver writes the result of a task to all sinks of the run, stopping at the first error. The sinks are called with the context
+// of the run, so cancelling the run cancels writes in flight.
+func (s *run) deliver(task *Task, val interface{}, err error) error {
+	res := Result{
+		TaskID: task.ID,
+		Name:   task.Name,
+		Value:  val,
+		Err:    err,
+	}
+
+	for _, sink := range s.sinks {
+		if err := sink.Write(s.ctx, res); err != nil {
+			return fmt.Errorf("sink result of task %s: %w", task.ID, err)
+		}
+	}
+	return nil
+}
+
+// CSVSink is a Sink appending one record per result to a CSV file. The records contain the task ID,
+// the value formatted with fmt.Sprint and the error message. It is safe for concurrent use.
+type CSVSink struct {
+	mu sync.Mutex
+	w  *csv.Writer
+}
+
+// NewCSVSink creates a new CSVSink writing to w.
+func NewCSVSink(w io.Writer) *CSVSink {
+	return &CSVSink{
+		w: csv.NewWriter(w),
+	}
+}
+
+// Write implements the Sink interface. The record is flushed immediately.
+func (s *CSVSink) Write(_ context.Context, r Result) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	value, errMsg := "", ""
+	if r.Value != nil {
+		value = fmt.Sprint(r.Value)
+	}
+	if r.Err != nil {
+		errMsg = r.Err.Error()
+	}
+
+	if err := s.w.Write([]string{r.TaskID, value, errMsg}); err != nil {
+		return err
+	}
+	s.w.Flush()
+	return s.w.Error()
+}
+
+// HTTPSink is a Sink posting every result as JSON to an endpoint. The body has the form
+// {"task_id": "task_1", "value": ..., "error": "..."}, the value is encoded with encoding/json.
+type HTTPSink struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewHTTPSink creates a new HTTPSink posting to url. If client is nil, http.DefaultClient is used.
+func NewHTTPSink(url string, client *http.Client) *HTTPSink {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &HTTPSink{
+		URL:    url,
+		Client: client,
+	}
+}
+
+// Write implements the Sink interface. Every response status outside of 2xx is treated as an error.
+func (s *HTTPSink) Write(ctx context.Context, r Result) error {
+	payload := struct {
+		TaskID string      `json:"task_id"`
+		Value  interface{} `json:"value,omitempty"`
+		Error  string      `json:"error,omitempty"`
+	}{
+		TaskID: r.TaskID,
+		Value:  r.Value,
+	}
+	if r.Err != nil {
+		payload.Error = r.Err.Error()
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package task
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSinks(t *testing.T) {
+	var got []Result
+	sink := SinkFunc(func(ctx context.Context, r Result) error {
+		got = append(got, r)
+		return nil
+	})
+
+	task := New(context.Background(), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return 1, nil
+	}))
+	task.AddSubtasks(New(context.Background(), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, errors.New("foobar")
+	})))
+
+	if _, err := NewRunner(WithSinks(sink)).Run([]*Task{task}); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(got))
+	}
+	if got[0].TaskID != task.ID || got[0].Value != 1 || got[0].Err != nil {
+		t.Errorf("unexpected first result %+v", got[0])
+	}
+	if got[1].Err == nil {
+		t.Error("expected second result to carry the error")
+	}
+}
+
+func TestSinkErrorReverts(t *testing.T) {
+	reverted := false
+	task := New(context.Background(), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, nil
+	}), WithRevertFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		reverted = true
+		return nil, nil
+	}))
+
+	sink := SinkFunc(func(ctx context.Context, r Result) error {
+		return errors.New("sink unavailable")
+	})
+
+	if _, err := NewRunner(WithSinks(sink)).Run([]*Task{task}); err == nil {
+		t.Fatal("expected an error")
+	}
+	if !reverted {
+		t.Error("expected task to be reverted")
+	}
+}
+
+func TestContextWithSinks(t *testing.T) {
+	var runnerResults, runResults int
+	runner := NewRunner(WithSinks(SinkFunc(func(ctx context.Context, r Result) error {
+		runnerResults++
+		return nil
+	})))
+	ctx := ContextWithSinks(context.Background(), SinkFunc(func(ctx context.Context, r Result) error {
+		runResults++
+		return nil
+	}))
+
+	if _, err := runner.RunCtx(ctx, []*Task{New(context.Background(), WithFunc(noop))}); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if _, err := runner.Run([]*Task{New(context.Background(), WithFunc(noop))}); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if runnerResults != 2 {
+		t.Errorf("expected the sink of the runner to receive %d results, got %d", 2, runnerResults)
+	}
+	if runResults != 1 {
+		t.Errorf("expected the sink of the run to receive %d result, got %d", 1, runResults)
+	}
+	if len(runner.Options.Sinks) != 1 {
+		t.Errorf("expected the runner to keep %d sink, got %d", 1, len(runner.Options.Sinks))
+	}
+}
+
+func TestSinkRunContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	sink := SinkFunc(func(ctx context.Context, r Result) error {
+		cancel()
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	_, err := NewRunner(WithSinks(sink)).RunCtx(ctx, []*Task{New(context.Background(), WithFunc(noop))})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the sink to be cancelled together with the run, got %v", err)
+	}
+}
+
+func TestCSVSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewCSVSink(&buf)
+
+	if err := sink.Write(context.Background(), Result{TaskID: "task_1", Value: 42}); err != nil {
+		t.Fatal("didnt expect error")
+	}
+	if err := sink.Write(context.Background(), Result{TaskID: "task_2", Err: errors.New("failed, badly")}); err != nil {
+		t.Fatal("didnt expect error")
+	}
+
+	expected := "task_1,42,\ntask_2,,\"failed, badly\"\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestHTTPSink(t *testing.T) {
+	var payload map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Error("expected a json body")
+		}
+		if strings.Contains(r.URL.Path, "fail") {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	sink := NewHTTPSink(srv.URL, nil)
+	if err := sink.Write(context.Background(), Result{TaskID: "task_1", Value: "done"}); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if payload["task_id"] != "task_1" || payload["value"] != "done" {
+		t.Errorf("unexpected payload %v", payload)
+	}
+
+	sink = NewHTTPSink(srv.URL+"/fail", srv.Client())
+	if err := sink.Write(context.Background(), Result{TaskID: "task_1"}); err == nil {
+		t.Error("expected an error")
+	}
+}
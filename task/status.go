@@ -0,0 +1,181 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Status is the status of a task in its latest run.
+type Status int32
+
+const (
+	// Pending means the task has not started yet.
+	Pending Status = iota
+	// Running means the task is running, including waiting for its retries.
+	Running
+	// Succeeded means the task completed, or its output was restored from a checkpoint or reused by its idempotency key.
+	Succeeded
+	// Failed means the task failed after all of its retries.
+	Failed
+	// Reverted means the task succeeded and its Revert function was called successfully because the run failed.
+	Reverted
+	// Skipped means the task never started because the run failed before it was due or its condition returned false, see WithCondition.
+	Skipped
+	// Cancelled means the task was cancelled while it was running, because the run was cancelled, a sibling failed or it timed out.
+	Cancelled
+)
+
+// String returns a human readable representation of the Status.
+func (s Status) String() string {
+	switch s {
+	case Pending:
+		return "pending"
+	case Running:
+		return "running"
+	case Succeeded:
+		return "succeeded"
+	case Failed:
+		return "failed"
+	case Reverted:
+		return "reverted"
+	case Skipped:
+		return "skipped"
+	case Cancelled:
+		return "cancelled"
+	default:
+		return fmt.Sprintf("Status(%d)", int32(s))
+	}
+}
+
+// Status returns the status of the task in its latest run. It is safe to call while the task is running.
+// A task that is part of several runs at the same time reports the status of whichever run changed it last.
+func (t *Task) Status() Status {
+	return Status(t.status.Load())
+}
+
+// setStatus sets the status of the task.
+func (t *Task) setStatus(s Status) {
+	t.status.Store(int32(s))
+}
+
+// completedStatus returns the status of a task that completed with err.
+func completedStatus(err error) Status {
+	var cerr *CancelledError
+	switch {
+	case err == nil:
+		return Succeeded
+	case errors.As(err, &cerr):
+		return Cancelled
+	default:
+		return Failed
+	}
+}
+
+// TaskSnapshot is the status of a single task at the time of a snapshot.
+//
+// Members:
+// - ID: the ID of the task
+// - Name: the name of the task
+// - Status: the status of the task
+type TaskSnapshot struct {
+	ID     string
+	Name   string
+	Status Status
+}
+
+// RunSnapshot is the status of every task of a run at the time of a snapshot.
+//
+// Members:
+// - RunID: the ID of the run, see Results.RunID
+// - GraphID: the graph ID passed to Runner.Resume, empty for other runs
+// - Started: the point in time the run started
+// - Tasks: the tasks of the run in the order they were discovered, starting with the tasks passed to the run
+type RunSnapshot struct {
+	RunID   string
+	GraphID string
+	Started time.Time
+	Tasks   []TaskSnapshot
+}
+
+// Snapshot returns the status of every task of the runs the Runner is currently executing, in the order they were started.
+// It is safe to call at any time, e.g. from a handler serving a dashboard. Runs of a Runner not created with NewRunner are not tracked.
+//
+// Example usage:
+//
+//	for _, run := range runner.Snapshot() {
+//		for _, t := range run.Tasks {
+//			fmt.Printf("%s %s\n", t.Name, t.Status)
+//		}
+//	}
+func (r *Runner) Snapshot() []RunSnapshot {
+	return r.active.snapshot()
+}
+
+// activeRuns keeps track of the runs a Runner is executing.
+type activeRuns struct {
+	mu   sync.Mutex
+	runs []*run
+}
+
+// add starts tracking a run.
+func (a *activeRuns) add(s *run) {
+	if a == nil {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.runs = append(a.runs, s)
+}
+
+// remove stops tracking a run.
+func (a *activeRuns) remove(s *run) {
+	if a == nil {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for i, other := range a.runs {
+		if other == s {
+			a.runs = append(a.runs[:i], a.runs[i+1:]...)
+			return
+		}
+	}
+}
+
+// snapshot returns the status of every task of the tracked runs.
+func (a *activeRuns) snapshot() []RunSnapshot {
+	if a == nil {
+		return nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	snapshots := make([]RunSnapshot, 0, len(a.runs))
+	for _, s := range a.runs {
+		snapshots = append(snapshots, s.snapshot())
+	}
+	return snapshots
+}
+
+// snapshot returns the status of every task of the run. It has no tasks until the graph of the run was built.
+func (s *run) snapshot() RunSnapshot {
+	s.mu.Lock()
+	nodes := s.nodes
+	s.mu.Unlock()
+
+	snapshot := RunSnapshot{
+		RunID:   s.id,
+		GraphID: s.graphID,
+		Started: s.started,
+		Tasks:   make([]TaskSnapshot, 0, len(nodes)),
+	}
+	for _, t := range nodes {
+		snapshot.Tasks = append(snapshot.Tasks, TaskSnapshot{ID: t.ID, Name: t.Name, Status: t.Status()})
+	}
+	return snapshot
+}
@@ -0,0 +1,123 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStatus(t *testing.T) {
+	ctx := context.Background()
+	ok := func(name string) *Task {
+		return New(ctx, WithName(name), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+			return name, nil
+		}), WithRevertFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+			return nil, nil
+		}))
+	}
+
+	root := ok("root")
+	irreversible := New(ctx, WithName("irreversible"), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, nil
+	}))
+	failing := New(ctx, WithName("failing"), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, errors.New("foobar")
+	}))
+	never := ok("never")
+	root.AddSubtasks(irreversible, failing)
+	failing.AddSubtasks(never)
+
+	if root.Status() != Pending {
+		t.Errorf("expected %s before the run, got %s", Pending, root.Status())
+	}
+	if _, err := Run([]*Task{root}); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	expected := map[*Task]Status{
+		root:         Reverted,
+		irreversible: Succeeded,
+		failing:      Failed,
+		never:        Skipped,
+	}
+	for task, status := range expected {
+		if task.Status() != status {
+			t.Errorf("expected task %s to be %s, got %s", task.Name, status, task.Status())
+		}
+	}
+}
+
+func TestStatusCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	task := New(context.Background(), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		cancel()
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}))
+
+	if _, err := RunCtx(ctx, []*Task{task}); err == nil {
+		t.Fatal("expected an error")
+	}
+	if task.Status() != Cancelled {
+		t.Errorf("expected %s, got %s", Cancelled, task.Status())
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	runner := NewRunner(WithConcurrency(2))
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	slow := New(context.Background(), WithName("slow"), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		close(started)
+		<-release
+		return nil, nil
+	}))
+	fast := New(context.Background(), WithName("fast"), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, nil
+	}))
+	after := New(context.Background(), WithName("after"), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, nil
+	}))
+	after.DependsOn(slow, fast)
+
+	done := make(chan error)
+	go func() {
+		_, err := runner.Run([]*Task{slow, fast})
+		done <- err
+	}()
+
+	<-started
+	var snapshots []RunSnapshot
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		snapshots = runner.Snapshot()
+		if len(snapshots) == 1 && fast.Status() == Succeeded {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if len(snapshots) != 1 {
+		t.Fatalf("expected a snapshot of %d run, got %d", 1, len(snapshots))
+	}
+	statuses := map[string]Status{}
+	for _, task := range snapshots[0].Tasks {
+		statuses[task.Name] = task.Status
+	}
+	expected := map[string]Status{"slow": Running, "fast": Succeeded, "after": Pending}
+	for name, status := range expected {
+		if statuses[name] != status {
+			t.Errorf("expected task %s to be %s, got %s", name, status, statuses[name])
+		}
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if snapshots := runner.Snapshot(); len(snapshots) != 0 {
+		t.Errorf("expected no runs after the run completed, got %d", len(snapshots))
+	}
+}
@@ -0,0 +1,47 @@
+package task
+
+import (
+	"context"
+)
+
+// RunStream executes a list of tasks like Run, but returns a channel emitting the Result of every task as soon as it completes,
+// so callers can process the outputs of large graphs incrementally. See Runner.RunStream.
+//
+// Example usage:
+//
+//	for res := range task.RunStream(tasks) {
+//		if res.Err != nil {
+//			log.Printf("task %s failed: %v", res.Name, res.Err)
+//			continue
+//		}
+//		process(res.Value)
+//	}
+func RunStream(tasks []*Task, values ...interface{}) <-chan Result {
+	return NewRunner().RunStream(context.Background(), tasks, values...)
+}
+
+// RunStream executes the tasks like RunCtx on a goroutine of its own and returns a channel emitting the Result of every task
+// as soon as it completes, in completion order, in addition to the sinks of the Runner and those carried by ctx, see ContextWithSinks.
+// The channel is closed once the run returned.
+//
+// The channel is unbuffered, so a slow consumer holds up the run instead of results piling up in memory. Consumers have to drain
+// the channel or cancel ctx: the run then fails and is reverted like after a cancellation. Failed tasks are emitted with their error,
+// but the error of the run itself, e.g. a *RevertError, is not; use Submit if it matters.
+func (r *Runner) RunStream(ctx context.Context, tasks []*Task, values ...interface{}) <-chan Result {
+	ch := make(chan Result)
+
+	runCtx := ContextWithSinks(ctx, SinkFunc(func(_ context.Context, res Result) error {
+		select {
+		case ch <- res:
+			return nil
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		}
+	}))
+
+	go func() {
+		defer close(ch)
+		_, _ = r.RunCtx(runCtx, tasks, values...)
+	}()
+	return ch
+}
@@ -0,0 +1,81 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRunStream(t *testing.T) {
+	ctx := context.Background()
+	first := New(ctx, WithName("first"), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return 1, nil
+	}))
+	second := New(ctx, WithName("second"), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return 2, nil
+	}))
+	if err := first.AddSubtasks(second); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+
+	var results []Result
+	for res := range RunStream([]*Task{first}) {
+		results = append(results, res)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("expected %d results, got %d", 2, len(results))
+	}
+	for i, name := range []string{"first", "second"} {
+		if results[i].Name != name {
+			t.Errorf("expected result %d of %q, got %q", i, name, results[i].Name)
+		}
+		if results[i].Value != i+1 {
+			t.Errorf("expected value %d, got %v", i+1, results[i].Value)
+		}
+		if results[i].Err != nil {
+			t.Errorf("didnt expect error, got %v", results[i].Err)
+		}
+	}
+}
+
+func TestRunStreamFailure(t *testing.T) {
+	boom := errors.New("boom")
+	fail := New(context.Background(), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, boom
+	}))
+
+	var results []Result
+	for res := range RunStream([]*Task{fail}) {
+		results = append(results, res)
+	}
+
+	if len(results) != 1 {
+		t.Fatalf("expected %d results, got %d", 1, len(results))
+	}
+	if !errors.Is(results[0].Err, boom) {
+		t.Errorf("expected %v, got %v", boom, results[0].Err)
+	}
+}
+
+func TestRunStreamCancel(t *testing.T) {
+	reverted := make(chan struct{})
+	first := New(context.Background(), WithFunc(noop), WithRevertFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		close(reverted)
+		return nil, nil
+	}))
+	second := New(context.Background(), WithFunc(noop))
+	if err := first.AddSubtasks(second); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := NewRunner().RunStream(ctx, []*Task{first})
+	<-ch
+	cancel()
+
+	// the run is reverted once the stream could not hand over the second result
+	<-reverted
+	for range ch {
+	}
+}
@@ -0,0 +1,60 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+)
+
+// MutatingTag is the tag of tasks with side effects, see WithRequireRevert.
+const MutatingTag = "mutating"
+
+// ErrRevertRequired is wrapped into the error of a run refused because a mutating task has neither a Revert function nor is idempotent, see WithRequireRevert.
+var ErrRevertRequired = errors.New("revert required")
+
+// WithIdempotent returns a TaskConfigFunc that declares the task idempotent: running it again has no further side effects,
+// so it doesn't need a Revert function even if it is tagged with MutatingTag.
+func WithIdempotent() TaskConfigFunc {
+	return func(t *Task) {
+		t.Idempotent = true
+	}
+}
+
+// WithRequireRevert returns a RunnerConfigFunc that refuses to run graphs containing a task tagged with MutatingTag that has
+// neither a Revert function nor is declared idempotent with WithIdempotent, so a failed run never leaves side effects behind
+// that can't be compensated. The run fails before any task started with an error wrapping ErrRevertRequired for every such task.
+//
+// Example usage:
+//
+//	runner := task.NewRunner(task.WithRequireRevert())
+//	charge := task.New(ctx, task.WithTags(task.MutatingTag), task.WithFunc(chargeCard), task.WithRevertFunc(refundCard))
+func WithRequireRevert() RunnerConfigFunc {
+	return func(o *RunOptions) {
+		o.RequireRevert = true
+	}
+}
+
+// checkRevertible returns an error if RequireRevert is set and any of the tasks is mutating without being revertible or idempotent.
+func (r *Runner) checkRevertible(tasks []*Task) error {
+	if !r.Options.RequireRevert {
+		return nil
+	}
+
+	var errs []error
+	for _, t := range tasks {
+		if t.Revert != nil || t.Idempotent || !t.hasTag(MutatingTag) {
+			continue
+		}
+		errs = append(errs, fmt.Errorf("task %s is mutating: %w", t.ID, ErrRevertRequired))
+	}
+	return errors.Join(errs...)
+}
+
+// hasTag reports whether the task is tagged with tag.
+func (t *Task) hasTag(tag string) bool {
+	for _, other := range t.Tags {
+		if other == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,41 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRequireRevert(t *testing.T) {
+	ctx := context.Background()
+	ran := false
+	run := func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		ran = true
+		return nil, nil
+	}
+
+	revertible := New(ctx, WithTags(MutatingTag), WithFunc(run), WithRevertFunc(run))
+	idempotent := New(ctx, WithTags(MutatingTag), WithFunc(run), WithIdempotent())
+	readOnly := New(ctx, WithFunc(run))
+	unsafe := New(ctx, WithTags("payments", MutatingTag), WithFunc(run))
+	revertible.AddSubtasks(idempotent, readOnly)
+
+	runner := NewRunner(WithRequireRevert())
+	if _, err := runner.Run([]*Task{revertible}); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+
+	ran = false
+	readOnly.AddSubtasks(unsafe)
+	_, err := runner.Run([]*Task{revertible})
+	if !errors.Is(err, ErrRevertRequired) {
+		t.Fatalf("expected error %v, got %v", ErrRevertRequired, err)
+	}
+	if ran {
+		t.Error("expected no task to run")
+	}
+
+	if _, err := NewRunner().Run([]*Task{revertible}); err != nil {
+		t.Fatalf("didnt expect error without RequireRevert, got %v", err)
+	}
+}
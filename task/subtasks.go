@@ -0,0 +1,168 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrSubtaskNotFound is wrapped into the error of a graph mutation referring to a task that is not a subtask of the task it is called on.
+	ErrSubtaskNotFound = errors.New("subtask not found")
+	// ErrDuplicateSubtask is wrapped into the error of a graph mutation adding a task that already is a subtask of the task it is called on.
+	ErrDuplicateSubtask = errors.New("duplicate subtask")
+)
+
+// RemoveSubtask removes the subtask with the given ID, together with its own subtasks, from the task and returns it,
+// so graphs assembled from specs or templates can be adjusted before they run. The removed task is detached from the graph like in ReplaceSubtask,
+// so it is not executed through its dependencies or dependents either.
+// It returns an error wrapping ErrSubtaskNotFound if the task has no subtask with that ID. Like all mutations, it fails with an error
+// wrapping ErrSealed on a sealed task, see Seal.
+//
+// Example usage:
+//
+//	if !order.NeedsShipping {
+//		if _, err := checkout.RemoveSubtask(ship.ID); err != nil {
+//			return err
+//		}
+//	}
+func (t *Task) RemoveSubtask(id string) (*Task, error) {
+	if err := checkSealed(t); err != nil {
+		return nil, err
+	}
+
+	for i, st := range t.Subtasks {
+		if st.ID == id {
+			if err := st.checkDetachable(); err != nil {
+				return nil, err
+			}
+			t.Subtasks = append(t.Subtasks[:i:i], t.Subtasks[i+1:]...)
+			st.detach()
+			return st, nil
+		}
+	}
+	return nil, fmt.Errorf("task %s: %w: %s", t.ID, ErrSubtaskNotFound, id)
+}
+
+// ReplaceSubtask replaces the subtask old of the task with st at the same position. st receives a context derived from the task like in AddSubtasks,
+// the subtasks of old are not carried over. old is detached from the graph: its dependencies and dependents no longer refer to it, and
+// its TaskContext no longer has a Parent. It returns an error wrapping ErrSubtaskNotFound if old is not a subtask of the task,
+// one wrapping ErrDuplicateSubtask if st already is, and a *CycleError if st leads back to the task.
+func (t *Task) ReplaceSubtask(old, st *Task) error {
+	for i, other := range t.Subtasks {
+		if other != old {
+			continue
+		}
+		if err := t.validateSubtask(st); err != nil {
+			return err
+		}
+		if err := old.checkDetachable(); err != nil {
+			return err
+		}
+
+		t.Subtasks[i] = st
+		t.adopt(st)
+		old.detach()
+		return nil
+	}
+	return fmt.Errorf("task %s: %w: %s", t.ID, ErrSubtaskNotFound, taskID(old))
+}
+
+// InsertSubtask inserts st into the subtasks of the task at index i, 0 <= i <= len(t.Subtasks), so it is started before the subtasks following it
+// when they are ready at the same time. st receives a context derived from the task like in AddSubtasks. It returns an error wrapping
+// ErrDuplicateSubtask if st already is a subtask of the task and a *CycleError if st leads back to the task.
+func (t *Task) InsertSubtask(i int, st *Task) error {
+	if i < 0 || i > len(t.Subtasks) {
+		return fmt.Errorf("task %s: subtask index %d out of range [0, %d]", t.ID, i, len(t.Subtasks))
+	}
+	if err := t.validateSubtask(st); err != nil {
+		return err
+	}
+
+	t.Subtasks = append(t.Subtasks[:i:i], append([]*Task{st}, t.Subtasks[i:]...)...)
+	t.adopt(st)
+	return nil
+}
+
+// validateSubtask checks that st can become a subtask of the task.
+func (t *Task) validateSubtask(st *Task) error {
+	if st == nil {
+		return fmt.Errorf("task %s: nil subtask", t.ID)
+	}
+	if err := checkSealed(t, st); err != nil {
+		return err
+	}
+	for _, other := range t.Subtasks {
+		if other == st {
+			return fmt.Errorf("task %s: %w: %s", t.ID, ErrDuplicateSubtask, st.ID)
+		}
+	}
+	if st == t || reaches(st, t) {
+		return &CycleError{Tasks: []*Task{t, st}}
+	}
+	return nil
+}
+
+// adopt derives the context of the subtask st from the context of the task, like AddSubtasks.
+func (t *Task) adopt(st *Task) {
+	st.Context = context.WithValue(t.Context, CtxKey("ctx"), &TaskContext{
+		Task:   st,
+		Parent: t,
+	})
+}
+
+// checkDetachable returns an error wrapping ErrSealed if the task or one of the tasks detach modifies is sealed.
+func (t *Task) checkDetachable() error {
+	return checkSealed(append(append([]*Task{t}, t.Dependencies...), t.dependents...)...)
+}
+
+// detach removes the dependency edges of the task in both directions and resets its TaskContext, so it no longer refers to a graph.
+func (t *Task) detach() {
+	for _, dep := range t.Dependencies {
+		dep.dependents = without(dep.dependents, t)
+	}
+	for _, dependent := range t.dependents {
+		dependent.Dependencies = without(dependent.Dependencies, t)
+	}
+	t.Dependencies, t.dependents = nil, nil
+	t.Context = context.WithValue(t.Context, CtxKey("ctx"), &TaskContext{Task: t})
+}
+
+// without returns tasks without any occurrence of t, reusing the backing array of tasks.
+func without(tasks []*Task, t *Task) []*Task {
+	out := tasks[:0]
+	for _, other := range tasks {
+		if other != t {
+			out = append(out, other)
+		}
+	}
+	return out
+}
+
+// reaches reports whether target is reachable from t via subtasks and dependents, the edges along which tasks are started.
+func reaches(t, target *Task) bool {
+	seen := map[*Task]bool{}
+	queue := []*Task{t}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		if n == target {
+			return true
+		}
+		if seen[n] {
+			continue
+		}
+		seen[n] = true
+		queue = append(queue, n.Subtasks...)
+		queue = append(queue, n.dependents...)
+	}
+	return false
+}
+
+// taskID returns the ID of t, or "<nil>" if t is nil.
+func taskID(t *Task) string {
+	if t == nil {
+		return "<nil>"
+	}
+	return t.ID
+}
@@ -0,0 +1,144 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRemoveSubtask(t *testing.T) {
+	ctx := context.Background()
+	root, foo, bar := New(ctx), New(ctx), New(ctx)
+	root.AddSubtasks(foo, bar)
+
+	removed, err := root.RemoveSubtask(foo.ID)
+	if err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if removed != foo || len(root.Subtasks) != 1 || root.Subtasks[0] != bar {
+		t.Errorf("expected only %s to remain, got %v", bar.ID, root.Subtasks)
+	}
+
+	if _, err := root.RemoveSubtask(foo.ID); !errors.Is(err, ErrSubtaskNotFound) {
+		t.Errorf("expected error %v, got %v", ErrSubtaskNotFound, err)
+	}
+}
+
+func TestRemoveSubtaskNotExecuted(t *testing.T) {
+	ctx := context.Background()
+	shipped := false
+	root, a := New(ctx, WithFunc(noop)), New(ctx, WithFunc(noop))
+	ship := New(ctx, WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		shipped = true
+		return nil, nil
+	}))
+	for _, err := range []error{root.AddSubtasks(a, ship), ship.DependsOn(a)} {
+		if err != nil {
+			t.Fatalf("didnt expect error, got %v", err)
+		}
+	}
+
+	if _, err := root.RemoveSubtask(ship.ID); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if _, err := Run([]*Task{root}); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if shipped {
+		t.Error("expected the removed subtask not to be executed")
+	}
+}
+
+func TestReplaceSubtask(t *testing.T) {
+	ctx := context.Background()
+	root, foo, bar, baz := New(ctx), New(ctx), New(ctx), New(ctx)
+	root.AddSubtasks(foo, bar)
+
+	if err := root.ReplaceSubtask(foo, baz); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if root.Subtasks[0] != baz || root.Subtasks[1] != bar {
+		t.Errorf("expected %s to take the place of %s, got %v", baz.ID, foo.ID, root.Subtasks)
+	}
+	if tc := MustDecodeCtx(baz.Context); tc.Parent != root || tc.Task != baz {
+		t.Errorf("expected the context of %s to name %s as parent", baz.ID, root.ID)
+	}
+
+	if err := root.ReplaceSubtask(foo, New(ctx)); !errors.Is(err, ErrSubtaskNotFound) {
+		t.Errorf("expected error %v, got %v", ErrSubtaskNotFound, err)
+	}
+	if err := root.ReplaceSubtask(baz, bar); !errors.Is(err, ErrDuplicateSubtask) {
+		t.Errorf("expected error %v, got %v", ErrDuplicateSubtask, err)
+	}
+
+	var cerr *CycleError
+	if err := baz.ReplaceSubtask(nil, root); err == nil {
+		t.Error("expected an error")
+	}
+	baz.AddSubtasks(foo)
+	if err := baz.ReplaceSubtask(foo, root); !errors.As(err, &cerr) {
+		t.Errorf("expected a *CycleError, got %v", err)
+	}
+}
+
+func TestReplaceSubtaskDetaches(t *testing.T) {
+	ctx := context.Background()
+	root, old, st, dep, dependent := New(ctx), New(ctx), New(ctx), New(ctx), New(ctx)
+	for _, err := range []error{root.AddSubtasks(old), old.DependsOn(dep), dependent.DependsOn(old)} {
+		if err != nil {
+			t.Fatalf("didnt expect error, got %v", err)
+		}
+	}
+
+	if err := root.ReplaceSubtask(old, st); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if tc := MustDecodeCtx(old.Context); tc.Parent != nil || tc.Task != old {
+		t.Errorf("expected the context of %s to have no parent, got %v", old.ID, tc.Parent)
+	}
+	if len(old.Dependencies) != 0 || len(old.dependents) != 0 {
+		t.Errorf("expected %s to have no dependencies and dependents, got %v and %v", old.ID, old.Dependencies, old.dependents)
+	}
+	if len(dep.dependents) != 0 || len(dependent.Dependencies) != 0 {
+		t.Errorf("expected %s to be removed from the graph, got %v and %v", old.ID, dep.dependents, dependent.Dependencies)
+	}
+}
+
+func TestInsertSubtask(t *testing.T) {
+	ctx := context.Background()
+	var order []string
+	f := func(name string) TaskConfigFunc {
+		return WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+			order = append(order, name)
+			return nil, nil
+		})
+	}
+
+	root := New(ctx, f("root"))
+	foo, bar, baz := New(ctx, f("foo")), New(ctx, f("bar")), New(ctx, f("baz"))
+	root.AddSubtasks(foo, baz)
+
+	if err := root.InsertSubtask(1, bar); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if _, err := Run([]*Task{root}); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	expected := []string{"root", "foo", "bar", "baz"}
+	for i := range expected {
+		if order[i] != expected[i] {
+			t.Fatalf("expected order %v, got %v", expected, order)
+		}
+	}
+
+	if err := root.InsertSubtask(4, New(ctx)); err == nil {
+		t.Error("expected an error")
+	}
+	if err := root.InsertSubtask(0, bar); !errors.Is(err, ErrDuplicateSubtask) {
+		t.Errorf("expected error %v, got %v", ErrDuplicateSubtask, err)
+	}
+	var cerr *CycleError
+	if err := bar.InsertSubtask(0, root); !errors.As(err, &cerr) {
+		t.Errorf("expected a *CycleError, got %v", err)
+	}
+}
@@ -3,11 +3,11 @@ package task
 import (
 	"context"
 	"errors"
-	"fmt"
 	"sync/atomic"
+	"time"
 )
 
-// counter is a variable of type atomic.Int64 that keeps track of the number of tasks created. It is used to assign a unique ID to each new task that is created. The counter is incremented
+// counter is a variable of type atomic.Int64 that keeps track of the number of tasks created. It is used by CounterID to assign a unique ID to each new task that is created.
 var (
 	counter atomic.Int64
 )
@@ -21,27 +21,79 @@ type TaskFunc func(ctx context.Context, values ...interface{}) (interface{}, err
 // CtxKey represents a key for retrieving values from Go context.
 type CtxKey string
 
+// String returns the key qualified with its type, so printed contexts show which values the package stored.
+func (k CtxKey) String() string {
+	return "task.CtxKey(" + string(k) + ")"
+}
+
 // Task represents a unit of work that can be executed and reverted.
 //
 // Members:
 // - ID: the unique identifier of the task
+// - Name: the name under which the output of the task is available in Results
 // - Context: the context in which the task runs
 // - Subtasks: the list of subtasks that are dependent on this task
+// - Dependencies: the list of tasks whose outputs this task consumes, see DependsOn
 // - Run: the function that performs the task
 // - Revert: the function that reverts the task
+// - Tags: the tags used to apply run-wide policies to the task
+// - Retry: the policy describing how often the task is retried when it fails
+// - Timeout: the maximum duration of a single attempt of the task, zero means no limit
+// - Deadline: the point in time the task has to be completed by, including all retries, zero means no deadline
+// - Middleware: the middleware wrapping the Run function of the task
+// - SerialKey: the key of the tasks this task never runs at the same time with, see WithSerialKey
+// - IdempotencyKey: the key identifying the side effects of the task, see WithIdempotencyKey
+// - Resources: the slots of named resources the task needs to run, see WithResources
+// - RevertRetry: the policy describing how often the Revert function is retried when it fails
+// - RevertTimeout: the maximum duration of a single attempt of the Revert function, zero means no limit
+// - Idempotent: whether running the task again has no further side effects, see WithIdempotent
+// - Condition: the function deciding whether the task runs, nil always runs it, see WithCondition
 type Task struct {
-	ID         string
-	Parameters []interface{}
-	Context    context.Context
-	Subtasks   []*Task
-	Run        TaskFunc
-	Revert     TaskFunc
+	ID             string
+	Name           string
+	Parameters     []interface{}
+	Context        context.Context
+	Subtasks       []*Task
+	Dependencies   []*Task
+	Run            TaskFunc
+	Revert         TaskFunc
+	Tags           []string
+	Retry          RetryPolicy
+	Timeout        time.Duration
+	Deadline       time.Time
+	Middleware     []Middleware
+	SerialKey      string
+	IdempotencyKey string
+	Resources      map[string]int
+	RevertRetry    RetryPolicy
+	RevertTimeout  time.Duration
+	Idempotent     bool
+	Condition      ConditionFunc
+
+	dependents []*Task
+	status     atomic.Int32
+	sealed     atomic.Bool
 }
 
 // TaskContext represents the context of a task and its parent task.
 type TaskContext struct {
 	Parent *Task
 	Task   *Task
+
+	rand   *taskRand
+	values taskValues
+}
+
+// String returns the ID of the task and of its parent, if it has one, so printed contexts show which task they belong to.
+func (tc *TaskContext) String() string {
+	s := "TaskContext("
+	if tc.Task != nil {
+		s += tc.Task.ID
+	}
+	if tc.Parent != nil {
+		s += ", parent " + tc.Parent.ID
+	}
+	return s + ")"
 }
 
 // MustDecodeCtx takes a context and attempts to decode it into a TaskContext. If decoding fails, it panics.
@@ -71,13 +123,11 @@ func DecodeCtx(ctx context.Context) (*TaskContext, error) {
 }
 
 // New creates a new Task with the given context and configuration functions.
-// It generates a unique ID for the task, initializes the task with the provided configuration functions,
-// creates a new value context with the task, increments the counter, and returns the created task.
+// It generates a unique ID for the task with the IDGenerator set by SetIDGenerator, initializes the task with the provided configuration functions,
+// creates a new value context with the task, and returns the created task.
 func New(ctx context.Context, cfgs ...TaskConfigFunc) *Task {
-	taskID := fmt.Sprintf("task_%d", counter.Load())
-
 	t := &Task{
-		ID: taskID,
+		ID: nextID(),
 	}
 
 	for _, cfg := range cfgs {
@@ -89,8 +139,6 @@ func New(ctx context.Context, cfgs ...TaskConfigFunc) *Task {
 	})
 	t.Context = valueContext
 
-	counter.Add(1)
-
 	return t
 }
 
@@ -120,47 +168,60 @@ func WithParameters(parameters ...interface{}) TaskConfigFunc {
 // Each subtask is given a new context derived from the parent task's context using context.WithValue.
 // The value associated with the key "ctx" in the parent context is set to a TaskContext struct that contains a reference to the parent task and the subtask.
 // The subtasks are then appended to the task's Subtasks slice.
-func (t *Task) AddSubtasks(st ...*Task) {
+// If the task or any of the subtasks is sealed, nothing is changed and an error wrapping ErrSealed is returned, see Seal.
+func (t *Task) AddSubtasks(st ...*Task) error {
+	if err := checkSealed(append([]*Task{t}, st...)...); err != nil {
+		return err
+	}
+
 	for _, subtask := range st {
-		subtask.Context = context.WithValue(t.Context, CtxKey("ctx"), &TaskContext{
-			Task:   subtask,
-			Parent: t,
-		})
+		t.adopt(subtask)
 	}
 	t.Subtasks = append(t.Subtasks, st...)
+	return nil
 }
 
-// Revert iterates over a list of tasks and calls their Revert functions in reverse order.
+// Revert iterates over a list of tasks and calls their Revert functions in order.
 // It takes a slice of tasks and optional values as arguments.
 // The Revert function of each task is called with the provided values.
-// If an error occurs during the Revert call, it currently does not handle the error.
 // The function also recursively adds the subtasks of each task to the task list.
-func Revert(tasks []*Task, values ...interface{}) {
+// A failing Revert function doesn't stop the remaining tasks from being reverted, all failures are returned together as a *RevertError.
+func Revert(tasks []*Task, values ...interface{}) error {
+	var failures []RevertFailure
+
 	for len(tasks) > 0 {
 		task := tasks[0]
 		tasks = tasks[1:]
 
-		if task.Revert != nil {
-			_, err := task.Revert(task.Context, values...)
-			if err != nil {
-				// TODO
-			}
+		if attempts, err := revertTask(task.Context, task, false, values...); err != nil {
+			failures = append(failures, RevertFailure{Task: task, Err: err, Attempts: attempts})
 		}
 
 		tasks = append(tasks, task.Subtasks...)
 	}
+
+	if len(failures) > 0 {
+		return &RevertError{Failures: failures}
+	}
+	return nil
 }
 
-// Run executes a list of tasks in parallel, returning the results and an error if any task fails.
+// Run executes a list of tasks and their subtasks sequentially, returning the results and an error if any task fails.
+// Use a Runner configured with WithConcurrency to execute independent tasks in parallel.
 //
 // The function takes a slice of pointers to Task structs and variadic arguments representing the initial input values.
 //
 // Each task in the list is executed by calling its Run method with the provided values.
 // If a task returns an error, the function will attempt to revert the changes made by the tasks that have already succeeded,
 // by calling their Revert methods in reverse order. The original input values are passed to the Revert methods.
-// If an error occurs during the revert process, it is currently not handled and needs to be implemented.
+// The subtasks of reverted tasks are visited as well, every task that succeeded is reverted once. If any Revert method fails,
+// Run returns a *RevertError that wraps the original task error together with every revert failure.
 //
-// The return value is a slice of the output values produced by each task. If all tasks succeed, the returned error is nil.
+// If the context of a task is done before or while it runs, Run returns a *CancelledError whose Reason tells
+// whether the task was cancelled by the caller, timed out, cancelled because a sibling failed or was preempted.
+//
+// The return value holds the output values produced by each task, in execution order, and the outputs of named tasks by name.
+// If all tasks succeed, the returned error is nil.
 //
 // Example usage:
 //
@@ -213,35 +274,29 @@ func Revert(tasks []*Task, values ...interface{}) {
 //		return nil, nil
 //	}))
 //
-// foo.AddSubtasks(quz, bar)
+//	if err := foo.AddSubtasks(quz, bar); err != nil {
+//		panic(err)
+//	}
 //
 //	if _, err := task.Run([]*task.Task{foo}); err != nil {
 //		panic(err)
 //	}
-func Run(tasks []*Task, values ...interface{}) ([]interface{}, error) {
-	result := make([]interface{}, 0, len(tasks))
-	successfulTasks := make([]*Task, 0, len(tasks))
-
-	for len(tasks) > 0 {
-		task := tasks[0]
-		tasks[0] = nil // Clear the pointer for garbage collection
-		tasks = tasks[1:]
-
-		val, err := task.Run(task.Context, values...)
-		if err != nil {
-			Revert(successfulTasks, values...)
-			return nil, err
-		}
-		values = append(values, val)
-		result = append(result, val)
-
-		// prepend task to successfulTasks with minimal reallocation
-		successfulTasks = append(successfulTasks[:1], successfulTasks...)
-		successfulTasks[0] = task
-
-		// append subtasks to tasks
-		tasks = append(tasks, task.Subtasks...)
-	}
+func Run(tasks []*Task, values ...interface{}) (*Results, error) {
+	return NewRunner().Run(tasks, values...)
+}
 
-	return result, nil
+// RunCtx executes a list of tasks like Run, but aborts the run when ctx is done. No further tasks are started,
+// the running task is cancelled and all tasks that succeeded so far are reverted.
+// The returned error is a *CancelledError wrapping ctx.Err().
+//
+// Example usage:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+//	defer cancel()
+//
+//	if _, err := task.RunCtx(ctx, []*task.Task{foo}); errors.Is(err, context.DeadlineExceeded) {
+//		log.Printf("run took too long and was reverted")
+//	}
+func RunCtx(ctx context.Context, tasks []*Task, values ...interface{}) (*Results, error) {
+	return NewRunner().RunCtx(ctx, tasks, values...)
 }
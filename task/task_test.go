@@ -49,12 +49,12 @@ func TestSimpleTaskChain(t *testing.T) {
 	if err != nil {
 		t.Fatal("didnt expect error")
 	}
-	if len(result) != 3 {
+	if result.Len() != 3 {
 		t.Error("expected 3 results")
 	}
 
 	sum := 0
-	for _, val := range result {
+	for _, val := range result.Values() {
 		num := val.(int)
 		sum += num
 	}
@@ -0,0 +1,120 @@
+package tasktest
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/codecreationlabs/async/task"
+)
+
+// Expected describes the parts of a run outcome that AssertReport checks. Zero values are not checked, so tests only
+// state what they care about.
+//
+// Members:
+// - Len: the number of tasks that succeeded, zero skips the check
+// - Order: names of tasks that must have completed in this relative order, other tasks may complete in between
+// - Outputs: outputs of named tasks, compared with reflect.DeepEqual
+// - Statuses: statuses of tasks after the run, see task.Task.Status
+type Expected struct {
+	Len      int
+	Order    []string
+	Outputs  map[string]interface{}
+	Statuses map[*task.Task]task.Status
+}
+
+// AssertReport compares the results of a run against the expected outcome and reports every mismatch in a single
+// readable failure, instead of stopping at the first one.
+//
+// Example usage:
+//
+//	results, err := runner.Run(tasks)
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	tasktest.AssertReport(t, results, tasktest.Expected{
+//		Order:   []string{"create-user", "send-welcome-mail"},
+//		Outputs: map[string]interface{}{"create-user": User{ID: "foobar"}},
+//		Statuses: map[*task.Task]task.Status{sendSMS: task.Skipped},
+//	})
+func AssertReport(t testing.TB, results *task.Results, expected Expected) {
+	t.Helper()
+
+	if diff := Diff(results, expected); diff != "" {
+		t.Errorf("run does not match expectation:\n%s", diff)
+	}
+}
+
+// Diff returns a description of every difference between the results of a run and the expected outcome,
+// or an empty string if they match.
+func Diff(results *task.Results, expected Expected) string {
+	var diffs []string
+
+	if expected.Len > 0 && results.Len() != expected.Len {
+		diffs = append(diffs, fmt.Sprintf("len: want %d, got %d", expected.Len, results.Len()))
+	}
+
+	if len(expected.Order) > 0 {
+		names := results.Names()
+		if !isSubsequence(expected.Order, names) {
+			diffs = append(diffs, fmt.Sprintf("order: want %v as subsequence, got %v", expected.Order, names))
+		}
+	}
+
+	for _, name := range sortedKeys(expected.Outputs) {
+		want := expected.Outputs[name]
+		got, ok := results.Get(name)
+		switch {
+		case !ok:
+			diffs = append(diffs, fmt.Sprintf("output %q: want %#v, got no result", name, want))
+		case !reflect.DeepEqual(got, want):
+			diffs = append(diffs, fmt.Sprintf("output %q: want %#v, got %#v", name, want, got))
+		}
+	}
+
+	tasks := make([]*task.Task, 0, len(expected.Statuses))
+	for t := range expected.Statuses {
+		tasks = append(tasks, t)
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		return taskName(tasks[i]) < taskName(tasks[j])
+	})
+	for _, t := range tasks {
+		if want, got := expected.Statuses[t], t.Status(); got != want {
+			diffs = append(diffs, fmt.Sprintf("status %q: want %s, got %s", taskName(t), want, got))
+		}
+	}
+
+	return strings.Join(diffs, "\n")
+}
+
+// taskName returns the name of t, or its ID if it has no name.
+func taskName(t *task.Task) string {
+	if t.Name != "" {
+		return t.Name
+	}
+	return t.ID
+}
+
+// isSubsequence reports whether all elements of sub appear in s in the same relative order.
+func isSubsequence(sub, s []string) bool {
+	i := 0
+	for _, v := range s {
+		if i < len(sub) && sub[i] == v {
+			i++
+		}
+	}
+	return i == len(sub)
+}
+
+// sortedKeys returns the keys of m in lexical order, so diffs are stable.
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
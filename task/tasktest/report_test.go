@@ -0,0 +1,79 @@
+package tasktest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/codecreationlabs/async/task"
+)
+
+func runExample(t *testing.T) *task.Results {
+	ctx := context.Background()
+	value := func(v interface{}) task.TaskConfigFunc {
+		return task.WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+			return v, nil
+		})
+	}
+
+	create := task.New(ctx, value(map[string]string{"id": "foobar"}), task.WithName("create-user"))
+	create.AddSubtasks(
+		task.New(ctx, value(1), task.WithName("audit")),
+		task.New(ctx, value("sent"), task.WithName("send-mail")),
+	)
+
+	results, err := task.Run([]*task.Task{create})
+	if err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	return results
+}
+
+func TestAssertReport(t *testing.T) {
+	AssertReport(t, runExample(t), Expected{
+		Len:   3,
+		Order: []string{"create-user", "send-mail"},
+		Outputs: map[string]interface{}{
+			"create-user": map[string]string{"id": "foobar"},
+			"send-mail":   "sent",
+		},
+	})
+}
+
+func TestDiff(t *testing.T) {
+	diff := Diff(runExample(t), Expected{
+		Len:   2,
+		Order: []string{"send-mail", "create-user"},
+		Outputs: map[string]interface{}{
+			"audit":   2,
+			"missing": nil,
+		},
+	})
+
+	for _, want := range []string{"len: want 2, got 3", "order:", `output "audit": want 2, got 1`, `output "missing"`} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("expected diff to contain %q, got\n%s", want, diff)
+		}
+	}
+}
+
+func TestDiffStatuses(t *testing.T) {
+	ctx := context.Background()
+	ok := task.New(ctx, task.WithName("ok"), task.WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, nil
+	}))
+
+	results, err := task.Run([]*task.Task{ok})
+	if err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+
+	if diff := Diff(results, Expected{Statuses: map[*task.Task]task.Status{ok: task.Succeeded}}); diff != "" {
+		t.Errorf("expected no diff, got\n%s", diff)
+	}
+	diff := Diff(results, Expected{Statuses: map[*task.Task]task.Status{ok: task.Failed}})
+	if want := fmt.Sprintf(`status "ok": want %s, got %s`, task.Failed, task.Succeeded); diff != want {
+		t.Errorf("expected diff %q, got %q", want, diff)
+	}
+}
@@ -0,0 +1,113 @@
+// Package tasktest provides utilities for testing task graphs.
+package tasktest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/codecreationlabs/async/task"
+)
+
+// UpdateEnv is the environment variable that makes SnapshotGraph rewrite golden files instead of comparing against them.
+//
+// Example usage:
+//
+//	TASKTEST_UPDATE=1 go test ./...
+const UpdateEnv = "TASKTEST_UPDATE"
+
+// SnapshotGraph renders the graph reachable from roots with FormatGraph and compares it against the golden file
+// testdata/<test name>.golden, failing the test with both representations if they differ. Missing golden files are
+// created when the environment variable TASKTEST_UPDATE is set, which also overwrites existing ones.
+// This makes refactors that unintentionally change the shape of a workflow fail in CI.
+func SnapshotGraph(t testing.TB, roots ...*task.Task) {
+	t.Helper()
+
+	got, err := FormatGraph(roots...)
+	if err != nil {
+		t.Fatalf("format graph: %v", err)
+	}
+
+	path := filepath.Join("testdata", strings.ReplaceAll(t.Name(), "/", "_")+".golden")
+
+	if os.Getenv(UpdateEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("create testdata: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("write golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden file, run with %s=1 to create it: %v", UpdateEnv, err)
+	}
+
+	if got != string(want) {
+		t.Errorf("graph does not match %s, run with %s=1 to update it\n--- want\n%s\n--- got\n%s", path, UpdateEnv, want, got)
+	}
+}
+
+// FormatGraph returns a canonical textual representation of the graph reachable from roots. Tasks are listed in topological order
+// and referred to by name, or by their position in that order if they have no name, so the output doesn't depend on task IDs.
+// Every task lists its subtasks, dependencies, tags and policies.
+func FormatGraph(roots ...*task.Task) (string, error) {
+	tasks, err := task.Sort(roots)
+	if err != nil {
+		return "", err
+	}
+
+	labels := make(map[*task.Task]string, len(tasks))
+	for i, t := range tasks {
+		labels[t] = label(t, i)
+	}
+	list := func(tasks []*task.Task) string {
+		names := make([]string, 0, len(tasks))
+		for _, t := range tasks {
+			names = append(names, labels[t])
+		}
+		return strings.Join(names, ", ")
+	}
+
+	var sb strings.Builder
+	for _, t := range tasks {
+		fmt.Fprintf(&sb, "task %s\n", labels[t])
+		if len(t.Subtasks) > 0 {
+			fmt.Fprintf(&sb, "  subtasks: %s\n", list(t.Subtasks))
+		}
+		if len(t.Dependencies) > 0 {
+			fmt.Fprintf(&sb, "  depends on: %s\n", list(t.Dependencies))
+		}
+		if len(t.Tags) > 0 {
+			fmt.Fprintf(&sb, "  tags: %s\n", strings.Join(t.Tags, ", "))
+		}
+		if t.Revert != nil {
+			sb.WriteString("  revert: yes\n")
+		}
+		if t.Retry.Attempts > 1 {
+			fmt.Fprintf(&sb, "  retry: %d attempts\n", t.Retry.Attempts)
+		}
+		if t.Timeout > 0 {
+			fmt.Fprintf(&sb, "  timeout: %s\n", t.Timeout)
+		}
+		if !t.Deadline.IsZero() {
+			sb.WriteString("  deadline: yes\n")
+		}
+		if t.SerialKey != "" {
+			fmt.Fprintf(&sb, "  serial key: %s\n", t.SerialKey)
+		}
+	}
+	return sb.String(), nil
+}
+
+// label returns the name of a task or its position in the graph if it has none.
+func label(t *task.Task, i int) string {
+	if t.Name != "" {
+		return t.Name
+	}
+	return fmt.Sprintf("#%d", i)
+}
@@ -0,0 +1,46 @@
+package tasktest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/codecreationlabs/async/task"
+)
+
+func noop(ctx context.Context, values ...interface{}) (interface{}, error) {
+	return nil, nil
+}
+
+func TestSnapshotGraph(t *testing.T) {
+	ctx := context.Background()
+
+	create := task.New(ctx, task.WithFunc(noop), task.WithRevertFunc(noop), task.WithName("create-user"), task.WithRetry(3, nil))
+	account := task.New(ctx, task.WithFunc(noop), task.WithName("create-account"), task.WithTags("billing"))
+	welcome := task.New(ctx, task.WithFunc(noop), task.WithTimeout(time.Second))
+	welcome.DependsOn(create, account)
+	create.AddSubtasks(task.New(ctx, task.WithFunc(noop), task.WithName("audit")))
+
+	SnapshotGraph(t, create)
+}
+
+func TestFormatGraphIsStable(t *testing.T) {
+	build := func() *task.Task {
+		ctx := context.Background()
+		root := task.New(ctx, task.WithFunc(noop))
+		root.AddSubtasks(task.New(ctx, task.WithFunc(noop)), task.New(ctx, task.WithFunc(noop)))
+		return root
+	}
+
+	a, err := FormatGraph(build())
+	if err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	b, err := FormatGraph(build())
+	if err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if a != b {
+		t.Errorf("expected identical graphs to format identically, got\n%s\nand\n%s", a, b)
+	}
+}
@@ -0,0 +1,52 @@
+package task
+
+import (
+	"context"
+	"time"
+)
+
+// WithTimeout returns a TaskConfigFunc that limits every attempt of the task to the duration d.
+// When an attempt does not return in time, its context is cancelled and the attempt fails with a *CancelledError
+// whose Reason is TimedOut. The attempt is retried if the task has a retry policy, otherwise the run is reverted.
+func WithTimeout(d time.Duration) TaskConfigFunc {
+	return func(t *Task) {
+		t.Timeout = d
+	}
+}
+
+// WithDeadline returns a TaskConfigFunc that requires the task, including all of its retries, to complete before deadline.
+// When the deadline passes, the context of the task is cancelled and the task fails with a *CancelledError
+// whose Reason is TimedOut, which reverts the run.
+func WithDeadline(deadline time.Time) TaskConfigFunc {
+	return func(t *Task) {
+		t.Deadline = deadline
+	}
+}
+
+// call runs f, the Run function of a task wrapped in its middleware. If the task has a timeout or deadline, the function runs on its own goroutine,
+// so the runner can give up on it once ctx is done even if the function ignores its context. In that case
+// the function keeps running in the background until it returns and its result is dropped. See invoke for dump.
+func call(ctx context.Context, task *Task, f TaskFunc, values []interface{}, dump bool) (interface{}, error) {
+	if task.Timeout <= 0 && task.Deadline.IsZero() {
+		return invoke(ctx, task, f, values, dump)
+	}
+
+	type outcome struct {
+		val interface{}
+		err error
+	}
+
+	// buffered, so an abandoned function can still deliver its result and exit
+	done := make(chan outcome, 1)
+	go func() {
+		val, err := invoke(ctx, task, f, values, dump)
+		done <- outcome{val: val, err: err}
+	}()
+
+	select {
+	case o := <-done:
+		return o.val, o.err
+	case <-ctx.Done():
+		return nil, newCancelledError(task, ctx)
+	}
+}
@@ -0,0 +1,73 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithTimeout(t *testing.T) {
+	reverted := false
+	root := New(context.Background(), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, nil
+	}), WithRevertFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		reverted = true
+		return nil, nil
+	}))
+
+	release := make(chan struct{})
+	defer close(release)
+
+	// ignores its context on purpose
+	root.AddSubtasks(New(context.Background(), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		<-release
+		return nil, nil
+	}), WithTimeout(10*time.Millisecond)))
+
+	_, err := Run([]*Task{root})
+
+	var cerr *CancelledError
+	if !errors.As(err, &cerr) || cerr.Reason != TimedOut {
+		t.Fatalf("expected the task to time out, got %v", err)
+	}
+	if !reverted {
+		t.Error("expected successful tasks to be reverted")
+	}
+}
+
+func TestWithTimeoutRetry(t *testing.T) {
+	var calls atomic.Int64
+	task := New(context.Background(), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		if calls.Add(1) == 1 {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return "done", nil
+	}), WithTimeout(10*time.Millisecond), WithRetry(2, nil))
+
+	result, err := Run([]*Task{task})
+	if err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if result.Values()[0] != "done" {
+		t.Errorf("expected second attempt to succeed, got %v", result.Values()[0])
+	}
+}
+
+func TestWithDeadline(t *testing.T) {
+	var calls atomic.Int64
+	task := New(context.Background(), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		calls.Add(1)
+		return nil, errors.New("down")
+	}), WithDeadline(time.Now().Add(20*time.Millisecond)), WithRetry(100, ConstantBackoff(5*time.Millisecond)))
+
+	_, err := Run([]*Task{task})
+	if !errors.Is(err, ErrTimedOut) {
+		t.Fatalf("expected the task to time out, got %v", err)
+	}
+	if calls.Load() >= 100 {
+		t.Error("expected the deadline to stop the retries")
+	}
+}
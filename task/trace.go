@@ -0,0 +1,92 @@
+package task
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WithTracer returns a RunnerConfigFunc that creates an OpenTelemetry span for every task executed by the Runner and for every Revert function it calls.
+// The span of a subtask is a child of the span of its parent task, the span of a root task is a child of the span in the context passed to RunCtx, if any.
+// The span is available in the context of the task, so spans started by the TaskFunc, e.g. by an instrumented HTTP client, become children of the task span.
+// Errors are recorded on the span and retries are added as span events.
+//
+// Example usage:
+//
+//	runner := task.NewRunner(task.WithTracer(otel.Tracer("github.com/acme/billing")))
+//
+//	ctx, span := tracer.Start(ctx, "checkout")
+//	defer span.End()
+//
+//	results, err := runner.RunCtx(ctx, tasks)
+func WithTracer(t trace.Tracer) RunnerConfigFunc {
+	return func(o *RunOptions) {
+		o.Tracer = t
+	}
+}
+
+// spanName returns the name of the span of a task, the name of the task if it has one and its ID otherwise.
+func spanName(task *Task) string {
+	if task.Name != "" {
+		return task.Name
+	}
+	return task.ID
+}
+
+// startSpan starts the span of a task as a child of the span of its parent task and returns ctx carrying the new span.
+func (s *run) startSpan(ctx context.Context, task *Task) (context.Context, trace.Span) {
+	parent := s.ctx
+	if tc, err := DecodeCtx(task.Context); err == nil && tc.Parent != nil {
+		if span, ok := s.span(tc.Parent); ok {
+			parent = trace.ContextWithSpan(s.ctx, span)
+		}
+	}
+
+	_, span := s.runner.Options.Tracer.Start(parent, spanName(task), trace.WithAttributes(
+		attribute.String("task.id", task.ID),
+		attribute.String("task.name", task.Name),
+	))
+
+	s.mu.Lock()
+	if s.spans == nil {
+		s.spans = make(map[*Task]trace.Span)
+	}
+	s.spans[task] = span
+	s.mu.Unlock()
+
+	return trace.ContextWithSpan(ctx, span), span
+}
+
+// startRevertSpan starts the span of the Revert function of a task as a child of the span of the task and returns ctx carrying the new span.
+func (s *run) startRevertSpan(ctx context.Context, task *Task) (context.Context, trace.Span) {
+	parent := s.ctx
+	if span, ok := s.span(task); ok {
+		parent = trace.ContextWithSpan(s.ctx, span)
+	}
+
+	_, span := s.runner.Options.Tracer.Start(parent, "revert "+spanName(task), trace.WithAttributes(
+		attribute.String("task.id", task.ID),
+		attribute.String("task.name", task.Name),
+	))
+
+	return trace.ContextWithSpan(ctx, span), span
+}
+
+// span returns the span started for a task in this run.
+func (s *run) span(task *Task) (trace.Span, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	span, ok := s.spans[task]
+	return span, ok
+}
+
+// endSpan records err on span, if it is not nil, and ends the span.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,89 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestWithTracer(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tracer := provider.Tracer("task")
+
+	ctx := context.Background()
+	create := New(ctx, WithName("create"), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, nil
+	}), WithRevertFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, nil
+	}))
+	calls := 0
+	create.AddSubtasks(New(ctx, WithName("process"), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		calls++
+		return nil, errors.New("foobar")
+	}), WithRetry(2, ConstantBackoff(time.Millisecond))))
+
+	runCtx, root := tracer.Start(ctx, "checkout")
+	_, err := NewRunner(WithTracer(tracer)).RunCtx(runCtx, []*Task{create})
+	root.End()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	if len(spans) != 4 {
+		t.Fatalf("expected %d spans, got %d", 4, len(spans))
+	}
+
+	if spans["create"].Parent().SpanID() != spans["checkout"].SpanContext().SpanID() {
+		t.Error("expected span of the root task to be a child of the span of the run")
+	}
+	if spans["process"].Parent().SpanID() != spans["create"].SpanContext().SpanID() {
+		t.Error("expected span of the subtask to be a child of the span of its parent")
+	}
+	if spans["revert create"].Parent().SpanID() != spans["create"].SpanContext().SpanID() {
+		t.Error("expected span of the revert to be a child of the span of the task")
+	}
+
+	if spans["process"].Status().Code != codes.Error {
+		t.Error("expected failed task to have an error status")
+	}
+	if spans["create"].Status().Code == codes.Error {
+		t.Error("didnt expect successful task to have an error status")
+	}
+	if events := spans["process"].Events(); len(events) != 2 || events[0].Name != "retry" {
+		t.Errorf("expected a retry and an error event, got %v", events)
+	}
+}
+
+func TestTracerPropagatesSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tracer := provider.Tracer("task")
+
+	task := New(context.Background(), WithName("call"), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		_, span := tracer.Start(ctx, "http")
+		span.End()
+		return nil, nil
+	}))
+
+	if _, err := NewRunner(WithTracer(tracer), WithConcurrency(2)).Run([]*Task{task}); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected %d spans, got %d", 2, len(spans))
+	}
+	if spans[0].Parent().SpanID() != spans[1].SpanContext().SpanID() {
+		t.Error("expected span started by the task to be a child of the task span")
+	}
+}
@@ -0,0 +1,80 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// TypedTaskFunc represents a function that can be executed as a task with a statically typed input and output.
+type TypedTaskFunc[In, Out any] func(ctx context.Context, in In) (Out, error)
+
+// TypeError is returned by typed tasks when none of the values passed to the task has the expected input type.
+type TypeError struct {
+	TaskID   string
+	Expected reflect.Type
+}
+
+// Error implements the error interface.
+func (e *TypeError) Error() string {
+	return fmt.Sprintf("task %s: no input value of type %s", e.TaskID, e.Expected)
+}
+
+// NewTyped creates a new Task running the TypedTaskFunc f with the given context and configuration functions.
+// The input of f is the most recent value passed to the task that is assignable to In, so a typed task consumes the output
+// of the closest preceding task of the matching type. If no such value exists, the task parameters are searched in order.
+// If no value matches at all, the task fails with a *TypeError instead of panicking on a type assertion.
+//
+// Example usage:
+//
+//	create := task.NewTyped(ctx, func(ctx context.Context, p CreateUserParams) (User, error) {
+//		return User{ID: "foobar", Name: p.Name}, nil
+//	}, task.WithParameters(params))
+//
+//	process := task.NewTyped(ctx, func(ctx context.Context, u User) (User, error) {
+//		u.Processed = true
+//		return u, nil
+//	})
+//
+//	if err := create.AddSubtasks(process); err != nil {
+//		return err
+//	}
+func NewTyped[In, Out any](ctx context.Context, f TypedTaskFunc[In, Out], cfgs ...TaskConfigFunc) *Task {
+	return New(ctx, append([]TaskConfigFunc{WithTypedFunc(f)}, cfgs...)...)
+}
+
+// WithTypedFunc returns a TaskConfigFunc that sets the Run function of a Task to the TypedTaskFunc f. See NewTyped for how the input is selected.
+func WithTypedFunc[In, Out any](f TypedTaskFunc[In, Out]) TaskConfigFunc {
+	return WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		in, err := typedInput[In](ctx, values)
+		if err != nil {
+			return nil, err
+		}
+		return f(ctx, in)
+	})
+}
+
+// typedInput selects the input of a typed task from the values passed to it and its parameters.
+func typedInput[In any](ctx context.Context, values []interface{}) (In, error) {
+	for i := len(values) - 1; i >= 0; i-- {
+		if in, ok := values[i].(In); ok {
+			return in, nil
+		}
+	}
+
+	var zero In
+	tc, err := DecodeCtx(ctx)
+	if err != nil || tc.Task == nil {
+		return zero, &TypeError{Expected: reflect.TypeOf((*In)(nil)).Elem()}
+	}
+
+	for _, p := range tc.Task.Parameters {
+		if in, ok := p.(In); ok {
+			return in, nil
+		}
+	}
+	return zero, &TypeError{
+		TaskID:   tc.Task.ID,
+		Expected: reflect.TypeOf((*In)(nil)).Elem(),
+	}
+}
@@ -0,0 +1,65 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type typedParams struct {
+	Name string
+}
+
+type typedUser struct {
+	Name      string
+	Processed bool
+}
+
+func TestTypedTaskChain(t *testing.T) {
+	ctx := context.Background()
+
+	create := NewTyped(ctx, func(ctx context.Context, p typedParams) (typedUser, error) {
+		return typedUser{Name: p.Name}, nil
+	}, WithParameters(typedParams{Name: "foobar"}))
+
+	count := NewTyped(ctx, func(ctx context.Context, u typedUser) (int, error) {
+		return len(u.Name), nil
+	})
+
+	process := NewTyped(ctx, func(ctx context.Context, u typedUser) (typedUser, error) {
+		u.Processed = true
+		return u, nil
+	})
+
+	count.AddSubtasks(process)
+	create.AddSubtasks(count)
+
+	result, err := Run([]*Task{create})
+	if err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+
+	if result.Values()[1].(int) != 6 {
+		t.Errorf("expected length of %d, got %v", 6, result.Values()[1])
+	}
+	user := result.Values()[2].(typedUser)
+	if !user.Processed || user.Name != "foobar" {
+		t.Errorf("unexpected user %+v", user)
+	}
+}
+
+func TestTypedTaskMismatch(t *testing.T) {
+	task := NewTyped(context.Background(), func(ctx context.Context, u typedUser) (typedUser, error) {
+		return u, nil
+	})
+
+	_, err := Run([]*Task{task}, "not a user")
+
+	var terr *TypeError
+	if !errors.As(err, &terr) {
+		t.Fatalf("expected a TypeError, got %v", err)
+	}
+	if terr.TaskID != task.ID {
+		t.Errorf("expected task id %s, got %s", task.ID, terr.TaskID)
+	}
+}
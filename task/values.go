@@ -0,0 +1,44 @@
+package task
+
+import "sync"
+
+// taskValues holds the values stored on a TaskContext with Set.
+type taskValues struct {
+	mu sync.Mutex
+	m  map[interface{}]interface{}
+}
+
+// Set stores value under key on the TaskContext. Every execution of a task in a run has a TaskContext of its own, so values are isolated
+// per task and per run: tasks running concurrently, and the same task running in several runs, never see each other's values,
+// while the retries of a task share them. Like context keys, key should be of an unexported type of the package using it to avoid collisions.
+// The Revert function of the task doesn't see the values. Set and Get are safe for concurrent use.
+//
+// Example usage:
+//
+//	type attemptsKey struct{}
+//
+//	func fetch(ctx context.Context, values ...interface{}) (interface{}, error) {
+//		tc := task.MustDecodeCtx(ctx)
+//		n, _ := tc.Get(attemptsKey{})
+//		attempts, _ := n.(int)
+//		tc.Set(attemptsKey{}, attempts+1)
+//		// ...
+//	}
+func (tc *TaskContext) Set(key, value interface{}) {
+	tc.values.mu.Lock()
+	defer tc.values.mu.Unlock()
+
+	if tc.values.m == nil {
+		tc.values.m = make(map[interface{}]interface{})
+	}
+	tc.values.m[key] = value
+}
+
+// Get returns the value stored under key with Set and whether it was found.
+func (tc *TaskContext) Get(key interface{}) (interface{}, bool) {
+	tc.values.mu.Lock()
+	defer tc.values.mu.Unlock()
+
+	value, ok := tc.values.m[key]
+	return value, ok
+}
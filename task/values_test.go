@@ -0,0 +1,76 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+type attemptsKey struct{}
+
+func TestTaskContextValues(t *testing.T) {
+	var seen []int
+	task := New(context.Background(), WithRetry(3, nil), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		tc := MustDecodeCtx(ctx)
+		n, _ := tc.Get(attemptsKey{})
+		attempts, _ := n.(int)
+		attempts++
+		tc.Set(attemptsKey{}, attempts)
+		seen = append(seen, attempts)
+
+		if attempts < 2 {
+			return nil, errors.New("foobar")
+		}
+		return attempts, nil
+	}))
+
+	for i := 0; i < 2; i++ {
+		if _, err := Run([]*Task{task}); err != nil {
+			t.Fatalf("didnt expect error, got %v", err)
+		}
+	}
+
+	expected := []int{1, 2, 1, 2}
+	if len(seen) != len(expected) {
+		t.Fatalf("expected %d attempts, got %d", len(expected), len(seen))
+	}
+	for i := range expected {
+		if seen[i] != expected[i] {
+			t.Errorf("expected attempt %d to see %d, got %d", i, expected[i], seen[i])
+		}
+	}
+}
+
+func TestTaskContextValuesIsolated(t *testing.T) {
+	ctx := context.Background()
+	var both sync.WaitGroup
+	both.Add(2)
+	f := func(name string) TaskFunc {
+		return func(ctx context.Context, values ...interface{}) (interface{}, error) {
+			tc := MustDecodeCtx(ctx)
+			if _, ok := tc.Get(attemptsKey{}); ok {
+				return nil, errors.New("expected no value")
+			}
+			tc.Set(attemptsKey{}, name)
+			// both tasks set their value before either reads it
+			both.Done()
+			both.Wait()
+
+			v, _ := tc.Get(attemptsKey{})
+			return v, nil
+		}
+	}
+	foo := New(ctx, WithName("foo"), WithFunc(f("foo")))
+	bar := New(ctx, WithName("bar"), WithFunc(f("bar")))
+
+	results, err := NewRunner(WithConcurrency(2)).Run([]*Task{foo, bar})
+	if err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	for _, name := range []string{"foo", "bar"} {
+		if v, ok := results.Get(name); !ok || v != name {
+			t.Errorf("expected task %s to see its own value, got %v", name, v)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrUnknownWorkflow is wrapped into the error of a task created by Call whose workflow was not registered with RegisterWorkflow.
+var ErrUnknownWorkflow = errors.New("unknown workflow")
+
+// WorkflowFunc builds the tasks of a registered workflow from the parameters of a task created by Call.
+// It is called every time the calling task runs, so it has to return new tasks on every call.
+type WorkflowFunc func(ctx context.Context, params ...interface{}) ([]*Task, error)
+
+// workflowRegistry maps registered names to workflows.
+type workflowRegistry struct {
+	mu    sync.RWMutex
+	funcs map[string]WorkflowFunc
+}
+
+var workflows = &workflowRegistry{
+	funcs: make(map[string]WorkflowFunc),
+}
+
+// RegisterWorkflow registers build under name, so graphs can reuse the workflow with Call instead of copying its tasks.
+// Like RegisterParameter, RegisterWorkflow panics if name is already registered or build is nil.
+//
+// Example usage:
+//
+//	func init() {
+//		task.RegisterWorkflow("onboard-user", func(ctx context.Context, params ...interface{}) ([]*task.Task, error) {
+//			user := params[0].(User)
+//			return []*task.Task{sendWelcomeMail(ctx, user), createWorkspace(ctx, user)}, nil
+//		})
+//	}
+func RegisterWorkflow(name string, build WorkflowFunc) {
+	if build == nil {
+		panic(fmt.Sprintf("task: cannot register nil workflow %q", name))
+	}
+
+	workflows.mu.Lock()
+	defer workflows.mu.Unlock()
+
+	if _, ok := workflows.funcs[name]; ok {
+		panic(fmt.Sprintf("task: workflow %q registered twice", name))
+	}
+	workflows.funcs[name] = build
+}
+
+// unregisterWorkflow removes the workflow registered under name, so tests can register their workflows again.
+func unregisterWorkflow(name string) {
+	workflows.mu.Lock()
+	defer workflows.mu.Unlock()
+	delete(workflows.funcs, name)
+}
+
+// lookupWorkflow returns the workflow registered under name.
+func lookupWorkflow(name string) (WorkflowFunc, error) {
+	workflows.mu.RLock()
+	defer workflows.mu.RUnlock()
+
+	build, ok := workflows.funcs[name]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownWorkflow, name)
+	}
+	return build, nil
+}
+
+// Call returns a task that expands the workflow registered under name with RegisterWorkflow when it runs. The Parameters of the task,
+// see WithParameters, are passed to the WorkflowFunc, and the values the task receives are passed to the roots of the workflow.
+// The workflow runs as a nested run of the same Runner and the output of the task is its *Results. Before the nested run starts,
+// the task gives up its serial key, resource and adaptive limit slots, so tasks of the workflow needing the same slots don't deadlock with it.
+//
+// The workflow has its own revert scope: if one of its tasks fails, its succeeded tasks are reverted before the calling task fails.
+// If the workflow succeeded and the enclosing run fails later, the Revert function of the calling task reverts the succeeded tasks
+// of the workflow in reverse topological order. Setting another Revert function replaces this behavior.
+//
+// Example usage:
+//
+//	onboard := task.Call(ctx, "onboard-user", task.WithParameters(user), task.WithName("onboard"))
+//	if err := onboard.DependsOn(createUser); err != nil {
+//		return err
+//	}
+func Call(ctx context.Context, name string, cfgs ...TaskConfigFunc) *Task {
+	c := &workflowCall{name: name}
+	t := New(ctx, append([]TaskConfigFunc{WithFunc(c.run), WithRevertFunc(c.revert)}, cfgs...)...)
+	c.task = t
+	return t
+}
+
+// workflowCall is the state of a task created by Call. It keeps the tasks of the latest successful expansion, so they can be reverted.
+type workflowCall struct {
+	name string
+	task *Task
+
+	mu     sync.Mutex
+	runner *Runner
+	tasks  []*Task
+}
+
+// run builds the tasks of the workflow and runs them with the Runner executing the calling task.
+func (c *workflowCall) run(ctx context.Context, values ...interface{}) (interface{}, error) {
+	build, err := lookupWorkflow(c.name)
+	if err != nil {
+		return nil, err
+	}
+
+	runner, _ := ctx.Value(CtxKey("runner")).(*Runner)
+	if runner == nil {
+		runner = NewRunner()
+	}
+
+	// the tasks outlive this execution, so their reverts must not be cancelled when it returns
+	tasks, err := build(context.WithoutCancel(ctx), c.task.Parameters...)
+	if err != nil {
+		return nil, fmt.Errorf("workflow %q: %w", c.name, err)
+	}
+
+	// the tasks of the workflow may need the serial key, resources or limiters of the calling task
+	if held, ok := ctx.Value(CtxKey("slots")).(*heldSlots); ok {
+		held.handOver()
+	}
+
+	results, err := runner.RunCtx(ctx, tasks, values...)
+	if err != nil {
+		return nil, fmt.Errorf("workflow %q: %w", c.name, err)
+	}
+
+	c.mu.Lock()
+	c.runner, c.tasks = runner, tasks
+	c.mu.Unlock()
+	return results, nil
+}
+
+// revert reverts the succeeded tasks of the latest successful expansion in reverse topological order.
+func (c *workflowCall) revert(ctx context.Context, _ ...interface{}) (interface{}, error) {
+	c.mu.Lock()
+	runner, tasks := c.runner, c.tasks
+	c.runner, c.tasks = nil, nil
+	c.mu.Unlock()
+	if tasks == nil {
+		return nil, nil
+	}
+
+	sorted, err := Sort(tasks)
+	if err != nil {
+		return nil, err
+	}
+	succeeded := make([]*Task, 0, len(sorted))
+	for i := len(sorted) - 1; i >= 0; i-- {
+		if sorted[i].Status() == Succeeded {
+			succeeded = append(succeeded, sorted[i])
+		}
+	}
+
+	s := runner.newRun(ctx, "", nil)
+	defer s.cancel(nil)
+	return nil, s.revert(nil, succeeded)
+}
+
+// errHandedOver is the cause reported to the limiters of a task that handed its slots over to a nested run.
+var errHandedOver = errors.New("slots handed over to a nested run")
+
+// heldSlots tracks the serial key, resource and limiter slots held by an executing task, so a task starting a nested run
+// on the same Runner, see Call, can hand them over instead of deadlocking with tasks of the nested run that need the same slots.
+type heldSlots struct {
+	mu    sync.Mutex
+	slots []*heldSlot
+}
+
+// heldSlot is a slot registered with heldSlots.hold.
+type heldSlot struct {
+	release  func(handedOver bool)
+	released bool
+}
+
+// hold registers release, which frees a slot and is called once: with handedOver set by handOver, otherwise by the returned function.
+func (h *heldSlots) hold(release func(handedOver bool)) func() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	slot := &heldSlot{release: release}
+	h.slots = append(h.slots, slot)
+	return func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if !slot.released {
+			slot.released = true
+			slot.release(false)
+		}
+	}
+}
+
+// handOver frees all slots that are still held, in reverse order of acquisition.
+func (h *heldSlots) handOver() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for i := len(h.slots) - 1; i >= 0; i-- {
+		if slot := h.slots[i]; !slot.released {
+			slot.released = true
+			slot.release(true)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCall(t *testing.T) {
+	registerWorkflow(t, "test-greet", func(ctx context.Context, params ...interface{}) ([]*Task, error) {
+		greet := New(ctx, WithName("greet"), WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+			return params[0].(string) + " " + values[0].(string), nil
+		}))
+		return []*Task{greet}, nil
+	})
+
+	ctx := context.Background()
+	input := New(ctx, WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return "world", nil
+	}))
+	call := Call(ctx, "test-greet", WithParameters("hello"), WithName("call"))
+	if err := call.DependsOn(input); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+
+	results, err := Run([]*Task{input})
+	if err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	nested, err := GetAs[*Results](results, "call")
+	if err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	greeting, err := GetAs[string](nested, "greet")
+	if err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+	if greeting != "hello world" {
+		t.Errorf("expected %q, got %q", "hello world", greeting)
+	}
+}
+
+func TestCallUnknownWorkflow(t *testing.T) {
+	_, err := Run([]*Task{Call(context.Background(), "test-missing")})
+	if !errors.Is(err, ErrUnknownWorkflow) {
+		t.Errorf("expected ErrUnknownWorkflow, got %v", err)
+	}
+}
+
+func TestCallRevert(t *testing.T) {
+	var mu sync.Mutex
+	var reverted []string
+	revert := func(name string) TaskFunc {
+		return func(ctx context.Context, values ...interface{}) (interface{}, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			reverted = append(reverted, name)
+			return nil, nil
+		}
+	}
+
+	registerWorkflow(t, "test-revert", func(ctx context.Context, params ...interface{}) ([]*Task, error) {
+		first := New(ctx, WithFunc(noop), WithRevertFunc(revert("first")))
+		second := New(ctx, WithFunc(noop), WithRevertFunc(revert("second")))
+		if err := first.AddSubtasks(second); err != nil {
+			return nil, err
+		}
+		return []*Task{first}, nil
+	})
+
+	ctx := context.Background()
+	call := Call(ctx, "test-revert")
+	fail := New(ctx, WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+		return nil, errors.New("boom")
+	}))
+	if err := call.AddSubtasks(fail); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+
+	if _, err := Run([]*Task{call}); err == nil {
+		t.Fatal("expected an error")
+	}
+	if expected := []string{"second", "first"}; !reflect.DeepEqual(reverted, expected) {
+		t.Errorf("expected reverts %v, got %v", expected, reverted)
+	}
+	if call.Status() != Reverted {
+		t.Errorf("expected status %s, got %s", Reverted, call.Status())
+	}
+}
+
+func TestCallWorkflowFailure(t *testing.T) {
+	reverted := false
+	registerWorkflow(t, "test-failure", func(ctx context.Context, params ...interface{}) ([]*Task, error) {
+		first := New(ctx, WithFunc(noop), WithRevertFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+			reverted = true
+			return nil, nil
+		}))
+		second := New(ctx, WithFunc(func(ctx context.Context, values ...interface{}) (interface{}, error) {
+			return nil, errors.New("boom")
+		}))
+		if err := first.AddSubtasks(second); err != nil {
+			return nil, err
+		}
+		return []*Task{first}, nil
+	})
+
+	if _, err := Run([]*Task{Call(context.Background(), "test-failure")}); err == nil {
+		t.Fatal("expected an error")
+	}
+	if !reverted {
+		t.Error("expected the workflow to revert its succeeded tasks")
+	}
+}
+
+func TestCallSharedSlots(t *testing.T) {
+	registerWorkflow(t, "test-shared", func(ctx context.Context, params ...interface{}) ([]*Task, error) {
+		inner := New(ctx, WithName("inner"), WithFunc(noop), WithSerialKey("account"), WithResources(map[string]int{"db": 1}))
+		return []*Task{inner}, nil
+	})
+
+	runner := NewRunner(WithSerialQueue(NewSerialQueue()), WithResourcePool(NewResourcePool(map[string]int{"db": 1})))
+	call := Call(context.Background(), "test-shared", WithSerialKey("account"), WithResources(map[string]int{"db": 1}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := runner.RunCtx(ctx, []*Task{call}); err != nil {
+		t.Fatalf("didnt expect error, got %v", err)
+	}
+}
+
+func TestRegisterWorkflowTwice(t *testing.T) {
+	build := func(ctx context.Context, params ...interface{}) ([]*Task, error) {
+		return nil, nil
+	}
+	registerWorkflow(t, "test-twice", build)
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic")
+		}
+	}()
+	RegisterWorkflow("test-twice", build)
+}
+
+// registerWorkflow registers build under name for the duration of the test.
+func registerWorkflow(t *testing.T, name string, build WorkflowFunc) {
+	t.Helper()
+	RegisterWorkflow(name, build)
+	t.Cleanup(func() {
+		unregisterWorkflow(name)
+	})
+}